@@ -75,6 +75,9 @@ func init() {
 			err := generate.Generate(genOpts, opts, args)
 			if err != nil {
 				cli.Failuref("Generation failed: %v\n", err)
+				if genOpts.Check {
+					os.Exit(1)
+				}
 				return
 			}
 			success = true
@@ -83,7 +86,9 @@ func init() {
 	generateCmd.Flags().StringVarP(&genOpts.ConfigPath, "config", "c", "", "path to the configuration file or - for stdin")
 	generateCmd.Flags().StringVarP(&genOpts.OutPath, "out", "o", "", "the output directory or file or - for stdout")
 	generateCmd.Flags().BoolVarP(&genOpts.Yes, "yes", "y", false, "answer to all prompts with the default answers")
+	generateCmd.Flags().BoolVarP(&genOpts.Check, "check", "", false, "check whether the existing generated files are up to date instead of writing them, keep blocks are ignored")
 	generateCmd.Flags().StringVarP(&genOpts.Targets, "targets", "t", "", "targets to generate in the following format: \"go-general:types,spec;go-echo:server\", this overrides the values in the config")
+	generateCmd.Flags().StringVarP(&genOpts.PackageName, "package", "p", "", "name of the package for the generated code, this overrides the value in the config")
 
 	rootCmd.AddCommand(generateCmd)
 }
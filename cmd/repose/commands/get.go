@@ -2,14 +2,19 @@ package commands
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/tamasfe/repose/cmd/repose/config"
+	"github.com/tamasfe/repose/internal/markdown"
+	"github.com/tamasfe/repose/pkg/generator"
 	"github.com/tamasfe/repose/pkg/util"
 	"github.com/tamasfe/repose/pkg/util/cli"
 	"gopkg.in/yaml.v3"
@@ -52,7 +57,7 @@ func init() {
 			} else {
 				conf := config.DefaultReposeOptions()
 				conf.Generators = make(map[string]*config.Generator)
-				for _, t := range config.Generators {
+				for _, t := range config.AllGenerators() {
 					kinds := make([]string, 0, len(t.Targets()))
 					for k := range t.Targets() {
 						kinds = append(kinds, k)
@@ -86,55 +91,54 @@ func init() {
 			}
 
 			if getOpts.OutPath != "" && getOpts.OutPath != "-" {
-				if !getOpts.Force {
-					_, err := os.Stat(getOpts.OutPath)
-					if err == nil {
-						cli.Failureln("file already exists, use \"-f\" to force overwrite.")
-						return
-					}
-				}
-
-				err := os.MkdirAll(filepath.Dir(getOpts.OutPath), os.ModePerm)
+				err := writeGetOutput(getOpts, []byte(configComment+cfg))
 				if err != nil {
 					cli.Failureln(err)
-					return
-				}
-				info, err := os.Stat(getOpts.OutPath)
-				if err != nil {
-					if !os.IsNotExist(err) {
-						cli.Failureln(err)
-						return
-					}
 				}
+				return
+			}
 
-				if info != nil && info.IsDir() {
-					cli.Failureln("Output path should be a file, not a directory")
-					return
-				}
+			fmt.Println(configComment + cfg)
+		},
+	}
 
-				f, err := os.Create(getOpts.OutPath)
-				if err != nil {
-					cli.Failureln(err)
-					return
-				}
-				defer f.Close()
+	getConfigCmd.Flags().BoolVarP(&getOpts.NoComments, "no-comments", "", false, "Disables all comments")
+	getConfigCmd.Flags().StringVarP(&getOpts.OutPath, "out", "o", "", "the output directory or file")
+	getConfigCmd.Flags().BoolVarP(&getOpts.All, "all", "a", false, "include all possible values")
+	getConfigCmd.Flags().BoolVarP(&getOpts.Force, "force", "f", true, "force overwriting files")
+
+	schemaOpts := &config.GetOptions{}
 
-				_, err = f.Write([]byte(configComment + cfg))
+	getConfigSchemaCmd := &cobra.Command{
+		Use:          "config-schema",
+		Short:        "Provides a JSON Schema of the configuration",
+		Aliases:      []string{"schema"},
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if schemaOpts.OutPath == "" || schemaOpts.OutPath == "-" {
+				cli.Silent = true
+			}
+
+			b, err := json.MarshalIndent(configSchema(), "", "  ")
+			if err != nil {
+				cli.Failureln(err)
+				return
+			}
+
+			if schemaOpts.OutPath != "" && schemaOpts.OutPath != "-" {
+				err := writeGetOutput(schemaOpts, append(b, '\n'))
 				if err != nil {
 					cli.Failureln(err)
-					return
 				}
 				return
 			}
 
-			fmt.Println(configComment + cfg)
+			fmt.Println(string(b))
 		},
 	}
 
-	getConfigCmd.Flags().BoolVarP(&getOpts.NoComments, "no-comments", "", false, "Disables all comments")
-	getConfigCmd.Flags().StringVarP(&getOpts.OutPath, "out", "o", "", "the output directory or file")
-	getConfigCmd.Flags().BoolVarP(&getOpts.All, "all", "a", false, "include all possible values")
-	getConfigCmd.Flags().BoolVarP(&getOpts.Force, "force", "f", true, "force overwriting files")
+	getConfigSchemaCmd.Flags().StringVarP(&schemaOpts.OutPath, "out", "o", "", "the output file")
+	getConfigSchemaCmd.Flags().BoolVarP(&schemaOpts.Force, "force", "f", true, "force overwriting files")
 
 	getParsersCmd := &cobra.Command{
 		Use:          "parsers",
@@ -185,10 +189,123 @@ func init() {
 	getCmd.AddCommand(getTransformersCmd)
 	getCmd.AddCommand(getParsersCmd)
 	getCmd.AddCommand(getConfigCmd)
+	getCmd.AddCommand(getConfigSchemaCmd)
 
 	rootCmd.AddCommand(getCmd)
 }
 
+// writeGetOutput writes the output of a get command to getOpts.OutPath.
+func writeGetOutput(getOpts *config.GetOptions, data []byte) error {
+	if !getOpts.Force {
+		_, err := os.Stat(getOpts.OutPath)
+		if err == nil {
+			return errors.New("file already exists, use \"-f\" to force overwrite.")
+		}
+	}
+
+	err := os.MkdirAll(filepath.Dir(getOpts.OutPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(getOpts.OutPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if info != nil && info.IsDir() {
+		return errors.New("Output path should be a file, not a directory")
+	}
+
+	f, err := os.Create(getOpts.OutPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	return err
+}
+
+// configSchema returns a JSON Schema of the configuration
+// including the options of all the parsers, transformers and generators.
+func configSchema() map[string]interface{} {
+	schema := markdown.OptionsSchema(config.DefaultReposeOptions())
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "Repose configuration"
+
+	props := schema["properties"].(map[string]interface{})
+
+	parsers := make(map[string]interface{}, len(config.Parsers))
+	for _, p := range config.Parsers {
+		parsers[p.Name()] = markdown.OptionsSchema(p.DefaultOptions())
+	}
+
+	parsersSchema := props["parsers"].(map[string]interface{})
+	parsersSchema["properties"] = parsers
+	parsersSchema["additionalProperties"] = false
+
+	transformers := make([]interface{}, 0, len(config.Transformers))
+	for _, t := range config.Transformers {
+		tSchema := markdown.OptionsSchema(config.Transformer{})
+		tProps := tSchema["properties"].(map[string]interface{})
+
+		tProps["name"].(map[string]interface{})["const"] = t.Name()
+		tProps["options"] = withDescription(markdown.OptionsSchema(t.DefaultOptions()), tProps["options"])
+		tSchema["required"] = []string{"name"}
+
+		transformers = append(transformers, tSchema)
+	}
+
+	props["transformers"].(map[string]interface{})["items"] = map[string]interface{}{
+		"oneOf": transformers,
+	}
+
+	generators := make(map[string]interface{})
+	for _, g := range config.AllGenerators() {
+		gSchema := markdown.OptionsSchema(config.Generator{})
+		gProps := gSchema["properties"].(map[string]interface{})
+
+		targets := make([]string, 0, len(g.Targets()))
+		for t := range g.Targets() {
+			targets = append(targets, t)
+		}
+
+		// The aliases are accepted in the configuration as well.
+		if aliases, ok := g.(generator.TargetAliases); ok {
+			for _, a := range aliases.TargetAliases() {
+				targets = append(targets, a...)
+			}
+		}
+		sort.Strings(targets)
+
+		gProps["targets"].(map[string]interface{})["items"] = map[string]interface{}{
+			"type": "string",
+			"enum": targets,
+		}
+		gProps["options"] = withDescription(markdown.OptionsSchema(g.DefaultOptions()), gProps["options"])
+
+		generators[g.Name()] = gSchema
+	}
+
+	generatorsSchema := props["generators"].(map[string]interface{})
+	generatorsSchema["properties"] = generators
+	generatorsSchema["additionalProperties"] = false
+
+	return schema
+}
+
+// withDescription copies the description of the
+// original schema of a property to its new schema.
+func withDescription(schema map[string]interface{}, original interface{}) map[string]interface{} {
+	if o, ok := original.(map[string]interface{}); ok && o["description"] != nil {
+		schema["description"] = o["description"]
+	}
+
+	return schema
+}
+
 func printParsers() {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
 
@@ -213,7 +330,7 @@ func printGenerators() {
 	cli.Infof("Available generators:\n")
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
 
-	for _, p := range config.Generators {
+	for _, p := range config.AllGenerators() {
 		targets := make([]string, 0, len(p.Targets()))
 
 		for t := range p.Targets() {
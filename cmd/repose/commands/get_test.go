@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestConfigSchemaTargets(t *testing.T) {
+	generators := configSchema()["properties"].(map[string]interface{})["generators"].(map[string]interface{})["properties"].(map[string]interface{})
+	echo := generators["go-echo"].(map[string]interface{})["properties"].(map[string]interface{})
+	targets := echo["targets"].(map[string]interface{})["items"].(map[string]interface{})["enum"].([]string)
+
+	enum := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		enum[target] = true
+	}
+
+	// The aliases accepted by the generator are valid as well.
+	for _, target := range []string{"server", "srv", "server-fake", "fake", "server-route-test", "route-test"} {
+		assert.Equal(t, enum[target], true)
+	}
+}
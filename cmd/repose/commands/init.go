@@ -0,0 +1,200 @@
+package commands
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/spf13/cobra"
+	"github.com/tamasfe/repose/cmd/repose/config"
+	"github.com/tamasfe/repose/pkg/util/cli"
+)
+
+// Frameworks that can be chosen for a new project.
+const (
+	frameworkEcho   = "echo"
+	frameworkStdlib = "stdlib"
+)
+
+const initSpec = `openapi: 3.0.0
+info:
+  title: %v
+  version: 0.1.0
+paths:
+  /ping:
+    get:
+      operationId: ping
+      responses:
+        "200":
+          description: The service is up.
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Pong"
+components:
+  schemas:
+    Pong:
+      type: object
+      properties:
+        message:
+          type: string
+`
+
+const initMakefile = `.PHONY: generate
+
+generate:
+	repose generate -c repose.yaml -o %v openapi.yaml
+`
+
+func init() {
+	initOpts := &config.InitOptions{}
+
+	initCmd := &cobra.Command{
+		Use:          "init [directory]",
+		Short:        "Create a new project with a configuration and a specification",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			err := initProject(dir, initOpts)
+			if err != nil {
+				cli.Failuref("Initialization failed: %v\n", err)
+				return
+			}
+
+			cli.Successln("Project created, run \"make generate\" to generate the code.")
+		},
+	}
+
+	initCmd.Flags().BoolVarP(&initOpts.Yes, "yes", "y", false, "answer to all prompts with the default answers")
+	initCmd.Flags().BoolVarP(&initOpts.Force, "force", "f", false, "force overwriting files")
+	initCmd.Flags().StringVarP(&initOpts.Framework, "framework", "", "", "framework of the generated code, either echo or stdlib")
+	initCmd.Flags().StringVarP(&initOpts.OutPath, "out", "o", "", "the output directory of the generated code, relative to the project")
+
+	rootCmd.AddCommand(initCmd)
+}
+
+// initProject writes the configuration, the specification
+// and the Makefile of a new project to dir.
+func initProject(dir string, opts *config.InitOptions) error {
+	if opts.Framework == "" {
+		opts.Framework = frameworkEcho
+
+		if !opts.Yes {
+			prompt := &survey.Select{
+				Message: "Which framework should the generated code use?",
+				Options: []string{frameworkEcho, frameworkStdlib},
+				Default: frameworkEcho,
+			}
+			err := survey.AskOne(prompt, &opts.Framework)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.OutPath == "" {
+		opts.OutPath = "api"
+
+		if !opts.Yes {
+			prompt := &survey.Input{
+				Message: "Where should the generated code be placed?",
+				Default: "api",
+			}
+			err := survey.AskOne(prompt, &opts.OutPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	conf, err := initConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := marshalYAML(conf)
+	if err != nil {
+		return err
+	}
+
+	title := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		title = filepath.Base(abs)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"repose.yaml", "# Generated config file for Repose, a Go RESTful API code generation tool.\n\n" + string(cfg)},
+		{"openapi.yaml", fmt.Sprintf(initSpec, title)},
+		{"Makefile", fmt.Sprintf(initMakefile, filepath.ToSlash(opts.OutPath))},
+	}
+
+	if !opts.Force {
+		for _, f := range files {
+			_, err := os.Stat(filepath.Join(dir, f.name))
+			if err == nil {
+				return fmt.Errorf("file %v already exists, use \"-f\" to force overwrite", f.name)
+			}
+		}
+	}
+
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	for _, f := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, f.name), []byte(f.content), 0644)
+		if err != nil {
+			return fmt.Errorf("failed to write %v: %w", f.name, err)
+		}
+
+		cli.Verboseln("Created \"" + filepath.Join(dir, f.name) + "\".")
+	}
+
+	return nil
+}
+
+// initConfig returns the configuration of a new project.
+func initConfig(opts *config.InitOptions) (*config.ReposeOptions, error) {
+	conf := config.DefaultReposeOptions()
+
+	// The package is named after the output directory.
+	conf.PackageName = strings.ToLower(filepath.Base(filepath.Clean(opts.OutPath)))
+	if !token.IsIdentifier(conf.PackageName) {
+		return nil, fmt.Errorf(
+			"the name of the output directory %v is not a valid Go package name, choose another directory",
+			opts.OutPath,
+		)
+	}
+
+	conf.Generators["go-general"] = &config.Generator{
+		Targets: []string{"types", "spec"},
+	}
+
+	switch opts.Framework {
+	case frameworkEcho:
+		conf.Generators["go-echo"] = &config.Generator{
+			Targets: []string{"server"},
+		}
+	case frameworkStdlib:
+		conf.Generators["go-stdlib"] = &config.Generator{
+			Targets: []string{"client", "full-client"},
+		}
+	default:
+		return nil, fmt.Errorf("unknown framework %v, expected %v or %v", opts.Framework, frameworkEcho, frameworkStdlib)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tamasfe/repose/cmd/repose/config"
+	"github.com/tamasfe/repose/cmd/repose/generate"
+	"gopkg.in/go-playground/assert.v1"
+	"gopkg.in/yaml.v3"
+)
+
+func TestInitProject(t *testing.T) {
+	for _, framework := range []string{frameworkEcho, frameworkStdlib} {
+		dir, err := ioutil.TempDir("", "repose-init")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		err = initProject(dir, &config.InitOptions{Yes: true, Framework: framework, OutPath: "gen/petstore"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		makefile, err := ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, strings.Contains(string(makefile), "-o gen/petstore openapi.yaml"), true)
+
+		cfg, err := ioutil.ReadFile(filepath.Join(dir, "repose.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var options *config.ReposeOptions
+		err = yaml.Unmarshal(cfg, &options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, options.PackageName, "petstore")
+
+		specData, err := ioutil.ReadFile(filepath.Join(dir, "openapi.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The created project can be generated right away.
+		files, err := generate.GenerateFiles(options, specData)
+		if err != nil {
+			t.Fatalf("%v: %v", framework, err)
+		}
+
+		var src string
+		for _, f := range files {
+			src += string(f)
+		}
+
+		assert.Equal(t, strings.Contains(src, "package petstore"), true)
+		assert.Equal(t, strings.Contains(src, "type Pong struct"), true)
+		assert.Equal(t, strings.Contains(src, "Ping("), true)
+
+		// Existing files are only overwritten with force.
+		err = initProject(dir, &config.InitOptions{Yes: true, Framework: framework, OutPath: "api"})
+		assert.NotEqual(t, err, nil)
+
+		err = initProject(dir, &config.InitOptions{Yes: true, Force: true, Framework: framework, OutPath: "api"})
+		assert.Equal(t, err, nil)
+	}
+}
+
+func TestInitConfigErrors(t *testing.T) {
+	_, err := initConfig(&config.InitOptions{Framework: "gin", OutPath: "api"})
+	assert.NotEqual(t, err, nil)
+
+	// The package name is derived from the output directory.
+	for _, outPath := range []string{"my-api", "type", "2api", "."} {
+		_, err := initConfig(&config.InitOptions{Framework: frameworkEcho, OutPath: outPath})
+		assert.NotEqual(t, err, nil)
+	}
+
+	conf, err := initConfig(&config.InitOptions{Framework: frameworkEcho, OutPath: "internal/API"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, conf.PackageName, "api")
+}
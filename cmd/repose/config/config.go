@@ -1,7 +1,14 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/tamasfe/repose/pkg/generator"
+	"github.com/tamasfe/repose/pkg/generator/examples"
 	"github.com/tamasfe/repose/pkg/generator/golang"
 	"github.com/tamasfe/repose/pkg/parser"
 	"github.com/tamasfe/repose/pkg/transformer"
@@ -9,10 +16,38 @@ import (
 )
 
 // Generators supported by the CLI.
+//
+// A custom main can append to it, generators registered
+// with generator.Register are also available, see AllGenerators.
 var Generators = []generator.Generator{
 	&golang.General{},
 	&golang.StdLib{},
 	&golang.Echo{},
+	&examples.Examples{},
+}
+
+// AllGenerators returns Generators along with the generators
+// registered with generator.Register.
+//
+// Registered generators do not override the ones in Generators with the same name.
+func AllGenerators() []generator.Generator {
+	all := append([]generator.Generator(nil), Generators...)
+
+	for _, r := range generator.Registered() {
+		var found bool
+		for _, g := range all {
+			if g.Name() == r.Name() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			all = append(all, r)
+		}
+	}
+
+	return all
 }
 
 // Parsers supported by the CLI.
@@ -27,8 +62,9 @@ var Transformers = []transformer.Transformer{
 
 // Generator groups kinds and options for generator.Generator
 type Generator struct {
-	Targets []string    `yaml:"targets,omitempty" description:"Targets to generate"`
-	Options interface{} `yaml:"options,omitempty" description:"Options for the generator"`
+	Targets   []string    `yaml:"targets,omitempty" description:"Targets to generate"`
+	Options   interface{} `yaml:"options,omitempty" description:"Options for the generator"`
+	DoNotEdit bool        `yaml:"doNotEdit,omitempty" description:"Add the standard \"Code generated ... DO NOT EDIT.\" header to the generated files so that Go tools treat them as generated, files with code from other generators only get it if all of them enable it, do not enable it for files with keep blocks"`
 }
 
 // Transformer groups the transformer name and its options
@@ -49,6 +85,10 @@ type GenerateOptions struct {
 	ConfigPath string
 	OutPath    string
 	Targets    string
+	Check      bool
+
+	// PackageName overrides the package name in the Repose options.
+	PackageName string
 }
 
 // GetOptions contains options for the CLI.
@@ -59,13 +99,25 @@ type GetOptions struct {
 	OutPath    string
 }
 
+// InitOptions contains options for the CLI.
+type InitOptions struct {
+	Yes       bool
+	Force     bool
+	Framework string
+	OutPath   string
+}
+
 // ReposeOptions options for Repose.
 type ReposeOptions struct {
 	PackageName         string                 `yaml:"packageName" description:"Name of the package for the generated code"`
 	FilePattern         string                 `yaml:"filePattern" description:"Pattern for generated file names if a directory is specified"`
 	Timestamp           bool                   `yaml:"timestamp" description:"Add timestamp for the generated code"`
+	TimestampSource     string                 `yaml:"timestampSource,omitempty" description:"Fixed time of the timestamp for reproducible output, either an RFC 3339 time, unix seconds, or SOURCE_DATE_EPOCH to read it from the environment variable, the current time is used if it is empty"`
 	Comments            bool                   `yaml:"comments" description:"Enable comments in the generated code"`
 	DescriptionComments bool                   `yaml:"descriptionComments" description:"Enable descriptions from the specifications as comments in the generated code"`
+	LineEnding          string                 `yaml:"lineEnding" description:"Line endings of non-Go generator outputs, either lf or crlf"`
+	EndMarker           bool                   `yaml:"endMarker" description:"Add a repose:end comment after the generated Go code, code after it is preserved when regenerating existing files, it is not added to files with outputs of generators that are not Go code"`
+	Initialisms         []string               `yaml:"initialisms,omitempty" description:"Additional initialisms to keep upper-cased in generated names (e.g. SKU for Sku), common initialisms such as ID, URL and HTTP are always upper-cased"`
 	Parsers             map[string]interface{} `yaml:"parsers,omitempty" description:"Parsers to use and their options, leave it empty to infer from the input"`
 	Transformers        []*Transformer         `yaml:"transformers,omitempty" description:"Transformers to alter the specification with before generating code, and their options"`
 	Generators          map[string]*Generator  `yaml:"generators,omitempty" description:"Generators for code generation"`
@@ -83,6 +135,7 @@ func DefaultReposeOptions() *ReposeOptions {
 		DescriptionComments: true,
 		Timestamp:           false,
 		Comments:            true,
+		LineEnding:          LineEndingLF,
 		FilePattern:         "{{ .Generator }}-{{ .Target }}.gen.go",
 		Parsers:             map[string]interface{}{},
 		Transformers:        []*Transformer{},
@@ -90,8 +143,55 @@ func DefaultReposeOptions() *ReposeOptions {
 	}
 }
 
+// Supported line endings.
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
+)
+
+// TimestampSourceEpoch reads the timestamp from
+// the SOURCE_DATE_EPOCH environment variable.
+const TimestampSourceEpoch = "SOURCE_DATE_EPOCH"
+
+// GenerationTime returns the time for the timestamp of the generated code
+// based on TimestampSource.
+func (g *ReposeOptions) GenerationTime() (time.Time, error) {
+	source := strings.TrimSpace(g.TimestampSource)
+
+	if source == TimestampSourceEpoch {
+		source = strings.TrimSpace(os.Getenv(TimestampSourceEpoch))
+	}
+
+	if source == "" {
+		return time.Now(), nil
+	}
+
+	if sec, err := strconv.ParseInt(source, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, source)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp source %v, expected an RFC 3339 time or unix seconds", source)
+	}
+
+	return t, nil
+}
+
 // ValidateReposeOptions validates options
 func ValidateReposeOptions(opts *ReposeOptions) error {
-	// TODO
+	switch strings.ToLower(opts.LineEnding) {
+	case "", LineEndingLF, LineEndingCRLF:
+	default:
+		return fmt.Errorf("unknown line ending %v, expected %v or %v", opts.LineEnding, LineEndingLF, LineEndingCRLF)
+	}
+
+	if opts.Timestamp {
+		_, err := opts.GenerationTime()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -5,11 +5,13 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"go/token"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -24,6 +26,7 @@ import (
 	"github.com/tamasfe/repose/pkg/parser"
 	"github.com/tamasfe/repose/pkg/spec"
 	"github.com/tamasfe/repose/pkg/transformer"
+	"github.com/tamasfe/repose/pkg/util"
 	"github.com/tamasfe/repose/pkg/util/cli"
 )
 
@@ -34,9 +37,175 @@ type filenameValues struct {
 
 // Generate generate code according to options
 func Generate(cliOpts *config.GenerateOptions, options *config.ReposeOptions, inPaths []string) error {
+	if cliOpts.PackageName != "" {
+		options.PackageName = cliOpts.PackageName
+	}
+
+	if options.PackageName != "" && (!token.IsIdentifier(options.PackageName) || options.PackageName == "_") {
+		return fmt.Errorf("%q is not a valid Go package name", options.PackageName)
+	}
+
+	ctx, err := newContext(options)
+	if err != nil {
+		return err
+	}
+
+	spec, err := parseSpec(ctx, cliOpts, options, inPaths)
+	if err != nil {
+		return err
+	}
+
+	err = transformSpec(ctx, options, spec)
+	if err != nil {
+		return err
+	}
+
+	return generateCode(ctx, cliOpts, options, spec)
+}
+
+// GenerateFiles parses the specification data, and generates code
+// according to the options without any file system access or prompts.
+//
+// The generated files are returned by their names based on the file pattern,
+// if the pattern contains neither the generator nor the target,
+// all the code is generated into a single file.
+//
+// The options are copied, the caller's options are left unchanged.
+func GenerateFiles(options *config.ReposeOptions, data []byte) (map[string][]byte, error) {
+	options = copyOptions(options)
+
+	ctx, err := newContext(options)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, _, err := parseData(ctx, options, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = transformSpec(ctx, options, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	generators, err := getGenerators(&config.GenerateOptions{}, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.PackageName == "" {
+		options.PackageName = "api"
+	}
+
+	return generateFiles(ctx, options, spec, generators)
+}
+
+// SpecOptions are the options for GenerateSpec.
+type SpecOptions struct {
+	// PackageName is the name of the package
+	// of the generated code, "api" by default.
+	PackageName string
+
+	// Targets are the targets to generate by generator name,
+	// all the targets are generated for generators that are not in it
+	// except for the spec target, as there is no specification file,
+	// and the targets that need it (e.g. the validator of echo).
+	Targets map[string][]string
+
+	// GeneratorOptions are the options by generator name, the
+	// default options are used for generators that are not in it.
+	GeneratorOptions map[string]interface{}
+}
+
+// specFileTargets are the targets that embed or use
+// the bytes of the parsed specification file.
+var specFileTargets = map[string]bool{
+	"spec":      true,
+	"validator": true,
+}
+
+// GenerateSpec generates code for a specification that was built
+// programmatically (e.g. with the builders of the spec package)
+// without any parsing, transformations, file system access or prompts.
+//
+// The code of all the generators is rendered into a single Go file,
+// the files generated separately (e.g. by the examples generator) are
+// returned by their names.
+func GenerateSpec(
+	sp *spec.Spec,
+	generators []generator.Generator,
+	commonOpts *common.Options,
+	opts *SpecOptions,
+) ([]byte, generator.Files, error) {
+	if commonOpts == nil {
+		commonOpts = common.DefaultOptions()
+	}
+
+	if opts == nil {
+		opts = &SpecOptions{}
+	}
 
+	options := config.DefaultReposeOptions()
+	options.PackageName = opts.PackageName
+	options.Comments = commonOpts.Comments
+	options.DescriptionComments = commonOpts.DescriptionComments
+
+	if options.PackageName == "" {
+		options.PackageName = "api"
+	}
+
+	targets := make(map[string][]string, len(generators))
+
+	for _, g := range generators {
+		genTargets := opts.Targets[g.Name()]
+
+		if len(genTargets) == 0 {
+			for t := range g.Targets() {
+				// There is no parsed specification file to embed.
+				if specFileTargets[t] {
+					continue
+				}
+				genTargets = append(genTargets, t)
+			}
+			sort.Strings(genTargets)
+		}
+
+		targets[g.Name()] = genTargets
+		options.Generators[g.Name()] = &config.Generator{
+			Targets: genTargets,
+			Options: opts.GeneratorOptions[g.Name()],
+		}
+	}
+
+	ctx, err := newContext(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx = context.WithValue(ctx, common.ContextCommonOptions, commonOpts)
+
+	buf := &bytes.Buffer{}
+	files := make(generator.Files)
+
+	err = generateUnit(ctx, options, sp, generators, targets, buf, files)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), files, nil
+}
+
+// newContext validates the options, and creates
+// the context that is passed to all the components.
+func newContext(options *config.ReposeOptions) (context.Context, error) {
 	normalizeNames(options)
 
+	err := config.ValidateReposeOptions(options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
 	// Provide all the generator options in the
 	// context as well.
 	ctxGeneratorOptions := make(map[string]interface{})
@@ -50,11 +219,15 @@ func Generate(cliOpts *config.GenerateOptions, options *config.ReposeOptions, in
 	state := &common.State{}
 	ctx = context.WithValue(ctx, common.ContextState, state)
 
-	spec, err := parseSpec(ctx, cliOpts, options, inPaths)
-	if err != nil {
-		return err
-	}
+	// Names are generated by every component,
+	// not only by the generators.
+	ctx = context.WithValue(ctx, common.ContextInitialisms, util.NewInitialisms(options.Initialisms))
 
+	return ctx, nil
+}
+
+// transformSpec runs all the configured transformers on the specification.
+func transformSpec(ctx context.Context, options *config.ReposeOptions, spec *spec.Spec) error {
 	transformers, err := getTransformers(options)
 	if err != nil {
 		return err
@@ -67,7 +240,7 @@ func Generate(cliOpts *config.GenerateOptions, options *config.ReposeOptions, in
 		}
 	}
 
-	return generateCode(ctx, cliOpts, options, spec)
+	return nil
 }
 
 func generateCode(
@@ -87,6 +260,32 @@ func generateCode(
 		return err
 	}
 
+	if cliOpts.Check && outputToStdout {
+		return fmt.Errorf("an output path is required for checking generated code")
+	}
+
+	// Files that are out of date in check mode.
+	var staleFiles []string
+
+	// output either writes the generated code into a file,
+	// or compares it with the existing file in check mode.
+	output := func(codeBuf *bytes.Buffer, path string) error {
+		if !cliOpts.Check {
+			return writeFile(cliOpts, bytes.NewReader(codeBuf.Bytes()), path)
+		}
+
+		upToDate, err := checkFile(codeBuf.Bytes(), path)
+		if err != nil {
+			return err
+		}
+
+		if !upToDate {
+			staleFiles = append(staleFiles, path)
+		}
+
+		return nil
+	}
+
 	hasGenerator := regexp.MustCompile(`\{\{\s?\.Generator\s?\}\}`)
 	hasTarget := regexp.MustCompile(`\{\{\s?\.Target\s?\}\}`)
 
@@ -150,6 +349,7 @@ func generateCode(
 		}
 
 		codeBuf := &bytes.Buffer{}
+		files := make(generator.Files)
 
 		err = generateUnit(
 			ctx,
@@ -158,6 +358,7 @@ func generateCode(
 			generators,
 			allTargets,
 			codeBuf,
+			files,
 		)
 
 		if err != nil {
@@ -165,12 +366,29 @@ func generateCode(
 		}
 
 		if !outputToStdout {
-			err := writeFile(cliOpts, bytes.NewReader(codeBuf.Bytes()), cliOpts.OutPath)
+			err := output(codeBuf, cliOpts.OutPath)
 			if err != nil {
 				return err
 			}
 
-			return nil
+			fileNames := make([]string, 0, len(files))
+			for fName := range files {
+				fileNames = append(fileNames, fName)
+			}
+			sort.Strings(fileNames)
+
+			for _, fName := range fileNames {
+				err := output(bytes.NewBuffer(files[fName]), filepath.Join(filepath.Dir(cliOpts.OutPath), fName))
+				if err != nil {
+					return err
+				}
+			}
+
+			return staleError(staleFiles)
+		}
+
+		if len(files) > 0 {
+			cli.Warningf("%v generated files are not written to stdout.\n", len(files))
 		}
 
 		_, err = io.Copy(os.Stdout, codeBuf)
@@ -182,7 +400,7 @@ func generateCode(
 	}
 
 	_, err = os.Stat(cliOpts.OutPath)
-	if err != nil {
+	if err != nil && !cliOpts.Check {
 		if os.IsNotExist(err) {
 			if !cliOpts.Yes {
 				create := false
@@ -202,18 +420,89 @@ func generateCode(
 		}
 	}
 
-	err = os.MkdirAll(cliOpts.OutPath, os.ModePerm)
+	if !cliOpts.Check {
+		err = os.MkdirAll(cliOpts.OutPath, os.ModePerm)
+		if err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+
+	files, err := generateFiles(ctx, options, spec, generators)
 	if err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return err
+	}
+
+	fileNames := make([]string, 0, len(files))
+	for fName := range files {
+		fileNames = append(fileNames, fName)
+	}
+	sort.Strings(fileNames)
+
+	for _, fName := range fileNames {
+		err := output(bytes.NewBuffer(files[fName]), filepath.Join(cliOpts.OutPath, fName))
+		if err != nil {
+			return err
+		}
 	}
 
+	return staleError(staleFiles)
+}
+
+// generateFiles generates code into files with names based on
+// the file pattern, and returns their contents by their names.
+func generateFiles(
+	ctx context.Context,
+	options *config.ReposeOptions,
+	spec *spec.Spec,
+	generators []generator.Generator,
+) (map[string][]byte, error) {
 	if options.FilePattern == "" {
 		options.FilePattern = "{{.Generator}}.gen.go"
 	}
 
+	hasGenerator := regexp.MustCompile(`\{\{\s?\.Generator\s?\}\}`)
+	hasTarget := regexp.MustCompile(`\{\{\s?\.Target\s?\}\}`)
+
+	fileNameTemplate, err := template.New("filename").Funcs(sprig.TxtFuncMap()).Parse(options.FilePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file pattern: %w", err)
+	}
+
+	files := make(map[string][]byte)
+
+	// Everything is generated into a single file.
+	if len(hasGenerator.FindStringIndex(options.FilePattern)) == 0 &&
+		len(hasTarget.FindStringIndex(options.FilePattern)) == 0 {
+		fnBuf := &bytes.Buffer{}
+
+		err = fileNameTemplate.Execute(fnBuf, &filenameValues{})
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern: %w", err)
+		}
+
+		allTargets := make(map[string][]string, len(generators))
+
+		for gName, g := range options.Generators {
+			allTargets[gName] = g.Targets
+		}
+
+		codeBuf := &bytes.Buffer{}
+
+		err = generateUnit(ctx, options, spec, generators, allTargets, codeBuf, files)
+		if err != nil {
+			return nil, fmt.Errorf("Generation failed: %w", err)
+		}
+
+		if codeBuf.Len() > 0 {
+			files[fnBuf.String()] = codeBuf.Bytes()
+		}
+
+		return files, nil
+	}
+
 	separateTargets := len(hasTarget.FindStringIndex(options.FilePattern)) != 0
 	if separateTargets && len(hasGenerator.FindStringIndex(options.FilePattern)) == 0 {
-		return fmt.Errorf("generator must also be specified if target is specified in the file pattern")
+		return nil, fmt.Errorf("generator must also be specified if target is specified in the file pattern")
 	}
 
 	for _, g := range generators {
@@ -226,11 +515,9 @@ func generateCode(
 					Target:    t,
 				})
 				if err != nil {
-					return fmt.Errorf("invalid file pattern: %w", err)
+					return nil, fmt.Errorf("invalid file pattern: %w", err)
 				}
 
-				fName := filepath.Join(cliOpts.OutPath, fnBuf.String())
-
 				codeBuf := &bytes.Buffer{}
 
 				err = generateUnit(
@@ -242,14 +529,14 @@ func generateCode(
 						g.Name(): []string{t},
 					},
 					codeBuf,
+					files,
 				)
 				if err != nil {
-					return err
+					return nil, err
 				}
 
-				err := writeFile(cliOpts, bytes.NewReader(codeBuf.Bytes()), fName)
-				if err != nil {
-					return err
+				if codeBuf.Len() > 0 {
+					files[fnBuf.String()] = codeBuf.Bytes()
 				}
 			}
 
@@ -262,11 +549,9 @@ func generateCode(
 			Generator: g.Name(),
 		})
 		if err != nil {
-			return fmt.Errorf("invalid file pattern: %w", err)
+			return nil, fmt.Errorf("invalid file pattern: %w", err)
 		}
 
-		fName := filepath.Join(cliOpts.OutPath, fnBuf.String())
-
 		codeBuf := &bytes.Buffer{}
 
 		err = generateUnit(
@@ -278,18 +563,49 @@ func generateCode(
 				g.Name(): options.Generators[g.Name()].Targets,
 			},
 			codeBuf,
+			files,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err := writeFile(cliOpts, bytes.NewReader(codeBuf.Bytes()), fName)
-		if err != nil {
-			return err
+		if codeBuf.Len() > 0 {
+			files[fnBuf.String()] = codeBuf.Bytes()
 		}
 	}
 
-	return nil
+	return files, nil
+}
+
+// staleError returns an error listing the
+// out of date files if there are any.
+func staleError(staleFiles []string) error {
+	if len(staleFiles) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("generated code is out of date:\n%v", strings.Join(staleFiles, "\n"))
+}
+
+// doNotEditUnit checks whether all the generators
+// with targets in the unit enabled the DO NOT EDIT header.
+func doNotEditUnit(options *config.ReposeOptions, generators []generator.Generator, targets map[string][]string) bool {
+	enabled := false
+
+	for _, g := range generators {
+		if len(targets[g.Name()]) == 0 {
+			continue
+		}
+
+		genOpts := options.Generators[g.Name()]
+		if genOpts == nil || !genOpts.DoNotEdit {
+			return false
+		}
+
+		enabled = true
+	}
+
+	return enabled
 }
 
 // Essentially a single file
@@ -300,18 +616,36 @@ func generateUnit(
 	generators []generator.Generator,
 	targets map[string][]string,
 	w io.Writer,
+	files generator.Files,
 ) error {
 	codeBuf := &bytes.Buffer{}
 	jenFile := jen.NewFile(options.PackageName)
 
+	doNotEdit := doNotEditUnit(options, generators, targets)
+
+	if doNotEdit {
+		jenFile.HeaderComment("Code generated by Repose. DO NOT EDIT.")
+	}
+
 	if options.Comments {
 		if options.Timestamp {
-			jenFile.HeaderComment(fmt.Sprintf("This code was generated by Repose at %v.", time.Now().Format(time.RFC1123)))
-		} else {
+			generatedAt, err := options.GenerationTime()
+			if err != nil {
+				return err
+			}
+
+			jenFile.HeaderComment(fmt.Sprintf("This code was generated by Repose at %v.", generatedAt.Format(time.RFC1123)))
+		} else if !doNotEdit {
 			jenFile.HeaderComment(fmt.Sprintf("This code was generated by Repose."))
 		}
 	}
 
+	// Units of targets that only generate separate files are not written.
+	hasCode := false
+
+	// Outputs that are not Go code are written after the Go code.
+	hasRaw := false
+
 	for _, g := range generators {
 		for _, t := range targets[g.Name()] {
 			out, err := g.Generate(ctx, options.Generators[g.Name()].Options, spec, t)
@@ -322,11 +656,23 @@ func generateUnit(
 			switch c := out.(type) {
 			case jen.Code:
 				jenFile.Add(c)
+				hasCode = true
 			case []byte:
 				codeBuf.Write(c)
 				codeBuf.WriteString("\n")
+				hasCode = true
+				hasRaw = true
 			case string:
 				codeBuf.WriteString(c + "\n")
+				hasCode = true
+				hasRaw = true
+			case generator.Files:
+				for name, content := range c {
+					if _, ok := files[name]; ok {
+						return fmt.Errorf("generator %v: file %v is generated more than once", g.Name(), name)
+					}
+					files[name] = content
+				}
 			default:
 				panic("generator gave wrong output: " + fmt.Sprint(c))
 			}
@@ -335,6 +681,10 @@ func generateUnit(
 		}
 	}
 
+	if !hasCode {
+		return nil
+	}
+
 	goCodeBuf := &bytes.Buffer{}
 
 	for name, path := range ctx.Value(common.ContextState).(*common.State).PackageAliases() {
@@ -356,14 +706,38 @@ func generateUnit(
 		return fmt.Errorf("write failed: %w", err)
 	}
 
-	_, err = io.Copy(w, codeBuf)
+	_, err = w.Write(convertLineEndings(codeBuf.Bytes(), options.LineEnding))
 	if err != nil {
 		return fmt.Errorf("write failed: %w", err)
 	}
 
+	// The end marker is a Go comment that could corrupt the other outputs,
+	// so it is only added after Go code, which always has LF line endings.
+	if options.EndMarker && !hasRaw {
+		_, err = w.Write([]byte("\n" + endMarkerComment + "\n"))
+		if err != nil {
+			return fmt.Errorf("write failed: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// endMarkerComment is the end marker added after the generated code.
+const endMarkerComment = "// repose:end Code after this line is preserved."
+
+// convertLineEndings converts all line endings
+// in b to the given line ending.
+func convertLineEndings(b []byte, lineEnding string) []byte {
+	b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+
+	if strings.ToLower(lineEnding) == config.LineEndingCRLF {
+		b = bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n"))
+	}
+
+	return b
+}
+
 func parseSpec(
 	ctx context.Context,
 	cliOpts *config.GenerateOptions,
@@ -387,21 +761,14 @@ func parseSpec(
 			return nil, fmt.Errorf("failed to read from standard input %w", err)
 		}
 
-		errStrings := make([]string, 0, len(parsers))
-
-		for _, p := range parsers {
-			spec, err := p.Parse(ctx, options.Parsers[p.Name()], data)
-			if err != nil {
-				errStrings = append(errStrings, fmt.Sprintf("%v: %v", p.Name(), err.Error()))
-				continue
-			}
-
-			cli.Successf("Specification was successfully parsed by the %v parser.\n", p.Name())
-
-			return spec, nil
+		spec, p, err := parseData(ctx, options, data)
+		if err != nil {
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("no parsers could parse the data, parsers tried:\n%v", strings.Join(errStrings, "\n\n"))
+		cli.Successf("Specification was successfully parsed by the %v parser.\n", p.Name())
+
+		return spec, nil
 	}
 
 	filePaths := make([]string, 0)
@@ -443,6 +810,59 @@ func parseSpec(
 	return nil, fmt.Errorf("no parsers could parse the input files, parsers tried:\n%v", strings.Join(errStrings, "\n\n"))
 }
 
+// parseData parses the specification data with the first parser that can parse it.
+func parseData(ctx context.Context, options *config.ReposeOptions, data []byte) (*spec.Spec, parser.Parser, error) {
+	parsers, err := getParsers(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	errStrings := make([]string, 0, len(parsers))
+
+	for _, p := range parsers {
+		spec, err := p.Parse(ctx, options.Parsers[p.Name()], data)
+		if err != nil {
+			errStrings = append(errStrings, fmt.Sprintf("%v: %v", p.Name(), err.Error()))
+			continue
+		}
+
+		return spec, p, nil
+	}
+
+	return nil, nil, fmt.Errorf("no parsers could parse the data, parsers tried:\n%v", strings.Join(errStrings, "\n\n"))
+}
+
+// copyOptions copies the options deep enough for
+// the names and targets to be changed in the copy.
+func copyOptions(options *config.ReposeOptions) *config.ReposeOptions {
+	optsCopy := *options
+
+	if options.Parsers != nil {
+		optsCopy.Parsers = make(map[string]interface{}, len(options.Parsers))
+		for pName, pVal := range options.Parsers {
+			optsCopy.Parsers[pName] = pVal
+		}
+	}
+
+	if options.Transformers != nil {
+		optsCopy.Transformers = make([]*config.Transformer, 0, len(options.Transformers))
+		for _, transformer := range options.Transformers {
+			transformerCopy := *transformer
+			optsCopy.Transformers = append(optsCopy.Transformers, &transformerCopy)
+		}
+	}
+
+	if options.Generators != nil {
+		optsCopy.Generators = make(map[string]*config.Generator, len(options.Generators))
+		for gName, gVal := range options.Generators {
+			generatorCopy := *gVal
+			optsCopy.Generators[gName] = &generatorCopy
+		}
+	}
+
+	return &optsCopy
+}
+
 func normalizeNames(options *config.ReposeOptions) {
 	for pName, pVal := range options.Parsers {
 		normalizedName := strings.ToLower(strings.TrimSpace(pName))
@@ -543,7 +963,7 @@ func getGenerators(cliOpts *config.GenerateOptions, options *config.ReposeOption
 
 	for gName := range options.Generators {
 		var found bool
-		for _, generator := range config.Generators {
+		for _, generator := range config.AllGenerators() {
 			if generator.Name() == gName {
 				generators = append(generators, generator)
 				found = true
@@ -591,6 +1011,12 @@ func writeFile(cliOpts *config.GenerateOptions, codeBuf io.ReadSeeker, path stri
 			}
 		}
 	} else {
+		// Files of generators can be in subdirectories.
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			return err
+		}
+
 		f, err := os.Create(path)
 		if err != nil {
 			return err
@@ -675,6 +1101,71 @@ func writeFile(cliOpts *config.GenerateOptions, codeBuf io.ReadSeeker, path stri
 	return nil
 }
 
+// endMarker marks the end of the generated code,
+// everything after it is preserved.
+var endMarker = regexp.MustCompile(`repose:end\b`)
+
+// checkFile compares the generated code with an existing file,
+// the contents of keep blocks and code after the end marker are ignored.
+func checkFile(generated []byte, path string) (bool, error) {
+	existing, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	existingCode, err := generatedRegions(bytes.NewReader(existing))
+	if err != nil {
+		return false, err
+	}
+
+	generatedCode, err := generatedRegions(bytes.NewReader(generated))
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(existingCode, generatedCode), nil
+}
+
+// generatedRegions returns the code without the contents of keep blocks,
+// timestamps and the code after the end marker.
+func generatedRegions(r io.Reader) ([]byte, error) {
+	keepStart := regexp.MustCompile(`repose:keep\s([a-zA-Z0-9_]+)\s?\b`)
+	timestamp := regexp.MustCompile(`This code was generated by Repose at .*`)
+
+	code := &bytes.Buffer{}
+
+	scn := bufio.NewScanner(r)
+
+	keep := false
+	for scn.Scan() {
+		line := bytes.TrimRight(scn.Bytes(), "\r")
+
+		if keep {
+			if bytes.Contains(line, []byte("repose:endkeep")) {
+				keep = false
+			}
+			continue
+		}
+
+		if endMarker.Match(line) {
+			code.Write(line)
+			break
+		}
+
+		if keepStart.Match(line) {
+			keep = true
+		}
+
+		code.Write(timestamp.ReplaceAll(line, []byte("This code was generated by Repose.")))
+		code.WriteByte('\n')
+	}
+
+	return code.Bytes(), scn.Err()
+}
+
 type keepBlock struct {
 	tag  string
 	code *bytes.Buffer
@@ -705,8 +1196,13 @@ func writeWithKeep(existing, generated io.ReadSeeker, out, outOld io.Writer) err
 	keepStart := regexp.MustCompile(`repose:keep\s([a-zA-Z0-9_]+)\s?\b`)
 
 	keep := false
+	hasEnd := false
 	for scn.Scan() {
 		if !keep {
+			if endMarker.Match(scn.Bytes()) {
+				hasEnd = true
+			}
+
 			foundTags := keepStart.FindStringSubmatch(scn.Text())
 
 			if len(foundTags) != 0 {
@@ -735,6 +1231,25 @@ func writeWithKeep(existing, generated io.ReadSeeker, out, outOld io.Writer) err
 		}
 	}
 
+	// Carry over the code after the end marker
+	// only if the generated code has one as well.
+	if hasEnd {
+		_, err = existing.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		trailing, err := trailingCode(existing)
+		if err != nil {
+			return err
+		}
+
+		_, err = out.Write(trailing)
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(obsoleteKeep) > 0 {
 		for _, b := range obsoleteKeep {
 			_, err = outOld.Write(b.code.Bytes())
@@ -830,6 +1345,26 @@ func diffKeepBlocks(targetKeep, oldKeep map[string]keepBlock) (map[string]keepBl
 	return obsolete, nil
 }
 
+// trailingCode returns everything after the end marker.
+func trailingCode(r io.Reader) ([]byte, error) {
+	trailing := &bytes.Buffer{}
+
+	scn := bufio.NewScanner(r)
+
+	found := false
+	for scn.Scan() {
+		if !found {
+			found = endMarker.Match(scn.Bytes())
+			continue
+		}
+
+		trailing.Write(scn.Bytes())
+		trailing.WriteByte('\n')
+	}
+
+	return trailing.Bytes(), scn.Err()
+}
+
 func keepBlocks(r io.Reader) (map[string]keepBlock, error) {
 	keepStart := regexp.MustCompile(`repose:keep\s([a-zA-Z0-9_]+)\s?\b`)
 
@@ -841,6 +1376,11 @@ func keepBlocks(r io.Reader) (map[string]keepBlock, error) {
 	for scn.Scan() {
 		foundTags := keepStart.FindStringSubmatch(scn.Text())
 		if currentKeep == nil {
+			// Code after the end marker is kept as a whole.
+			if endMarker.Match(scn.Bytes()) {
+				break
+			}
+
 			if len(foundTags) == 0 {
 				continue
 			} else if len(foundTags) > 2 {
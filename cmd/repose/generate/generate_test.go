@@ -0,0 +1,228 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/tamasfe/repose/cmd/repose/config"
+	"github.com/tamasfe/repose/pkg/generator"
+	"github.com/tamasfe/repose/pkg/generator/golang"
+	"github.com/tamasfe/repose/pkg/spec"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestGenerateSpecDefaultTargets(t *testing.T) {
+	sp := &spec.Spec{
+		Schemas: []*spec.Schema{
+			spec.NewSchema().WithName("Pet").ShouldCreate(true).Struct(map[string]*spec.Schema{
+				"Name": spec.NewSchema().Primitive("string"),
+			}),
+		},
+	}
+
+	src, _, err := GenerateSpec(sp, []generator.Generator{&golang.General{}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, strings.Contains(string(src), "type Pet struct"), true)
+
+	// The validator needs the specification file as well.
+	src, _, err = GenerateSpec(sp, []generator.Generator{&golang.General{}, &golang.Echo{}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, strings.Contains(string(src), "RegisterEchoServer"), true)
+	assert.Equal(t, strings.Contains(string(src), "APISpecification"), false)
+}
+
+func TestGenerateFilesKeepsOptions(t *testing.T) {
+	data := []byte(`{
+		"openapi": "3.0.0",
+		"info": {"title": "Pets", "version": "1.0.0"},
+		"paths": {},
+		"components": {
+			"schemas": {
+				"Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+			}
+		}
+	}`)
+
+	options := config.DefaultReposeOptions()
+	options.Generators[" Go-General "] = &config.Generator{Targets: []string{"types"}}
+
+	files, err := GenerateFiles(options, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, src := range files {
+		if strings.Contains(string(src), "type Pet struct") {
+			found = true
+		}
+	}
+	assert.Equal(t, found, true)
+
+	// The caller's options must not be normalized or defaulted.
+	assert.Equal(t, options.PackageName, "")
+	assert.Equal(t, len(options.Generators), 1)
+
+	gen, ok := options.Generators[" Go-General "]
+	assert.Equal(t, ok, true)
+	assert.Equal(t, len(gen.Targets), 1)
+	assert.Equal(t, gen.Targets[0], "types")
+}
+
+func TestGenerateInvalidPackageName(t *testing.T) {
+	for _, name := range []string{"my-api", "type", "_", "1api"} {
+		err := Generate(&config.GenerateOptions{PackageName: name}, config.DefaultReposeOptions(), nil)
+		assert.NotEqual(t, err, nil)
+		assert.MatchRegex(t, err.Error(), "not a valid Go package name")
+	}
+}
+
+// rawGenerator generates a JSON document instead of Go code.
+type rawGenerator struct{}
+
+func (rawGenerator) Name() string                { return "raw" }
+func (rawGenerator) Description() string         { return "Raw JSON output" }
+func (rawGenerator) Targets() map[string]string  { return map[string]string{"json": "JSON document"} }
+func (rawGenerator) DefaultOptions() interface{} { return nil }
+
+func (rawGenerator) Generate(ctx context.Context, options interface{}, sp *spec.Spec, target string) (interface{}, error) {
+	return []byte("{\n  \"a\": 1\n}"), nil
+}
+
+func TestRegisteredGenerators(t *testing.T) {
+	generator.Register(registeredGenerator{})
+
+	options := config.DefaultReposeOptions()
+	options.Generators["registered-raw"] = &config.Generator{Targets: []string{"json"}}
+
+	generators, err := getGenerators(&config.GenerateOptions{}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, len(generators), 1)
+	assert.Equal(t, generators[0].Name(), "registered-raw")
+}
+
+// registeredGenerator is registered like an external generator.
+type registeredGenerator struct {
+	rawGenerator
+}
+
+func (registeredGenerator) Name() string { return "registered-raw" }
+
+func TestEndMarker(t *testing.T) {
+	sp := &spec.Spec{
+		Schemas: []*spec.Schema{
+			spec.NewSchema().WithName("Pet").ShouldCreate(true).Struct(map[string]*spec.Schema{
+				"Name": spec.NewSchema().Primitive("string"),
+			}),
+		},
+	}
+
+	options := config.DefaultReposeOptions()
+	options.PackageName = "api"
+	options.EndMarker = true
+	options.LineEnding = config.LineEndingCRLF
+	options.Generators["go-general"] = &config.Generator{Targets: []string{"types"}}
+
+	ctx, err := newContext(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	err = generateUnit(ctx, options, sp, []generator.Generator{&golang.General{}}, map[string][]string{
+		"go-general": {"types"},
+	}, buf, make(generator.Files))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Go code always has LF line endings.
+	assert.Equal(t, bytes.HasSuffix(buf.Bytes(), []byte("\n"+endMarkerComment+"\n")), true)
+	assert.Equal(t, bytes.Contains(buf.Bytes(), []byte("\r\n")), false)
+
+	// The marker is not added to other outputs.
+	options.Generators["raw"] = &config.Generator{Targets: []string{"json"}}
+	buf.Reset()
+
+	err = generateUnit(ctx, options, sp, []generator.Generator{rawGenerator{}}, map[string][]string{
+		"raw": {"json"},
+	}, buf, make(generator.Files))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, strings.Contains(buf.String(), "repose:end"), false)
+	assert.Equal(t, strings.HasSuffix(buf.String(), "{\r\n  \"a\": 1\r\n}\r\n"), true)
+}
+
+func TestWriteWithKeep(t *testing.T) {
+	existing := `package api
+
+// repose:keep imports
+import "fmt"
+// repose:endkeep
+
+func a() {}
+
+` + endMarkerComment + `
+
+func custom() { fmt.Println() }
+`
+
+	generated := `package api
+
+// repose:keep imports
+// repose:endkeep
+
+func b() {}
+
+` + endMarkerComment + `
+`
+
+	out := &bytes.Buffer{}
+	outOld := &bytes.Buffer{}
+
+	err := writeWithKeep(strings.NewReader(existing), strings.NewReader(generated), out, outOld)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The keep blocks and the code after the marker are preserved.
+	assert.Equal(t, out.String(), `package api
+
+// repose:keep imports
+import "fmt"
+// repose:endkeep
+
+func b() {}
+
+`+endMarkerComment+`
+
+func custom() { fmt.Println() }
+`)
+	assert.Equal(t, outOld.Len(), 0)
+
+	// The code after the marker is only carried over
+	// if the generated code has a marker as well.
+	out.Reset()
+
+	err = writeWithKeep(strings.NewReader(existing), strings.NewReader(strings.Replace(generated, endMarkerComment+"\n", "", 1)), out, outOld)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, strings.Contains(out.String(), "func b() {}"), true)
+	assert.Equal(t, strings.Contains(out.String(), "func custom()"), false)
+}
@@ -89,7 +89,7 @@ type Server interface {
 	//
 	DeletePet(c echo.Context, id int64) (DeletePetHandlerResponse, error)
 
-	// FindPetById is the "GET" operation for path "/pets/{id}".
+	// FindPetByID is the "GET" operation for path "/pets/{id}".
 	//
 	// Description: Returns a user based on a single ID, if the user does not have
 	// access to the pet.
@@ -105,7 +105,7 @@ type Server interface {
 	//     "Error" (default): with content-type application/json.
 	//     Description: unexpected error.
 	//
-	FindPetById(c echo.Context, id int64) (FindPetByIdHandlerResponse, error)
+	FindPetByID(c echo.Context, id int64) (FindPetByIDHandlerResponse, error)
 
 	// Middleware allows attaching middleware to each operation.
 	Middleware() *ServerMiddleware
@@ -116,7 +116,7 @@ type ServerMiddleware struct {
 	AddPet      []echo.MiddlewareFunc
 	FindPets    []echo.MiddlewareFunc
 	DeletePet   []echo.MiddlewareFunc
-	FindPetById []echo.MiddlewareFunc
+	FindPetByID []echo.MiddlewareFunc
 }
 
 // EchoInstance is required to add handlers to
@@ -197,13 +197,13 @@ func RegisterEchoServer(e EchoInstance, server Server) {
 			id = _v
 		}
 
-		result, err := server.FindPetById(c, id)
+		result, err := server.FindPetByID(c, id)
 		if err != nil {
 			return err
 		}
-		return result.FindPetByIdHandlerResponse(c)
+		return result.FindPetByIDHandlerResponse(c)
 
-	}, middleware.FindPetById...)
+	}, middleware.FindPetByID...)
 
 }
 
@@ -261,18 +261,18 @@ func (r resDeletePetResponse204) DeletePetHandlerResponse(ctx echo.Context) erro
 	return nil
 }
 
-// FindPetByIdHandlerResponse defines responses for the FindPetById operation.
-type FindPetByIdHandlerResponse interface {
-	FindPetByIdHandlerResponse(echo.Context) error
+// FindPetByIDHandlerResponse defines responses for the FindPetByID operation.
+type FindPetByIDHandlerResponse interface {
+	FindPetByIDHandlerResponse(echo.Context) error
 }
 
-func (n noResponse) FindPetByIdHandlerResponse(ctx echo.Context) error {
+func (n noResponse) FindPetByIDHandlerResponse(ctx echo.Context) error {
 	return nil
 }
 
-// FindPetByIdHandlerResponse is implemented for Pet so that it can be used in a
+// FindPetByIDHandlerResponse is implemented for Pet so that it can be used in a
 // response.
-func (p *Pet) FindPetByIdHandlerResponse(ctx echo.Context) error {
+func (p *Pet) FindPetByIDHandlerResponse(ctx echo.Context) error {
 	if p == nil {
 		ctx.NoContent(200)
 		return nil
@@ -94,8 +94,8 @@ func (c clientPetsWithID) DeletePet(id int64) (*http.Request, error) {
 	return _req, nil
 }
 
-// FindPetById provides client request for the operation.
-func (c clientPetsWithID) FindPetById(id int64) (*http.Request, error) {
+// FindPetByID provides client request for the operation.
+func (c clientPetsWithID) FindPetByID(id int64) (*http.Request, error) {
 	var _bodyData io.Reader
 	idData := fmt.Sprint(id)
 
@@ -110,7 +110,7 @@ func (s *ServerImpl) DeletePet(c echo.Context, id int64) (api.DeletePetHandlerRe
 	// repose:endkeep
 }
 
-// FindPetById is the "GET" operation for path "/pets/{id}".
+// FindPetByID is the "GET" operation for path "/pets/{id}".
 //
 // Description: Returns a user based on a single ID, if the user does not have
 // access to the pet.
@@ -126,8 +126,8 @@ func (s *ServerImpl) DeletePet(c echo.Context, id int64) (api.DeletePetHandlerRe
 //     "Error" (default): with content-type application/json.
 //     Description: unexpected error.
 //
-func (s *ServerImpl) FindPetById(c echo.Context, id int64) (api.FindPetByIdHandlerResponse, error) {
-	// repose:keep FindPetById_body
+func (s *ServerImpl) FindPetByID(c echo.Context, id int64) (api.FindPetByIDHandlerResponse, error) {
+	// repose:keep FindPetByID_body
 	return &api.Pet{
 		NewPet: api.NewPet{
 			Name: "Bonnie",
@@ -55,7 +55,7 @@ func main() {
 		transformersBuilder.WriteString("\n")
 	}
 
-	for _, g := range config.Generators {
+	for _, g := range config.AllGenerators() {
 		genMd, ok := g.(common.DescriptionMarkdown)
 		if !ok {
 			continue
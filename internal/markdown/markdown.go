@@ -24,6 +24,12 @@ func OptionsTable(opts interface{}) string {
 	fields := make([]string, 0, optsTp.NumField())
 	for i := 0; i < optsTp.NumField(); i++ {
 		field := optsTp.Field(i)
+
+		// Unexported fields are not options.
+		if field.PkgPath != "" {
+			continue
+		}
+
 		fieldNames[field.Name] = i
 		fields = append(fields, field.Name)
 	}
@@ -0,0 +1,142 @@
+package markdown
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OptionsSchema returns a JSON Schema describing the given options,
+// the properties are named after the yaml tags, and they are
+// described by the description tags of the fields.
+//
+// Default values are taken from opts for scalar fields.
+func OptionsSchema(opts interface{}) map[string]interface{} {
+	return valueSchema(reflect.ValueOf(opts), reflect.TypeOf(opts))
+}
+
+func valueSchema(val reflect.Value, tp reflect.Type) map[string]interface{} {
+	if tp == nil {
+		return map[string]interface{}{}
+	}
+
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+		if val.IsValid() {
+			if val.IsNil() {
+				val = reflect.Value{}
+			} else {
+				val = val.Elem()
+			}
+		}
+	}
+
+	schema := make(map[string]interface{})
+
+	switch tp.Kind() {
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.String:
+		schema["type"] = "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = valueSchema(reflect.Value{}, tp.Elem())
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = valueSchema(reflect.Value{}, tp.Elem())
+	case reflect.Struct:
+		schema["type"] = "object"
+		schema["properties"] = structProperties(val, tp)
+		schema["additionalProperties"] = false
+	case reflect.Interface:
+		// Anything goes.
+		return schema
+	}
+
+	if val.IsValid() && isScalar(tp.Kind()) {
+		schema["default"] = val.Interface()
+	}
+
+	return schema
+}
+
+func structProperties(val reflect.Value, tp reflect.Type) map[string]interface{} {
+	props := make(map[string]interface{}, tp.NumField())
+
+	fields := make([]string, 0, tp.NumField())
+	fieldIndices := make(map[string]int, tp.NumField())
+	for i := 0; i < tp.NumField(); i++ {
+		field := tp.Field(i)
+		fieldIndices[field.Name] = i
+		fields = append(fields, field.Name)
+	}
+
+	sort.Strings(fields)
+
+	for _, f := range fields {
+		field := tp.Field(fieldIndices[f])
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		name := tag[0]
+
+		if name == "-" {
+			continue
+		}
+
+		var fieldVal reflect.Value
+		if val.IsValid() {
+			fieldVal = val.Field(fieldIndices[f])
+		}
+
+		fieldSchema := valueSchema(fieldVal, field.Type)
+
+		inline := false
+		for _, t := range tag[1:] {
+			if t == "inline" {
+				inline = true
+			}
+		}
+
+		if inline {
+			if inlineProps, ok := fieldSchema["properties"].(map[string]interface{}); ok {
+				for n, p := range inlineProps {
+					props[n] = p
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		if desc := field.Tag.Get("description"); desc != "" {
+			fieldSchema["description"] = desc
+		}
+
+		props[name] = fieldSchema
+	}
+
+	return props
+}
+
+func isScalar(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+// CommonTestOptions is exported, as unexported embedded fields are skipped.
+type CommonTestOptions struct {
+	Verbose bool `yaml:"verbose" description:"Verbose output"`
+}
+
+type testOptions struct {
+	CommonTestOptions `yaml:",inline"`
+
+	Name     string                 `yaml:"name" description:"Name of the thing"`
+	Count    int                    `yaml:"count"`
+	Ratio    *float64               `yaml:"ratio,omitempty"`
+	Tags     []string               `yaml:"tags"`
+	Labels   map[string]string      `yaml:"labels"`
+	Options  map[string]interface{} `yaml:"options"`
+	Nested   *CommonTestOptions     `yaml:"nested"`
+	Untagged string
+	Skipped  string `yaml:"-"`
+
+	unexported string
+}
+
+func TestOptionsSchema(t *testing.T) {
+	schema := OptionsSchema(&testOptions{
+		CommonTestOptions: CommonTestOptions{Verbose: true},
+		Name:              "repose",
+		Count:             3,
+	})
+
+	assert.Equal(t, schema["type"], "object")
+	assert.Equal(t, schema["additionalProperties"], false)
+
+	props := schema["properties"].(map[string]interface{})
+	prop := func(name string) map[string]interface{} {
+		p, ok := props[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("missing property %v", name)
+		}
+		return p
+	}
+
+	// Properties are named after the yaml tags, and described by the description tags.
+	assert.Equal(t, prop("name")["type"], "string")
+	assert.Equal(t, prop("name")["description"], "Name of the thing")
+	assert.Equal(t, prop("name")["default"], "repose")
+	assert.Equal(t, prop("count")["type"], "integer")
+	assert.Equal(t, prop("count")["default"], 3)
+
+	// Nil pointers have no defaults.
+	assert.Equal(t, prop("ratio")["type"], "number")
+	_, hasDefault := prop("ratio")["default"]
+	assert.Equal(t, hasDefault, false)
+
+	assert.Equal(t, prop("tags")["type"], "array")
+	assert.Equal(t, prop("tags")["items"].(map[string]interface{})["type"], "string")
+	assert.Equal(t, prop("labels")["type"], "object")
+	assert.Equal(t, prop("labels")["additionalProperties"].(map[string]interface{})["type"], "string")
+
+	// Any value is accepted by interfaces.
+	_, hasType := prop("options")["additionalProperties"].(map[string]interface{})["type"]
+	assert.Equal(t, hasType, false)
+
+	nested := prop("nested")
+	assert.Equal(t, nested["type"], "object")
+	assert.Equal(t, nested["properties"].(map[string]interface{})["verbose"].(map[string]interface{})["type"], "boolean")
+
+	// Inline fields are merged into the parent.
+	assert.Equal(t, prop("verbose")["type"], "boolean")
+	assert.Equal(t, prop("verbose")["default"], true)
+
+	// Fields without a yaml name use the lower-cased field name.
+	assert.Equal(t, prop("untagged")["type"], "string")
+
+	_, ok := props["Skipped"]
+	assert.Equal(t, ok, false)
+	_, ok = props["-"]
+	assert.Equal(t, ok, false)
+	_, ok = props["unexported"]
+	assert.Equal(t, ok, false)
+
+	assert.Equal(t, len(props), 9)
+}
@@ -1,5 +1,11 @@
 package common
 
+import (
+	"context"
+
+	"github.com/tamasfe/repose/pkg/util"
+)
+
 // DescriptionMarkdown simply allows for getting markdown text.
 type DescriptionMarkdown interface {
 	DescriptionMarkdown() string
@@ -59,4 +65,12 @@ const (
 	ContextState            = "state"
 	ContextCommonOptions    = "options"
 	ContextGeneratorOptions = "generatorOptions"
+	ContextInitialisms      = "initialisms"
 )
+
+// Initialisms returns the initialisms of the context
+// that are upper-cased in generated names, or nil if there are none.
+func Initialisms(ctx context.Context) util.Initialisms {
+	initialisms, _ := ctx.Value(ContextInitialisms).(util.Initialisms)
+	return initialisms
+}
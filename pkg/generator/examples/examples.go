@@ -0,0 +1,300 @@
+package examples
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/tamasfe/repose/internal/markdown"
+	"github.com/tamasfe/repose/pkg/generator"
+	"github.com/tamasfe/repose/pkg/spec"
+	"github.com/tamasfe/repose/pkg/util"
+)
+
+// Examples writes the examples in the specification into JSON files.
+type Examples struct{}
+
+// ExamplesOptions is the options for the Examples generator.
+type ExamplesOptions struct {
+	Directory string `yaml:"directory" description:"Directory of the example files relative to the output directory"`
+	Indent    bool   `yaml:"indent" description:"Indent the JSON in the example files"`
+}
+
+// Name implements Generator
+func (e *Examples) Name() string {
+	return "examples"
+}
+
+// Description implements Generator
+func (e *Examples) Description() string {
+	return "Writes the examples of the specification into JSON files"
+}
+
+// DescriptionMarkdown implements DescriptionMarkdown
+func (e *Examples) DescriptionMarkdown() string {
+	desc := `
+# Description
+
+This generator writes the examples of the request and response bodies
+into JSON files, that can be used as fixtures in tests.
+
+The files are named after the operation, the status code of the response
+(or "request" for request bodies) and the name of the example, e.g.
+CreatePet.201.default.json. Examples with external values are skipped.
+
+The examples of the other parameters are written into objects keyed by
+the parameter locations (path, query or header) and then by the parameter
+names, one for each example name, e.g. FindPets.parameters.default.json,
+that can be used as the arguments of client calls. Parameters without an
+example of the given name fall back to their default example.
+
+# Options
+
+## List of all options
+
+{{ .OptionsTable }}
+
+## Example usage in Repose config
+
+{{ .OptionsExample }}
+
+# Targets
+
+{{ .TargetsTable }}
+`[1:]
+
+	buf := &bytes.Buffer{}
+
+	templ, err := template.New("desc").Parse(desc)
+	if err != nil {
+		panic(err)
+	}
+
+	yamlComments := util.DisableYAMLMarshalComments
+
+	util.DisableYAMLMarshalComments = true
+
+	err = templ.Execute(buf,
+		map[string]interface{}{
+			"OptionsTable": markdown.OptionsTable(*e.DefaultOptions().(*ExamplesOptions)),
+			"OptionsExample": "```yaml\n" + string(util.MustMarshalYAML(
+				map[string]interface{}{
+					"examples": e.DefaultOptions(),
+				},
+			)) + "```\n",
+			"TargetsTable": markdown.TargetsTable(e.Targets()),
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	util.DisableYAMLMarshalComments = yamlComments
+
+	return buf.String()
+}
+
+// Targets implements Generator
+func (e *Examples) Targets() map[string]string {
+	return map[string]string{
+		"fixtures": "JSON files of the request, response and parameter examples",
+	}
+}
+
+// DefaultOptions implements Generator
+func (e *Examples) DefaultOptions() interface{} {
+	return &ExamplesOptions{
+		Directory: "examples",
+		Indent:    true,
+	}
+}
+
+// Generate implements Generator
+func (e *Examples) Generate(ctx context.Context, options interface{}, sp *spec.Spec, target string) (interface{}, error) {
+	opts := e.DefaultOptions().(*ExamplesOptions)
+
+	err := mapstructure.Decode(options, opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	switch target {
+	case "fixtures":
+		return e.GenerateFixtures(ctx, sp, opts)
+	default:
+		return nil, fmt.Errorf("unsupported target: %v", target)
+	}
+}
+
+// GenerateFixtures returns the JSON files of the examples of
+// the request and response bodies of all operations.
+func (e *Examples) GenerateFixtures(ctx context.Context, sp *spec.Spec, opts *ExamplesOptions) (generator.Files, error) {
+	files := make(generator.Files)
+
+	add := func(o *spec.Operation, kind string, contentType string, examples []*spec.Example) error {
+		if !util.IsJSONMediaType(contentType) {
+			return nil
+		}
+
+		for _, ex := range examples {
+			if ex.ExternalValue != "" {
+				continue
+			}
+
+			name := path.Join(opts.Directory, strings.Join([]string{o.Name, kind, fileName(ex.Name)}, ".")+".json")
+
+			// Only the first content type is kept
+			// if there are multiple JSON ones.
+			if _, ok := files[name]; ok {
+				continue
+			}
+
+			var data []byte
+			var err error
+
+			if opts.Indent {
+				data, err = json.MarshalIndent(ex.Value, "", "  ")
+			} else {
+				data, err = json.Marshal(ex.Value)
+			}
+			if err != nil {
+				return fmt.Errorf("invalid example %v of %v: %w", ex.Name, o.Name, err)
+			}
+
+			files[name] = append(data, '\n')
+		}
+
+		return nil
+	}
+
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			for _, param := range o.Parameters {
+				if param.Type != spec.ParameterTypeBody {
+					continue
+				}
+
+				err := add(o, "request", param.ContentType, param.Examples)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for _, res := range o.Responses {
+				err := add(o, strings.ToLower(res.Code), res.ContentType, res.Examples)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			params := parameterExamples(o)
+			if len(params) > 0 {
+				err := add(o, "parameters", "application/json", params)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// parameterExamples returns the examples of the parameters of the
+// operation (except for the body) grouped by the example names, the
+// values of the examples are objects keyed by the parameter locations,
+// and then by the parameter names, so parameters with the same name
+// in different locations do not collide.
+func parameterExamples(o *spec.Operation) []*spec.Example {
+	values := make(map[string]map[string]map[string]interface{})
+	names := make([]string, 0)
+
+	for _, param := range o.Parameters {
+		if param.Type == spec.ParameterTypeBody {
+			continue
+		}
+
+		for _, ex := range param.Examples {
+			if ex.ExternalValue != "" {
+				continue
+			}
+
+			if _, ok := values[ex.Name]; !ok {
+				values[ex.Name] = make(map[string]map[string]interface{})
+				names = append(names, ex.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	examples := make([]*spec.Example, 0, len(names))
+
+	for _, name := range names {
+		for _, param := range o.Parameters {
+			if param.Type == spec.ParameterTypeBody {
+				continue
+			}
+
+			var example *spec.Example
+
+			for _, ex := range param.Examples {
+				if ex.ExternalValue != "" {
+					continue
+				}
+
+				if ex.Name == name {
+					example = ex
+					break
+				}
+
+				if ex.Name == "default" {
+					example = ex
+				}
+			}
+
+			// Parameters with multiple content types
+			// are only added once.
+			if example == nil {
+				continue
+			}
+
+			in := string(param.Type)
+
+			if _, ok := values[name][in][param.Name]; ok {
+				continue
+			}
+
+			if values[name][in] == nil {
+				values[name][in] = make(map[string]interface{})
+			}
+
+			values[name][in][param.Name] = example.Value
+		}
+
+		examples = append(examples, &spec.Example{
+			Name:  name,
+			Value: values[name],
+		})
+	}
+
+	return examples
+}
+
+// fileName replaces the characters of the
+// example name that are not safe in file names.
+func fileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
@@ -0,0 +1,87 @@
+package examples
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tamasfe/repose/pkg/common"
+	"github.com/tamasfe/repose/pkg/generator"
+	"github.com/tamasfe/repose/pkg/parser"
+	"github.com/tamasfe/repose/pkg/transformer"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestFixtures(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	sp, err := (&parser.OpenAPI3{}).Parse(ctx, nil, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "findPets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}, "example": 10},
+          {"name": "limit", "in": "header", "schema": {"type": "integer"}, "example": 20},
+          {
+            "name": "tags",
+            "in": "query",
+            "schema": {"type": "array", "items": {"type": "string"}},
+            "examples": {"dogs": {"value": ["dog"]}}
+          }
+        ],
+        "responses": {"204": {"description": "Found"}}
+      },
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "content": {
+            "application/json": {
+              "schema": {"type": "object", "properties": {"name": {"type": "string"}}},
+              "examples": {
+                "dog": {"value": {"name": "Rex"}},
+                "remote": {"externalValue": "https://example.com/pet.json"}
+              }
+            }
+          }
+        },
+        "responses": {
+          "201": {
+            "description": "Created",
+            "content": {
+              "application/json": {
+                "schema": {"type": "object", "properties": {"id": {"type": "integer"}}},
+                "example": {"id": 1}
+              },
+              "text/plain": {"example": "created"}
+            }
+          }
+        }
+      }
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&transformer.Default{}).Transform(ctx, nil, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := (&Examples{}).Generate(ctx, map[string]interface{}{"indent": false}, sp, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := out.(generator.Files)
+
+	assert.Equal(t, len(files), 4)
+	assert.Equal(t, string(files["examples/CreatePet.request.dog.json"]), "{\"name\":\"Rex\"}\n")
+	assert.Equal(t, string(files["examples/CreatePet.201.default.json"]), "{\"id\":1}\n")
+	// Parameters with the same name are kept apart by their locations.
+	assert.Equal(t, string(files["examples/FindPets.parameters.default.json"]), "{\"header\":{\"limit\":20},\"query\":{\"limit\":10}}\n")
+	assert.Equal(t, string(files["examples/FindPets.parameters.dogs.json"]), "{\"header\":{\"limit\":20},\"query\":{\"limit\":10,\"tags\":[\"dog\"]}}\n")
+}
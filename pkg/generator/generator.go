@@ -21,6 +21,17 @@ type Generator interface {
 	DefaultOptions() interface{}
 
 	// Generate generates code based on the options and targets.
-	// The generated output must be either jen.Code, []byte, or string.
+	// The generated output must be either jen.Code, []byte, string, or Files.
 	Generate(ctx context.Context, options interface{}, specification *spec.Spec, target string) (interface{}, error)
 }
+
+// TargetAliases is implemented by generators that accept
+// other names for their targets as well.
+type TargetAliases interface {
+	// TargetAliases returns the other names of the targets keyed by the targets.
+	TargetAliases() map[string][]string
+}
+
+// Files is the output of targets that generate separate files instead of code,
+// the contents of the files are keyed by their paths relative to the output directory.
+type Files map[string][]byte
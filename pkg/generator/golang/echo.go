@@ -4,15 +4,22 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mitchellh/mapstructure"
 	"github.com/tamasfe/repose/internal/markdown"
 	"github.com/tamasfe/repose/pkg/util"
 	"github.com/tamasfe/repose/pkg/util/gen"
+	"github.com/tamasfe/repose/pkg/util/gen/templates"
 
 	"github.com/dave/jennifer/jen"
 	"github.com/iancoleman/strcase"
@@ -22,16 +29,43 @@ import (
 
 const echoPath = "github.com/labstack/echo/v4"
 
+// responseValueName is the name of the response values
+// in the generated methods, a name derived from the schema
+// could collide with the parameters of the methods (e.g. "w").
+const responseValueName = "_r"
+
 // EchoOptions is the options for the Echo target.
 type EchoOptions struct {
-	ServerName            string `yaml:"serverName,omitempty" description:"Name of the server interface"`
-	ServerImplName        string `yaml:"serverImplName,omitempty" description:"Name of the server interface implementation"`
-	AllowNoResponse       bool   `yaml:"allowNoResponse" description:"Add a NoResponse value that indicates that the returned value by a handler should be ignored by the generated wrapper"`
-	ServerPackagePath     string `yaml:"serverPackagePath" description:"Path to the generated server package, used for generating the scaffold, if left empty it is assumed that it is in the same package"`
-	TypesPackagePath      string `yaml:"typesPackagePath" description:"Path to the generated types package, used for generating the server interface, if left empty it is assumed that it is in the same package"`
-	ResponsePostfix       string `yaml:"responsePostfix" description:"Postfix to add for response types, configure it to avoid collisions with actual types"`
-	ShortScaffoldComments bool   `yaml:"shortScaffoldComments" description:"Shorter scaffold comments for each method implementation"`
-	ServerMiddleware      bool   `yaml:"serverMiddleware" description:"Enable the ability to add middleware to the individual operations from a method on the server interface"`
+	ServerName               string `yaml:"serverName,omitempty" description:"Name of the server interface"`
+	ServerImplName           string `yaml:"serverImplName,omitempty" description:"Name of the server interface implementation"`
+	AllowNoResponse          bool   `yaml:"allowNoResponse" description:"Add a NoResponse value that indicates that the returned value by a handler should be ignored by the generated wrapper"`
+	ServerPackagePath        string `yaml:"serverPackagePath" description:"Full import path of the generated server package (e.g. github.com/user/module/server), used for generating the scaffold and the fake server, if left empty it is assumed that it is in the same package"`
+	TypesPackagePath         string `yaml:"typesPackagePath" description:"Full import path of the generated types package (e.g. github.com/user/module/types), used for generating the server interface and the named parameter types of the scaffold and the fake server, if left empty it is assumed that it is in the same package"`
+	ResponsePostfix          string `yaml:"responsePostfix" description:"Postfix to add for response types, configure it to avoid collisions with actual types"`
+	ShortScaffoldComments    bool   `yaml:"shortScaffoldComments" description:"Shorter scaffold comments for each method implementation"`
+	ServerMiddleware         bool   `yaml:"serverMiddleware" description:"Enable the ability to add middleware to the individual operations from a method on the server interface"`
+	GenerateOptionsHandlers  bool   `yaml:"generateOptionsHandlers" description:"Register an OPTIONS handler for each path that responds with the allowed methods in the Allow header"`
+	CORSAllowOrigin          string `yaml:"corsAllowOrigin,omitempty" description:"If set, the OPTIONS handlers also respond with CORS headers for preflight requests allowing the given origin"`
+	GenerateObserver         bool   `yaml:"generateObserver" description:"Generate an Observer interface that is passed to the register function, and is notified about the status and duration of each operation"`
+	GenerateHandlers         bool   `yaml:"generateHandlers" description:"Generate a Handlers function that returns the wrapped Echo handlers by operation name, useful for testing handlers without a router"`
+	StdlibResponses          bool   `yaml:"stdlibResponses" description:"Generate methods for the response types that write them to a http.ResponseWriter, so that they can be used outside of Echo as well"`
+	CallbackServerName       string `yaml:"callbackServerName,omitempty" description:"Name of the server interface for receiving callbacks generated by the callbacks-server target"`
+	IgnoreBasePath           bool   `yaml:"ignoreBasePath" description:"Do not prepend the relative server URL of the specification (e.g. /api/v1) to the routes, for when the server is mounted under it externally (e.g. with an Echo group)"`
+	SetOperationContextKey   string `yaml:"setOperationContextKey,omitempty" description:"If set, the wrapper stores the name of the operation in the Echo context with the given key before the handler is called, so that it can be read by the handler and by middleware after the handler returns"`
+	MaxBodyBytes             int64  `yaml:"maxBodyBytes" description:"Maximum size of request bodies in bytes, larger bodies are rejected with 413 Request Entity Too Large, 0 means no limit, operations can override it with the maxBodyBytes extension"`
+	ServerErrorHandler       bool   `yaml:"serverErrorHandler" description:"Generate an optional error handler interface, if the server implements it, the errors returned by its handlers are passed to its HandleError method before they are returned to Echo, so that they can be mapped to responses in one place"`
+	ScopeEmptyResponses      bool   `yaml:"scopeEmptyResponses" description:"Always prefix the names of the empty response values with the name of the operation (e.g. ListPetsNotFound instead of NotFound), by default only names that are used by multiple operations are prefixed"`
+	OperationContext         bool   `yaml:"operationContext" description:"Pass a context struct to each handler (e.g. FindPetByIDContext) that embeds echo.Context and contains the parsed parameters and body as fields, instead of passing them as separate arguments"`
+	StatusResponses          bool   `yaml:"statusResponses" description:"Generate a constructor (e.g. CreatePetPetWithStatus) for each response schema that is documented with multiple status codes of an operation, so that the handler can choose the status code"`
+	UseValidator             bool   `yaml:"useValidator" description:"Validate the bound request bodies with c.Validate (e.g. with go-playground/validator and validate tags added by the default transformer), invalid bodies are rejected with 400 Bad Request, a validator must be registered on the Echo instance with e.Validator, otherwise all requests with bodies are rejected"`
+	ValidateResponses        bool   `yaml:"validateResponses" description:"The middleware generated by the validator target also validates the responses of the handlers against the specification, invalid responses are logged and replaced by 500 Internal Server Error, the responses are buffered so it is meant for development"`
+	TrailingSlashInsensitive bool   `yaml:"trailingSlashInsensitive" description:"Register each route both with and without a trailing slash, unless the specification has both paths"`
+	ParamsInContext          bool   `yaml:"paramsInContext" description:"Store the parsed path, query and header parameters in the Echo context instead of passing them to the handlers, and generate typed accessor functions for them (e.g. GetLimit(c))"`
+	GenerateRequestID        bool   `yaml:"generateRequestID" description:"Generate a middleware that is registered for all operations, and assigns an ID to each request from the request ID header or a random one, the ID is stored in the contexts, can be read with RequestID(c), and is added to the response headers"`
+	RequestIDHeader          string `yaml:"requestIDHeader" description:"Name of the header of the request IDs"`
+
+	// registerName overrides the name of the register function.
+	registerName string
 }
 
 // MarshalYAML implements YAML Marshaler
@@ -51,6 +85,21 @@ func (e *Echo) Generate(ctx context.Context, options interface{}, sp *spec.Spec,
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	opts.ServerPackagePath, err = gen.NormalizeImportPath(opts.ServerPackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: serverPackagePath: %w", err)
+	}
+
+	opts.TypesPackagePath, err = gen.NormalizeImportPath(opts.TypesPackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: typesPackagePath: %w", err)
+	}
+
+	opts.RequestIDHeader = strings.TrimSpace(opts.RequestIDHeader)
+	if opts.GenerateRequestID && opts.RequestIDHeader == "" {
+		return nil, fmt.Errorf("invalid options: requestIDHeader: the header name is required to generate request IDs")
+	}
+
 	state, ok := ctx.Value(common.ContextState).(*common.State)
 	if ok {
 		state.PackageAlias("echo", echoPath)
@@ -61,6 +110,14 @@ func (e *Echo) Generate(ctx context.Context, options interface{}, sp *spec.Spec,
 		return e.GenerateServer(ctx, sp, opts)
 	case "server-scaffold", "scaffold", "srv-scaffold":
 		return e.GenerateScaffold(ctx, sp, opts)
+	case "server-fake", "fake", "srv-fake":
+		return e.GenerateFake(ctx, sp, opts)
+	case "server-route-test", "route-test":
+		return e.GenerateRouteTest(ctx, sp, opts)
+	case "validator", "server-validator":
+		return e.GenerateValidator(ctx, sp, opts)
+	case "callbacks-server", "callback-server":
+		return e.GenerateCallbacksServer(ctx, sp, opts)
 	default:
 		return nil, fmt.Errorf("target %v is not supported", target)
 	}
@@ -69,12 +126,31 @@ func (e *Echo) Generate(ctx context.Context, options interface{}, sp *spec.Spec,
 // DefaultOptions implements Generator
 func (e *Echo) DefaultOptions() interface{} {
 	return &EchoOptions{
-		ServerName:            "Server",
-		ServerImplName:        "ServerImpl",
-		AllowNoResponse:       false,
-		ShortScaffoldComments: false,
-		ResponsePostfix:       "HandlerResponse",
-		ServerMiddleware:      true,
+		ServerName:               "Server",
+		ServerImplName:           "ServerImpl",
+		AllowNoResponse:          false,
+		ShortScaffoldComments:    false,
+		ResponsePostfix:          "HandlerResponse",
+		ServerMiddleware:         true,
+		GenerateOptionsHandlers:  false,
+		CORSAllowOrigin:          "",
+		GenerateObserver:         false,
+		GenerateHandlers:         false,
+		StdlibResponses:          false,
+		CallbackServerName:       "CallbackServer",
+		IgnoreBasePath:           false,
+		SetOperationContextKey:   "",
+		MaxBodyBytes:             0,
+		ServerErrorHandler:       false,
+		ScopeEmptyResponses:      false,
+		UseValidator:             false,
+		ValidateResponses:        false,
+		TrailingSlashInsensitive: false,
+		OperationContext:         false,
+		StatusResponses:          false,
+		ParamsInContext:          false,
+		GenerateRequestID:        false,
+		RequestIDHeader:          "X-Request-ID",
 	}
 }
 
@@ -91,8 +167,24 @@ func (e *Echo) Description() string {
 // Targets implements Generator
 func (e *Echo) Targets() map[string]string {
 	return map[string]string{
-		"server":          "The server interface, and the register function",
-		"server-scaffold": "Scaffold for a server interface",
+		"server":            "The server interface, and the register function",
+		"server-scaffold":   "Scaffold for a server interface",
+		"server-fake":       "In-memory fake implementation of the server interface for tests, backed by maps",
+		"server-route-test": "Test that checks that every operation of the specification is registered by the server target, it should be generated into a _test.go file",
+		"validator":         "Middleware that validates requests against the embedded specification, requires the spec target of go-general in the same package",
+		"callbacks-server":  "The server interface, and the register function for receiving the callbacks of the operations, it should be generated in a different package than the server target",
+	}
+}
+
+// TargetAliases implements TargetAliases
+func (e *Echo) TargetAliases() map[string][]string {
+	return map[string][]string{
+		"server":            {"srv"},
+		"server-scaffold":   {"scaffold", "srv-scaffold"},
+		"server-fake":       {"fake", "srv-fake"},
+		"server-route-test": {"route-test"},
+		"validator":         {"server-validator"},
+		"callbacks-server":  {"callback-server"},
 	}
 }
 
@@ -159,12 +251,17 @@ func (e *Echo) GenerateServer(ctx context.Context, sp *spec.Spec, opts *EchoOpti
 
 	handlers := make([]jen.Code, 0)
 
+	// The context structs of the operations.
+	contextCode := jen.Null()
+
 	for _, p := range sp.Paths {
 		for _, o := range p.Operations {
 			params := make([]jen.Code, 0, len(o.Parameters)+1)
 			returns := make([]jen.Code, 0, 2)
 			params = append(params, jen.Id("c").Qual(echoPath, "Context"))
 
+			contextFields := []jen.Code{jen.Qual(echoPath, "Context")}
+
 			g := &General{}
 
 			generalOpts, err := g.GetOpts(ctx)
@@ -182,11 +279,11 @@ func (e *Echo) GenerateServer(ctx context.Context, sp *spec.Spec, opts *EchoOpti
 					continue
 				}
 
-				if param.Schema == nil {
+				if param.Schema == nil || paramInContext(param, opts) {
 					continue
 				}
 
-				paramCode := jen.Id(util.ToGoName(strcase.ToLowerCamel(param.Name)))
+				paramCode := jen.Id(common.Initialisms(ctx).ToGoName(strcase.ToLowerCamel(param.Name)))
 
 				var c jen.Code
 				if param.Schema.Name != "" {
@@ -205,6 +302,22 @@ func (e *Echo) GenerateServer(ctx context.Context, sp *spec.Spec, opts *EchoOpti
 				paramCode.Add(c)
 
 				params = append(params, paramCode)
+
+				fieldCode := jen.Id(operationContextField(ctx, param))
+				if param.IsPtr() {
+					fieldCode.Op("*")
+				}
+				contextFields = append(contextFields, fieldCode.Add(c))
+			}
+
+			if opts.OperationContext {
+				if options.Comments {
+					contextCode.Commentf("// %v is passed to the %v handler,", o.Name+"Context", strcase.ToCamel(o.Name)).Line()
+					contextCode.Comment("// it contains the parsed parameters of the operation.").Line()
+				}
+				contextCode.Type().Id(o.Name + "Context").Struct(contextFields...).Line().Line()
+
+				params = []jen.Code{jen.Id("c").Op("*").Id(o.Name + "Context")}
 			}
 
 			returns = append(returns, jen.Id(o.Name+opts.ResponsePostfix), jen.Error())
@@ -281,11 +394,259 @@ func (e *Echo) GenerateServer(ctx context.Context, sp *spec.Spec, opts *EchoOpti
 
 	return code.
 		Add(resCode).Line().
+		Add(contextCode).
 		Add(mwTypeCode).Line().
 		Add(wrapperCode).Line().
 		Add(returnInterfaces).Line(), nil
 }
 
+// GenerateCallbacksServer generates a server interface and
+// a register function for the callbacks of the operations,
+// so that incoming callback requests can be handled.
+//
+// The routes are the callback paths without the runtime expressions,
+// or the names of the callbacks if nothing else is left of the paths
+// (e.g. /onPetAdded for {$request.body#/callbackUrl}).
+//
+// Callbacks that are shared by operations (e.g. with $ref) are only
+// handled once, other callbacks with the same route are an error.
+func (e *Echo) GenerateCallbacksServer(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
+	cbSpec := &spec.Spec{
+		Schemas: sp.Schemas,
+	}
+
+	// The operations of the routes for detecting conflicts.
+	routes := make(map[string]string)
+
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			cbNames := make([]string, 0, len(o.Callbacks))
+			for name := range o.Callbacks {
+				cbNames = append(cbNames, name)
+			}
+			sort.Strings(cbNames)
+
+			for _, name := range cbNames {
+				for _, cbPath := range o.Callbacks[name] {
+					routePath := *cbPath
+					routePath.PathString = callbackRoutePath(cbPath.PathString)
+					if routePath.PathString == "/" {
+						routePath.PathString += url.PathEscape(name)
+					}
+
+					routePath.Operations = make([]*spec.Operation, 0, len(cbPath.Operations))
+
+					for _, cbOp := range cbPath.Operations {
+						route := strings.ToUpper(cbOp.Method) + " " + routePath.PathString
+						if other, ok := routes[route]; ok {
+							if other == cbOp.Name {
+								continue
+							}
+
+							return nil, fmt.Errorf(
+								"callbacks %v and %v have the same route %v",
+								other, cbOp.Name, route,
+							)
+						}
+						routes[route] = cbOp.Name
+						routePath.Operations = append(routePath.Operations, cbOp)
+					}
+
+					if len(routePath.Operations) > 0 {
+						cbSpec.Paths = append(cbSpec.Paths, &routePath)
+					}
+				}
+			}
+		}
+	}
+
+	cbOpts := *opts
+	cbOpts.ServerName = opts.CallbackServerName
+	cbOpts.registerName = "RegisterEcho" + opts.CallbackServerName
+
+	return e.GenerateServer(ctx, cbSpec, &cbOpts)
+}
+
+// callbackRoutePath returns the path of a callback
+// without the runtime expressions and the query string.
+func callbackRoutePath(pathString string) string {
+	p := regexp.MustCompile(`\{\$[^}]+\}`).ReplaceAllString(pathString, "")
+
+	if i := strings.Index(p, "?"); i != -1 {
+		p = p[:i]
+	}
+
+	// Drop the scheme and host if the callback has a static URL.
+	if u, err := url.Parse(p); err == nil && u.Host != "" {
+		p = u.Path
+	}
+
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	return p
+}
+
+// GenerateValidator generates a middleware that validates
+// the requests with kin-openapi against the specification
+// embedded by the spec target of the General generator.
+func (e *Echo) GenerateValidator(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	c := jen.Null()
+
+	if options.Comments {
+		c.Comment("// NewValidatorMiddleware returns an Echo middleware that validates").Line()
+		c.Comment("// the requests against the embedded specification before they reach the handlers.").Line()
+		c.Comment("// ").Line()
+		c.Comment("// Requests to unknown routes are rejected with 404, invalid requests with 400.").Line()
+
+		if opts.ValidateResponses {
+			c.Comment("// ").Line()
+			c.Comment("// The responses of the handlers are buffered and validated as well,").Line()
+			c.Comment("// invalid responses are logged and replaced by 500.").Line()
+		}
+	}
+
+	// The specification is generated by the spec target of the general generator.
+	generalOpts, err := (&General{}).GetOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The handler is called directly unless the responses are validated.
+	callNext := `return next(c)`
+
+	if opts.ValidateResponses {
+		callNext = `res := c.Response()
+				writer := res.Writer
+
+				rec := &validatorResponseRecorder{
+					header: writer.Header(),
+				}
+
+				res.Writer = rec
+				err = next(c)
+				res.Writer = writer
+
+				// Handlers can return an error after writing a response,
+				// the buffered response is written before it is returned.
+				if !res.Committed {
+					return err
+				}
+
+				validationErr := {{ .ValidateResponse }}(req.Context(), &{{ .ResponseValidationInput }}{
+					RequestValidationInput: input,
+					Status:                 res.Status,
+					Header:                 rec.header,
+					Body:                   {{ .NopCloser }}({{ .NewReader }}(rec.body.Bytes())),
+				})
+				if validationErr != nil {
+					c.Logger().Errorf("invalid response for %v %v: %v", req.Method, req.URL.Path, validationErr)
+
+					res.Committed = false
+					return {{ .NewHTTPError }}({{ .StatusInternalServerError }}, validationErr.Error())
+				}
+
+				writer.WriteHeader(res.Status)
+				if _, writeErr := writer.Write(rec.body.Bytes()); writeErr != nil && err == nil {
+					err = writeErr
+				}
+
+				return err`
+	}
+
+	c.Add(gen.MustTemplate(`func NewValidatorMiddleware() ({{ .MiddlewareFunc }}, error) {
+		swagger, err := {{ .NewSwaggerLoader }}().LoadSwaggerFromData({{ .SpecFunc }}())
+		if err != nil {
+			return nil, err
+		}
+
+		router := {{ .NewRouter }}().WithSwagger(swagger)
+
+		return func(next {{ .HandlerFunc }}) {{ .HandlerFunc }} {
+			return func(c {{ .Context }}) error {
+				req := c.Request()
+
+				route, pathParams, err := router.FindRoute(req.Method, req.URL)
+				if err != nil {
+					return {{ .NewHTTPError }}({{ .StatusNotFound }}, err.Error())
+				}
+
+				input := &{{ .RequestValidationInput }}{
+					Request:    req,
+					PathParams: pathParams,
+					Route:      route,
+				}
+
+				err = {{ .ValidateRequest }}(req.Context(), input)
+				if err != nil {
+					return {{ .NewHTTPError }}({{ .StatusBadRequest }}, err.Error())
+				}
+
+				`+callNext+`
+			}
+		}, nil
+	}`,
+		gen.Values{
+			"MiddlewareFunc":         jen.Qual(echoPath, "MiddlewareFunc"),
+			"HandlerFunc":            jen.Qual(echoPath, "HandlerFunc"),
+			"Context":                jen.Qual(echoPath, "Context"),
+			"NewHTTPError":           jen.Qual(echoPath, "NewHTTPError"),
+			"NewSwaggerLoader":       jen.Qual("github.com/getkin/kin-openapi/openapi3", "NewSwaggerLoader"),
+			"NewRouter":              jen.Qual("github.com/getkin/kin-openapi/openapi3filter", "NewRouter"),
+			"ValidateRequest":        jen.Qual("github.com/getkin/kin-openapi/openapi3filter", "ValidateRequest"),
+			"RequestValidationInput": jen.Qual("github.com/getkin/kin-openapi/openapi3filter", "RequestValidationInput"),
+			"StatusNotFound":         jen.Qual("net/http", "StatusNotFound"),
+			"StatusBadRequest":       jen.Qual("net/http", "StatusBadRequest"),
+			"SpecFunc":               jen.Id(generalOpts.SpecFuncName),
+
+			"ValidateResponse":          jen.Qual("github.com/getkin/kin-openapi/openapi3filter", "ValidateResponse"),
+			"ResponseValidationInput":   jen.Qual("github.com/getkin/kin-openapi/openapi3filter", "ResponseValidationInput"),
+			"NopCloser":                 jen.Qual("io/ioutil", "NopCloser"),
+			"NewReader":                 jen.Qual("bytes", "NewReader"),
+			"StatusInternalServerError": jen.Qual("net/http", "StatusInternalServerError"),
+		},
+	)).Line().Line()
+
+	if opts.ValidateResponses {
+		if options.Comments {
+			c.Comment("// validatorResponseRecorder buffers the responses").Line()
+			c.Comment("// so that they can be validated before they are written.").Line()
+		}
+
+		c.Add(gen.MustTemplate(`type validatorResponseRecorder struct {
+			header {{ .Header }}
+			body   {{ .Buffer }}
+		}
+
+		func (r *validatorResponseRecorder) Header() {{ .Header }} {
+			return r.header
+		}
+
+		func (r *validatorResponseRecorder) WriteHeader(status int) {}
+
+		func (r *validatorResponseRecorder) Write(b []byte) (int, error) {
+			return r.body.Write(b)
+		}
+
+		// Flush implements http.Flusher for Echo's Response.Flush,
+		// the responses are only written after they are validated.
+		func (r *validatorResponseRecorder) Flush() {}`,
+			gen.Values{
+				"Header": jen.Qual("net/http", "Header"),
+				"Buffer": jen.Qual("bytes", "Buffer"),
+			},
+		)).Line().Line()
+	}
+
+	return c, nil
+}
+
 func (e *Echo) GenerateScaffold(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
 	// Pretty similar to the server
 	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
@@ -306,52 +667,19 @@ func (e *Echo) GenerateScaffold(ctx context.Context, sp *spec.Spec, opts *EchoOp
 	if options.Comments {
 		scaffoldCode.Commentf("// Make sure that we implement the correct server.").Line()
 	}
-	scaffoldCode.Var().Id("_").Add(gen.Qual(opts.ServerPackagePath, opts.ServerName)).Op("=&").Id(opts.ServerImplName).Block().Line().Line()
+	scaffoldCode.Add(gen.AssertImplements(
+		gen.Qual(opts.ServerPackagePath, opts.ServerName),
+		jen.Op("&").Id(opts.ServerImplName).Values(),
+	)).Line().Line()
 
 	for _, p := range sp.Paths {
 		for _, o := range p.Operations {
-			params := make([]jen.Code, 0, len(o.Parameters)+1)
-			returns := make([]jen.Code, 0, 2)
-			params = append(params, jen.Id("c").Qual(echoPath, "Context"))
-
-			g := &General{}
-			generalOpts, err := g.GetOpts(ctx)
+			params, err := e.implParams(ctx, o, opts)
 			if err != nil {
 				return nil, err
 			}
 
-			generalOpts.TypesPackagePath = opts.TypesPackagePath
-			for _, param := range o.Parameters {
-				// We skip parameters that aren't supported.
-				if !e.isParameterContentTypeSupported(param.ContentType) {
-					continue
-				}
-
-				if param.Schema == nil {
-					continue
-				}
-
-				paramCode := jen.Id(util.ToGoName(strcase.ToLowerCamel(param.Name)))
-
-				if param.IsPtr() {
-					paramCode.Op("*")
-				}
-
-				if param.Schema.Name != "" {
-					paramCode.Add(gen.Qual(opts.ServerPackagePath, param.Schema.Name))
-				} else {
-					c, err := g.GenerateType(ctx, param.Schema, generalOpts)
-					if err != nil {
-						return nil, err
-					}
-
-					paramCode.Add(c)
-				}
-
-				params = append(params, paramCode)
-			}
-
-			returns = append(returns, gen.Qual(opts.ServerPackagePath, o.Name+opts.ResponsePostfix), jen.Error())
+			returns := []jen.Code{gen.Qual(opts.ServerPackagePath, o.Name+opts.ResponsePostfix), jen.Error()}
 
 			if options.Comments {
 				if opts.ShortScaffoldComments {
@@ -389,177 +717,1424 @@ func (e *Echo) GenerateScaffold(ctx context.Context, sp *spec.Spec, opts *EchoOp
 	return scaffoldCode, nil
 }
 
-// Checks whether the parameter content-type is supported, and should be handled.
-func (e *Echo) isParameterContentTypeSupported(contentType string) bool {
-	ct := strings.TrimSpace(strings.ToLower(contentType))
-
-	if ct == "" {
-		return true
+// GenerateFake generates an in-memory fake implementation of the
+// server interface that is meant to be used in tests.
+//
+// The items of the resources are kept in maps, and the operations
+// are matched by their names, operations starting with Add or Create
+// store, operations starting with Find, Get or List return, and operations
+// starting with Delete or Remove delete items. All the other operations
+// respond with 501 Not Implemented.
+func (e *Echo) GenerateFake(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
 	}
 
-	mimeTypes := []string{
-		echo.MIMEApplicationJSON,
-		echo.MIMEApplicationXML,
-		echo.MIMEApplicationForm,
-	}
+	fakeName := opts.ServerName + "Fake"
+	typesPath := implTypesPath(opts)
+	sharedNames := sharedEmptyResponseNames(sp)
 
-	for _, mt := range mimeTypes {
-		if strings.HasPrefix(ct, mt) {
-			return true
+	fakeOps := e.fakeOperations(sp)
+
+	// The resources with items stored in the fake, in alphabetical order.
+	resources := make(map[string]*spec.Schema)
+	resourceNames := make([]string, 0)
+
+	for _, fo := range fakeOps {
+		if fo == nil {
+			continue
+		}
+
+		if _, ok := resources[fo.resource.Name]; !ok {
+			resourceNames = append(resourceNames, fo.resource.Name)
 		}
+		resources[fo.resource.Name] = fo.resource
 	}
 
-	return false
-}
+	sort.Strings(resourceNames)
 
-// GenerateWrapper generates wrapper for an Echo instance
-// and the server interface.
-func (e *Echo) GenerateWrapper(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
-	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
-	if !ok {
-		options = common.DefaultOptions()
+	fakeCode := jen.Null()
+
+	fields := []jen.Code{
+		jen.Id("mu").Qual("sync", "Mutex"),
+		jen.Id("nextID").Int64(),
 	}
+	fieldValues := jen.Dict{}
 
-	c := jen.Null()
+	for _, name := range resourceNames {
+		fields = append(fields, jen.Id(fakeItemsField(name)).Map(jen.String()).Add(gen.Qual(typesPath, name)))
+		fieldValues[jen.Id(fakeItemsField(name))] = jen.Map(jen.String()).Add(gen.Qual(typesPath, name)).Values()
+	}
 
 	if options.Comments {
-		c.Comment("// EchoInstance is required to add handlers to").Line()
-		c.Comment("// to both Echo instances and Echo groups.").Line()
+		fakeCode.Commentf("// %v is an in-memory implementation of %v for tests,", fakeName, opts.ServerName).Line()
+		fakeCode.Comment("// the items are stored in maps keyed by their generated IDs.").Line()
 	}
+	fakeCode.Type().Id(fakeName).Struct(fields...).Line().Line()
 
-	// EchoInstance interface
-	c.Type().Id("EchoInstance").Interface(
-		jen.Id("Add").Params(
-			jen.String(),
-			jen.String(),
-			jen.Qual(echoPath, "HandlerFunc"),
-			jen.Op("...").Qual(echoPath, "MiddlewareFunc"),
-		).Params(jen.Op("*").Qual(echoPath, "Route")),
+	if options.Comments {
+		fakeCode.Commentf("// New%v returns a new %v without any items.", fakeName, fakeName).Line()
+	}
+	fakeCode.Func().Id("New" + fakeName).Params().Op("*").Id(fakeName).Block(
+		jen.Return(jen.Op("&").Id(fakeName).Values(fieldValues)),
 	).Line().Line()
 
-	funcHeader := jen.Null()
-
 	if options.Comments {
-		funcHeader.Commentf("// RegisterEchoServer registers a %v with an Echo instance,", opts.ServerName).Line()
-		funcHeader.Commentf("// and wraps %v's handlers with Echo handlers.", opts.ServerName).Line()
-		funcHeader.Comment("// Depending on the options, parameter parsing").Line()
-		funcHeader.Comment("// and response encoding are done in the wrapper.").Line()
-		funcHeader.Comment("// ").Line()
-		funcHeader.Comment("// Note that the parameters and responses are NOT validated by the wrapper.").Line()
-		funcHeader.Comment("// The value of an invalid parameter will be the default Go value.").Line()
-		funcHeader.Comment("// If you need to do validation, do it in a middleware,").Line()
-		funcHeader.Commentf("// or in %v's methods.", opts.ServerName).Line()
-
+		fakeCode.Commentf("// Make sure that we implement the correct server.").Line()
 	}
+	fakeCode.Add(gen.AssertImplements(
+		gen.Qual(opts.ServerPackagePath, opts.ServerName),
+		jen.Op("&").Id(fakeName).Values(),
+	)).Line().Line()
 
-	funcHeader.Func().Id("RegisterEchoServer").Params(
-		jen.Id("e").Id("EchoInstance"),
-		jen.Id("server").Id(opts.ServerName),
-	)
+	i := 0
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			fo := fakeOps[i]
+			i++
 
-	funcBody := make([]jen.Code, 0)
+			params, err := e.implParams(ctx, o, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			returns := []jen.Code{gen.Qual(opts.ServerPackagePath, o.Name+opts.ResponsePostfix), jen.Error()}
+
+			var body []jen.Code
+			if fo == nil {
+				body = []jen.Code{
+					jen.Return(jen.Nil(), jen.Qual(echoPath, "NewHTTPError").Call(jen.Qual("net/http", "StatusNotImplemented"))),
+				}
+			} else {
+				body = e.generateFakeBody(ctx, o, fo, sharedNames, opts)
+			}
+
+			if options.Comments {
+				fakeCode.Add(gen.Comments(o.Comments[0]))
+			}
+			fakeCode.Func().Params(jen.Id("f").Op("*").Id(fakeName)).
+				Id(strcase.ToCamel(o.Name)).
+				Params(params...).
+				Params(returns...).
+				Block(body...).Line().Line()
+		}
+	}
 
-	// If we have middleware declared, we need to
-	// handle them.
 	if opts.ServerMiddleware {
-		funcBody = append(funcBody,
-			jen.Id("middleware").Op(":=").Id("server").Dot("Middleware").Call().Line(),
-			jen.If(jen.Id("middleware").Op("==").Nil()).Block(
-				jen.Id("middleware").Op("=").New(jen.Id(opts.ServerName+"Middleware")),
-			).Line().Line(),
-		)
+		if options.Comments {
+			fakeCode.Commentf("// Middleware implements %v, the fake has no middleware.", opts.ServerName).Line()
+		}
+
+		fakeCode.Func().Params(jen.Id("f").Op("*").Id(fakeName)).
+			Id("Middleware").Params().Params(jen.Op("*").Add(gen.Qual(opts.ServerPackagePath, opts.ServerName+"Middleware"))).
+			Block(
+				jen.Return(jen.Op("&").Add(gen.Qual(opts.ServerPackagePath, opts.ServerName+"Middleware")).Values()),
+			).Line().Line()
 	}
 
-	for _, p := range sp.Paths {
-		// the parameters are expected like :param
-		pathStr := util.ParamStyleToColon(p.PathString)
+	return fakeCode, nil
+}
 
-		// create and register a handler for each operation
-		for _, o := range p.Operations {
-			handler := jen.Func().Params(jen.Id("c").Qual(echoPath, "Context")).Params(jen.Error())
+// generateFakeBody generates the body of the method
+// of an operation supported by the fake server.
+func (e *Echo) generateFakeBody(ctx context.Context, o *spec.Operation, fo *fakeOperation, sharedNames map[string]bool, opts *EchoOptions) []jen.Code {
+	typesPath := implTypesPath(opts)
+	items := func() *jen.Statement {
+		return jen.Id("f").Dot(fakeItemsField(fo.resource.Name))
+	}
+	itemID := func() *jen.Statement {
+		return jen.Id("c").Dot("Param").Call(jen.Lit(fo.idParam.Name))
+	}
 
-			paramNames := make([]jen.Code, 0, 1+len(o.Parameters))
+	notFound := jen.Return(jen.Nil(), jen.Qual(echoPath, "NewHTTPError").Call(jen.Qual("net/http", "StatusNotFound")))
+
+	// The value returned on success, either the item or the empty response.
+	result := func(item string) jen.Code {
+		switch {
+		case fo.response.Schema == nil || isEmptyStatus(fo.response.Code):
+			return gen.Qual(opts.ServerPackagePath, emptyResponseName(o, fo.response, sharedNames, opts))
+		case fo.response.IsPtr():
+			return jen.Op("&").Id(item)
+		default:
+			return jen.Id(item)
+		}
+	}
 
-			// The first parameter that is passed is always the echo context
-			paramNames = append(paramNames, jen.Id("c"))
+	body := []jen.Code{
+		jen.Id("f").Dot("mu").Dot("Lock").Call(),
+		jen.Defer().Id("f").Dot("mu").Dot("Unlock").Call(),
+		jen.Line(),
+	}
 
-			// The body of the wrapper handler func before the
-			// wrapped handler is called.
-			beforeStatements := make([]jen.Code, 0, len(o.Parameters))
+	switch fo.kind {
+	case fakeCreate:
+		body = append(body, jen.Var().Id("_item").Add(gen.Qual(typesPath, fo.resource.Name)))
 
-			for _, param := range o.Parameters {
-				// We skip parameters that aren't supported.
-				if !e.isParameterContentTypeSupported(param.ContentType) {
-					continue
+		if fo.body != nil {
+			bodyValue := func() *jen.Statement {
+				if opts.OperationContext {
+					return jen.Id("c").Dot(operationContextField(ctx, fo.body))
 				}
+				return jen.Id(common.Initialisms(ctx).ToGoName(strcase.ToLowerCamel(fo.body.Name)))
+			}
 
-				paramC := jen.Null()
-
-				c, err := e.generateExtractParam(ctx, param, opts)
+			switch {
+			case fo.body.Schema.Name == fo.resource.Name && fo.body.IsPtr():
+				body = append(body, jen.If(bodyValue().Op("!=").Nil()).Block(
+					jen.Id("_item").Op("=").Op("*").Add(bodyValue()),
+				))
+			case fo.body.Schema.Name == fo.resource.Name:
+				body = append(body, jen.Id("_item").Op("=").Add(bodyValue()))
+			default:
+				// The body is converted to the item by its JSON representation.
+				body = append(body,
+					jen.List(jen.Id("_data"), jen.Err()).Op(":=").Add((&General{}).jsonCall("Marshal")).Call(bodyValue()),
+					jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+					jen.If(
+						jen.Err().Op(":=").Add((&General{}).jsonCall("Unmarshal")).Call(jen.Id("_data"), jen.Op("&").Id("_item")),
+						jen.Err().Op("!=").Nil(),
+					).Block(jen.Return(jen.Nil(), jen.Err())),
+				)
+			}
+		}
+
+		body = append(body,
+			jen.Line(),
+			jen.Id("f").Dot("nextID").Op("++"),
+			jen.Id("_id").Op(":=").Qual("strconv", "FormatInt").Call(jen.Id("f").Dot("nextID"), jen.Lit(10)),
+		)
+
+		body = append(body, fakeAssignID(fo.resource, typesPath)...)
+
+		body = append(body,
+			items().Index(jen.Id("_id")).Op("=").Id("_item"),
+			jen.Line(),
+			jen.Return(result("_item"), jen.Nil()),
+		)
+	case fakeFind:
+		body = append(body,
+			jen.List(jen.Id("_item"), jen.Id("ok")).Op(":=").Add(items()).Index(itemID()),
+			jen.If(jen.Op("!").Id("ok")).Block(notFound),
+			jen.Line(),
+			jen.Return(result("_item"), jen.Nil()),
+		)
+	case fakeList:
+		itemSchema := fo.response.Schema.Children.Schema
+
+		appendItem := jen.Id("_items").Op("=").Append(jen.Id("_items"), items().Index(jen.Id("_k")))
+		if (itemSchema.Nullable || itemSchema.ShouldBePtr()) && !itemSchema.CanBeNil() {
+			appendItem = jen.Id("_item").Op(":=").Add(items()).Index(jen.Id("_k")).Line().
+				Id("_items").Op("=").Append(jen.Id("_items"), jen.Op("&").Id("_item"))
+		}
+
+		body = append(body,
+			jen.Id("_keys").Op(":=").Make(jen.Index().String(), jen.Lit(0), jen.Len(items())),
+			jen.For(jen.Id("_k").Op(":=").Range().Add(items())).Block(
+				jen.Id("_keys").Op("=").Append(jen.Id("_keys"), jen.Id("_k")),
+			),
+			jen.Line(),
+			jen.Comment("// The items are returned in the order they were added."),
+			jen.Qual("sort", "Slice").Call(jen.Id("_keys"), jen.Func().Params(jen.List(jen.Id("i"), jen.Id("j")).Int()).Bool().Block(
+				jen.If(jen.Len(jen.Id("_keys").Index(jen.Id("i"))).Op("!=").Len(jen.Id("_keys").Index(jen.Id("j")))).Block(
+					jen.Return(jen.Len(jen.Id("_keys").Index(jen.Id("i"))).Op("<").Len(jen.Id("_keys").Index(jen.Id("j")))),
+				),
+				jen.Return(jen.Id("_keys").Index(jen.Id("i")).Op("<").Id("_keys").Index(jen.Id("j"))),
+			)),
+			jen.Line(),
+			jen.Id("_items").Op(":=").Make(gen.Qual(implTypesPath(opts), fo.response.Schema.Name), jen.Lit(0), jen.Len(jen.Id("_keys"))),
+			jen.For(jen.List(jen.Id("_"), jen.Id("_k")).Op(":=").Range().Id("_keys")).Block(appendItem),
+			jen.Line(),
+			jen.Return(result("_items"), jen.Nil()),
+		)
+	case fakeDelete:
+		item := jen.Id("_item")
+		if fo.response.Schema == nil || isEmptyStatus(fo.response.Code) {
+			item = jen.Id("_")
+		}
+
+		body = append(body,
+			jen.List(item, jen.Id("ok")).Op(":=").Add(items()).Index(itemID()),
+			jen.If(jen.Op("!").Id("ok")).Block(notFound),
+			jen.Line(),
+			jen.Delete(items(), itemID()),
+			jen.Line(),
+			jen.Return(result("_item"), jen.Nil()),
+		)
+	}
+
+	return body
+}
+
+// fakeAssignID returns the statements that set the ID field
+// of a newly created item to the generated ID, if it has one.
+func fakeAssignID(resource *spec.Schema, typesPath string) []jen.Code {
+	if resource.Children == nil || resource.Children.Map == nil {
+		return nil
+	}
+
+	field := resource.Children.Map["ID"]
+	if field == nil || field.Variant != spec.VariantPrimitive {
+		return nil
+	}
+
+	var value *jen.Statement
+
+	switch field.PrimitiveType {
+	case "string":
+		value = jen.Id("_id")
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		value = jen.Id(field.PrimitiveType).Call(jen.Id("f").Dot("nextID"))
+	default:
+		return nil
+	}
+
+	if field.Name != "" {
+		value = gen.Qual(typesPath, field.Name).Call(value)
+	}
+
+	if (field.Nullable || field.ShouldBePtr()) && !field.CanBeNil() {
+		return []jen.Code{
+			jen.Id("_itemID").Op(":=").Add(value),
+			jen.Id("_item").Dot("ID").Op("=").Op("&").Id("_itemID"),
+		}
+	}
+
+	return []jen.Code{jen.Id("_item").Dot("ID").Op("=").Add(value)}
+}
+
+// fakeItemsField returns the name of the field of the
+// fake server that stores the items of a resource.
+func fakeItemsField(resourceName string) string {
+	return strcase.ToLowerCamel(resourceName) + "Items"
+}
+
+// fakeOperationKind describes what an operation does in the fake server.
+type fakeOperationKind int
+
+const (
+	fakeCreate fakeOperationKind = iota
+	fakeFind
+	fakeList
+	fakeDelete
+)
+
+// fakeOperation is an operation supported by the fake server.
+type fakeOperation struct {
+	kind fakeOperationKind
+
+	// The schema of the items the operation works with.
+	resource *spec.Schema
+
+	// The path parameter that identifies the item, if any.
+	idParam *spec.Parameter
+
+	// The body of the request, if any.
+	body *spec.Parameter
+
+	// The successful response that is returned.
+	response *spec.Response
+}
+
+// fakeOperationPrefixes are the prefixes of the operation names
+// that are recognized by the fake server, and what the operations do.
+var fakeOperationPrefixes = []struct {
+	prefix string
+	kind   fakeOperationKind
+}{
+	{"Add", fakeCreate},
+	{"Create", fakeCreate},
+	{"Find", fakeFind},
+	{"Get", fakeFind},
+	{"List", fakeFind},
+	{"Delete", fakeDelete},
+	{"Remove", fakeDelete},
+}
+
+// fakeOperations returns the operations of the spec that
+// the fake server supports in the order of the paths and
+// operations, the unsupported operations are nil.
+//
+// The items of the operations are determined by the schemas the
+// create and find operations return or accept, operations of the
+// same collection path (e.g. /pets and /pets/{id}) share the items.
+func (e *Echo) fakeOperations(sp *spec.Spec) []*fakeOperation {
+	type candidate struct {
+		fakeOperation
+		collection string
+		ok         bool
+	}
+
+	candidates := make([]*candidate, 0)
+	collections := make(map[string]*spec.Schema)
+
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			c := &candidate{collection: p.PathString}
+			candidates = append(candidates, c)
+
+			for _, pr := range fakeOperationPrefixes {
+				rest := strings.TrimPrefix(o.Name, pr.prefix)
+				if len(rest) == len(o.Name) {
+					continue
+				}
+
+				if r, _ := utf8.DecodeRuneInString(rest); rest == "" || unicode.IsUpper(r) {
+					c.kind = pr.kind
+					c.ok = true
+					break
+				}
+			}
+
+			if !c.ok {
+				continue
+			}
+
+			for _, param := range o.Parameters {
+				switch {
+				case param.Type == spec.ParameterTypePath && c.idParam == nil:
+					c.idParam = param
+				case param.Type == spec.ParameterTypePath:
+					// Nested resources are not supported.
+					c.ok = false
+				case param.Type == spec.ParameterTypeBody && c.body == nil &&
+					param.Schema != nil && e.isParameterContentTypeSupported(param.ContentType):
+					c.body = param
+				}
+			}
+
+			c.response = fakeSuccessResponse(o)
+
+			if !c.ok || c.response == nil {
+				c.ok = false
+				continue
+			}
+
+			if c.idParam != nil {
+				c.collection = strings.TrimSuffix(c.collection, "/{"+c.idParam.Name+"}")
+			}
+
+			if c.kind == fakeFind && c.idParam == nil {
+				c.kind = fakeList
+			}
+
+			var resource *spec.Schema
+
+			res := c.response.Schema
+			hasBody := res != nil && !isEmptyStatus(c.response.Code)
+
+			switch {
+			case c.kind == fakeList && hasBody && res.Variant == spec.VariantArray &&
+				res.Children != nil && res.Children.Schema != nil:
+				resource = res.Children.Schema
+			case (c.kind == fakeCreate || c.kind == fakeFind) && hasBody:
+				resource = res
+			case c.kind == fakeCreate && c.body != nil:
+				resource = c.body.Schema
+			}
+
+			if resource != nil && resource.Name != "" && resource.Variant == spec.VariantStruct {
+				if _, ok := collections[c.collection]; !ok {
+					collections[c.collection] = resource
+				}
+			}
+		}
+	}
+
+	fakeOps := make([]*fakeOperation, len(candidates))
+
+	for i, c := range candidates {
+		if !c.ok {
+			continue
+		}
+
+		c.resource = collections[c.collection]
+		if c.resource == nil {
+			continue
+		}
+
+		res := c.response.Schema
+		hasBody := res != nil && !isEmptyStatus(c.response.Code)
+
+		switch c.kind {
+		case fakeCreate, fakeDelete:
+			if hasBody && res.Name != c.resource.Name {
+				continue
+			}
+		case fakeFind:
+			if !hasBody || res.Name != c.resource.Name {
+				continue
+			}
+		case fakeList:
+			if !hasBody || res.Name == "" || res.Variant != spec.VariantArray ||
+				res.Children == nil || res.Children.Schema == nil ||
+				res.Children.Schema.Name != c.resource.Name {
+				continue
+			}
+		}
+
+		if c.kind == fakeDelete && c.idParam == nil {
+			continue
+		}
+
+		fo := c.fakeOperation
+		fakeOps[i] = &fo
+	}
+
+	return fakeOps
+}
+
+// fakeSuccessResponse returns the successful response with the lowest
+// status code that can be returned by the fake server, if any.
+func fakeSuccessResponse(o *spec.Operation) *spec.Response {
+	var success *spec.Response
+
+	for _, res := range o.Responses {
+		code := strings.ToLower(strings.TrimSpace(res.Code))
+
+		if len(code) != 3 || code[0] != '2' || len(res.Headers) > 0 || unsupportedResponse(res) != "" {
+			continue
+		}
+
+		if success == nil || code < strings.TrimSpace(success.Code) {
+			success = res
+		}
+	}
+
+	return success
+}
+
+// implTypesPath returns the import path of the generated types for
+// implementations of the server interface, such as the scaffold.
+//
+// It is the types package like in the server interface,
+// or the server package if the types package is not set.
+func implTypesPath(opts *EchoOptions) string {
+	if opts.TypesPackagePath != "" {
+		return opts.TypesPackagePath
+	}
+
+	return opts.ServerPackagePath
+}
+
+// GenerateRouteTest generates a test that registers the server
+// on an Echo instance, and checks that a request for every operation
+// is routed to a handler (it is not answered with 404 or 405).
+//
+// The handlers of the server are not implemented, the panics
+// caused by calling them are recovered by a middleware.
+func (e *Echo) GenerateRouteTest(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	serverName := strcase.ToLowerCamel(opts.ServerName) + "RouteTest"
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v implements %v without implementing any", serverName, opts.ServerName).Line()
+		code.Comment("// of the handlers, calling them panics.").Line()
+	}
+	code.Type().Id(serverName).Struct(gen.Qual(opts.ServerPackagePath, opts.ServerName)).Line().Line()
+
+	if opts.ServerMiddleware {
+		code.Func().Params(jen.Id("s").Op("*").Id(serverName)).
+			Id("Middleware").Params().Params(jen.Op("*").Add(gen.Qual(opts.ServerPackagePath, opts.ServerName+"Middleware"))).
+			Block(jen.Return(jen.Nil())).Line().Line()
+	}
+
+	basePath := sp.BasePath
+	if opts.IgnoreBasePath {
+		basePath = ""
+	}
+
+	routes := make([]jen.Code, 0)
+
+	for _, p := range sp.Paths {
+		// Any value is routed for the path parameters.
+		path := routeTestParamRe.ReplaceAllString(basePath+p.PathString, "1")
+
+		for _, o := range p.Operations {
+			routes = append(routes, jen.Line().Values(jen.Lit(strings.ToUpper(o.Method)), jen.Lit(path)))
+		}
+	}
+
+	registerArgs := []jen.Code{jen.Id("e"), jen.Op("&").Id(serverName).Values()}
+	if opts.GenerateObserver {
+		registerArgs = append(registerArgs, jen.Nil())
+	}
+
+	if options.Comments {
+		code.Commentf("// Test%vRoutes checks that all the operations of the specification are registered.", opts.ServerName).Line()
+	}
+	code.Func().Id("Test"+opts.ServerName+"Routes").Params(jen.Id("t").Op("*").Qual("testing", "T")).Block(
+		jen.Id("e").Op(":=").Qual(echoPath, "New").Call(),
+		jen.Id("e").Dot("Use").Call(jen.Func().Params(jen.Id("next").Qual(echoPath, "HandlerFunc")).Qual(echoPath, "HandlerFunc").Block(
+			jen.Return(jen.Func().Params(jen.Id("c").Qual(echoPath, "Context")).Params(jen.Err().Error()).Block(
+				jen.Defer().Func().Params().Block(
+					jen.If(jen.Id("r").Op(":=").Recover(), jen.Id("r").Op("!=").Nil()).Block(
+						jen.Err().Op("=").Qual(echoPath, "NewHTTPError").Call(jen.Qual("net/http", "StatusInternalServerError")),
+					),
+				).Call(),
+				jen.Line(),
+				jen.Return(jen.Id("next").Call(jen.Id("c"))),
+			)),
+		)),
+		jen.Line(),
+		gen.Qual(opts.ServerPackagePath, "RegisterEchoServer").Call(registerArgs...),
+		jen.Line(),
+		jen.Id("routes").Op(":=").Index().Struct(jen.List(jen.Id("method"), jen.Id("path")).String()).Values(
+			append(routes, jen.Line())...,
+		),
+		jen.Line(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("route")).Op(":=").Range().Id("routes")).Block(
+			jen.Id("rec").Op(":=").Qual("net/http/httptest", "NewRecorder").Call(),
+			jen.Id("e").Dot("ServeHTTP").Call(
+				jen.Id("rec"),
+				jen.Qual("net/http/httptest", "NewRequest").Call(jen.Id("route").Dot("method"), jen.Id("route").Dot("path"), jen.Nil()),
+			),
+			jen.Line(),
+			jen.If(
+				jen.Id("rec").Dot("Code").Op("==").Qual("net/http", "StatusNotFound").Op("||").
+					Id("rec").Dot("Code").Op("==").Qual("net/http", "StatusMethodNotAllowed"),
+			).Block(
+				jen.Id("t").Dot("Errorf").Call(jen.Lit("%v %v is not registered, status: %v"),
+					jen.Id("route").Dot("method"), jen.Id("route").Dot("path"), jen.Id("rec").Dot("Code")),
+			),
+		),
+	).Line().Line()
+
+	return code, nil
+}
+
+// routeTestParamRe matches the path parameters in paths of the specification.
+var routeTestParamRe = regexp.MustCompile(`\{[^}]+\}`)
+
+// implParams returns the parameters of the method of an operation
+// in an implementation of the server interface, such as the scaffold.
+func (e *Echo) implParams(ctx context.Context, o *spec.Operation, opts *EchoOptions) ([]jen.Code, error) {
+	if opts.OperationContext {
+		return []jen.Code{jen.Id("c").Op("*").Add(gen.Qual(opts.ServerPackagePath, o.Name+"Context"))}, nil
+	}
+
+	params := make([]jen.Code, 0, len(o.Parameters)+1)
+	params = append(params, jen.Id("c").Qual(echoPath, "Context"))
+
+	g := &General{}
+	generalOpts, err := g.GetOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generalOpts.TypesPackagePath = opts.TypesPackagePath
+	for _, param := range o.Parameters {
+		// We skip parameters that aren't supported.
+		if !e.isParameterContentTypeSupported(param.ContentType) {
+			continue
+		}
+
+		if param.Schema == nil || paramInContext(param, opts) {
+			continue
+		}
+
+		paramCode := jen.Id(common.Initialisms(ctx).ToGoName(strcase.ToLowerCamel(param.Name)))
+
+		if param.IsPtr() {
+			paramCode.Op("*")
+		}
+
+		if param.Schema.Name != "" {
+			paramCode.Add(gen.Qual(implTypesPath(opts), param.Schema.Name))
+		} else {
+			c, err := g.GenerateType(ctx, param.Schema, generalOpts)
+			if err != nil {
+				return nil, err
+			}
+
+			paramCode.Add(c)
+		}
+
+		params = append(params, paramCode)
+	}
+
+	return params, nil
+}
+
+// Checks whether the parameter content-type is supported, and should be handled.
+func (e *Echo) isParameterContentTypeSupported(contentType string) bool {
+	ct := strings.TrimSpace(strings.ToLower(contentType))
+
+	if ct == "" {
+		return true
+	}
+
+	mimeTypes := []string{
+		echo.MIMEApplicationJSON,
+		echo.MIMEApplicationXML,
+		echo.MIMEApplicationForm,
+	}
+
+	for _, mt := range mimeTypes {
+		if strings.HasPrefix(ct, mt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateWrapper generates wrapper for an Echo instance
+// and the server interface.
+func (e *Echo) GenerateWrapper(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	c := jen.Null()
+
+	if options.Comments {
+		c.Comment("// EchoInstance is required to add handlers to").Line()
+		c.Comment("// to both Echo instances and Echo groups.").Line()
+	}
+
+	// EchoInstance interface
+	c.Type().Id("EchoInstance").Interface(
+		jen.Id("Add").Params(
+			jen.String(),
+			jen.String(),
+			jen.Qual(echoPath, "HandlerFunc"),
+			jen.Op("...").Qual(echoPath, "MiddlewareFunc"),
+		).Params(jen.Op("*").Qual(echoPath, "Route")),
+	).Line().Line()
+
+	registerName := "RegisterEchoServer"
+	if opts.registerName != "" {
+		registerName = opts.registerName
+	}
+
+	funcHeader := jen.Null()
+
+	if options.Comments {
+		funcHeader.Commentf("// %v registers a %v with an Echo instance,", registerName, opts.ServerName).Line()
+		funcHeader.Commentf("// and wraps %v's handlers with Echo handlers.", opts.ServerName).Line()
+		funcHeader.Comment("// Depending on the options, parameter parsing").Line()
+		funcHeader.Comment("// and response encoding are done in the wrapper.").Line()
+		funcHeader.Comment("// ").Line()
+		funcHeader.Comment("// Note that the parameters and responses are NOT validated by the wrapper.").Line()
+		funcHeader.Comment("// The value of an invalid parameter will be the default Go value.").Line()
+		funcHeader.Comment("// If you need to do validation, do it in a middleware,").Line()
+		funcHeader.Commentf("// or in %v's methods.", opts.ServerName).Line()
+
+		if opts.GenerateObserver {
+			funcHeader.Comment("// ").Line()
+			funcHeader.Comment("// If observer is not nil, it is notified after each operation.").Line()
+		}
+
+	}
+
+	registerParams := []jen.Code{
+		jen.Id("e").Id("EchoInstance"),
+		jen.Id("server").Id(opts.ServerName),
+	}
+
+	if opts.GenerateObserver {
+		if options.Comments {
+			c.Comment("// Observer is notified after each operation handled by the server").Line()
+			c.Comment("// with the name of the operation, the response status and the duration of the handler.").Line()
+		}
+
+		c.Type().Id("Observer").Interface(
+			jen.Id("ObserveOperation").Params(
+				jen.Id("name").String(),
+				jen.Id("status").Int(),
+				jen.Id("dur").Qual("time", "Duration"),
+			),
+		).Line().Line()
+
+		if options.Comments {
+			c.Comment("// observeEchoHandler wraps an Echo handler, and reports it to the observer if it is not nil.").Line()
+		}
+
+		c.Add(gen.MustTemplate(`func observeEchoHandler(observer Observer, name string, handler {{ .HandlerFunc }}) {{ .HandlerFunc }} {
+			if observer == nil {
+				return handler
+			}
+
+			return func(c {{ .Context }}) error {
+				start := {{ .Now }}()
+				err := handler(c)
+
+				status := c.Response().Status
 				if err != nil {
-					return nil, err
+					status = {{ .StatusInternalServerError }}
+					if he, ok := err.(*{{ .HTTPError }}); ok {
+						status = he.Code
+					}
+				}
+
+				observer.ObserveOperation(name, status, {{ .Since }}(start))
+
+				return err
+			}
+		}`,
+			gen.Values{
+				"HandlerFunc":               jen.Qual(echoPath, "HandlerFunc"),
+				"Context":                   jen.Qual(echoPath, "Context"),
+				"HTTPError":                 jen.Qual(echoPath, "HTTPError"),
+				"Now":                       jen.Qual("time", "Now"),
+				"Since":                     jen.Qual("time", "Since"),
+				"StatusInternalServerError": jen.Qual("net/http", "StatusInternalServerError"),
+			},
+		)).Line().Line()
+
+		registerParams = append(registerParams, jen.Id("observer").Id("Observer"))
+	}
+
+	if opts.GenerateRequestID {
+		if options.Comments {
+			funcHeader.Comment("// ").Line()
+			funcHeader.Comment("// RequestIDMiddleware is added to each operation before any other middleware.").Line()
+		}
+
+		c.Add(e.generateRequestID(options, opts)).Line().Line()
+	}
+
+	funcHeader.Func().Id(registerName).Params(registerParams...)
+
+	funcBody := make([]jen.Code, 0)
+
+	// The statements that are required by the
+	// handlers before they are created.
+	handlersSetup := make([]jen.Code, 0)
+
+	if opts.ServerErrorHandler {
+		errorHandlerName := opts.ServerName + "ErrorHandler"
+
+		if options.Comments {
+			c.Commentf("// %v can be implemented by a %v to handle", errorHandlerName, opts.ServerName).Line()
+			c.Comment("// the errors returned by its handlers, the returned error").Line()
+			c.Comment("// is passed to Echo instead, if any.").Line()
+		}
+
+		c.Type().Id(errorHandlerName).Interface(
+			jen.Id("HandleError").Params(
+				jen.Id("c").Qual(echoPath, "Context"),
+				jen.Err().Error(),
+			).Params(jen.Error()),
+		).Line().Line()
+
+		handlersSetup = append(handlersSetup,
+			jen.List(jen.Id("errorHandler"), jen.Id("_")).Op(":=").Id("server").Assert(jen.Id(errorHandlerName)).Line(),
+		)
+	}
+
+	funcBody = append(funcBody, handlersSetup...)
+
+	// The wrapped handlers by operation name
+	// for the Handlers function.
+	handlers := jen.Dict{}
+
+	// If we have middleware declared, we need to
+	// handle them.
+	if opts.ServerMiddleware {
+		funcBody = append(funcBody,
+			jen.Id("middleware").Op(":=").Id("server").Dot("Middleware").Call().Line(),
+			jen.If(jen.Id("middleware").Op("==").Nil()).Block(
+				jen.Id("middleware").Op("=").New(jen.Id(opts.ServerName+"Middleware")),
+			).Line().Line(),
+		)
+	}
+
+	basePath := sp.BasePath
+	if opts.IgnoreBasePath {
+		basePath = ""
+	}
+
+	// The context accessors of the parameters by parameter name.
+	accessors := make(map[string][]*paramAccessor)
+
+	routes := make(map[string]bool, len(sp.Paths))
+	for _, p := range sp.Paths {
+		routes[util.ParamStyleToColon(basePath+p.PathString)] = true
+	}
+
+	for _, p := range sp.Paths {
+		// the parameters are expected like :param
+		pathStr := util.ParamStyleToColon(basePath + p.PathString)
+
+		// The same handlers are registered for the other path as well,
+		// if it is not a separate path in the specification.
+		altPathStr := ""
+		if opts.TrailingSlashInsensitive && pathStr != "/" {
+			if strings.HasSuffix(pathStr, "/") {
+				altPathStr = strings.TrimSuffix(pathStr, "/")
+			} else {
+				altPathStr = pathStr + "/"
+			}
+
+			if routes[altPathStr] {
+				altPathStr = ""
+			}
+		}
+
+		// create and register a handler for each operation
+		for _, o := range p.Operations {
+			handler := jen.Func().Params(jen.Id("c").Qual(echoPath, "Context")).Params(jen.Error())
+
+			paramNames := make([]jen.Code, 0, 1+len(o.Parameters))
+
+			// The first parameter that is passed is always the echo context
+			paramNames = append(paramNames, jen.Id("c"))
+
+			// The fields of the context struct of the operation.
+			contextFields := jen.Dict{
+				jen.Id("Context"): jen.Id("c"),
+			}
+
+			// The body of the wrapper handler func before the
+			// wrapped handler is called.
+			beforeStatements := make([]jen.Code, 0, len(o.Parameters)+1)
+
+			if opts.SetOperationContextKey != "" {
+				beforeStatements = append(beforeStatements,
+					jen.Id("c").Dot("Set").Call(jen.Lit(opts.SetOperationContextKey), jen.Lit(o.Name)).Line(),
+				)
+			}
+
+			for _, param := range o.Parameters {
+				// We skip parameters that aren't supported.
+				if !e.isParameterContentTypeSupported(param.ContentType) {
+					continue
+				}
+
+				paramC := jen.Null()
+
+				c, err := e.generateExtractParam(ctx, o, param, opts)
+				if err != nil {
+					return nil, fmt.Errorf("operation %v: %w", o.Name, err)
 				}
 				if c != nil {
-					paramC.Add(c)
-					paramNames = append(paramNames, jen.Id(param.Name))
+					if paramInContext(param, opts) {
+						// The parameter is only needed in its own block,
+						// so parameters with the same name can be stored as well.
+						paramC.Block(
+							c,
+							jen.Id("c").Dot("SetRequest").Call(
+								jen.Id("c").Dot("Request").Call().Dot("WithContext").Call(
+									jen.Qual("context", "WithValue").Call(
+										jen.Id("c").Dot("Request").Call().Dot("Context").Call(),
+										paramContextKey(param),
+										jen.Id(param.Name),
+									),
+								),
+							),
+						).Line()
+
+						if err := e.addParamAccessor(ctx, accessors, o, param, opts); err != nil {
+							return nil, fmt.Errorf("operation %v: %w", o.Name, err)
+						}
+					} else {
+						paramC.Add(c)
+						paramNames = append(paramNames, jen.Id(param.Name))
+						contextFields[jen.Id(operationContextField(ctx, param))] = jen.Id(param.Name)
+					}
 				}
 
 				beforeStatements = append(beforeStatements, paramC)
 			}
 
-			callResultVars := jen.Null()
-			callResultVars.List(jen.Id("result"), jen.Err())
+			if opts.OperationContext {
+				paramNames = []jen.Code{jen.Op("&").Id(o.Name + "Context").Values(contextFields)}
+			}
+
+			callResultVars := jen.Null()
+			callResultVars.List(jen.Id("result"), jen.Err())
+
+			handleResponse := jen.Null()
+			handleResponse.Add(gen.MustTemplate(`return result.{{ .InfName }}(c)`,
+				gen.Values{
+					"InfName": jen.Id(o.Name + opts.ResponsePostfix),
+				},
+			)).Line()
+
+			handleError := jen.Null()
+			if opts.ServerErrorHandler {
+				handleError.If(jen.Id("errorHandler").Op("!=").Nil()).Block(
+					jen.Return(jen.Id("errorHandler").Dot("HandleError").Call(jen.Id("c"), jen.Err())),
+				).Line()
+			}
+
+			handlerCall := gen.MustTemplate(`{{ .CallResultVars }} := server.{{ .Handler }}({{ .Params }})
+				if err != nil {
+					{{ .HandleError }}return err
+				}
+				{{ .HandleResponse }}`,
+				gen.Values{
+					"Handler":        jen.Id(strcase.ToCamel(o.Name)),
+					"CallResultVars": callResultVars,
+					"Params":         jen.List(paramNames...),
+					"HandleError":    handleError,
+					"HandleResponse": handleResponse,
+				},
+			)
+
+			// Then finally we can Append the body statements
+			// to the handler header
+			statements := make([]jen.Code, 0)
+
+			statements = append(statements, beforeStatements...)
+			statements = append(statements, handlerCall)
+
+			handler.Block(statements...)
+
+			handlers[jen.Lit(o.Name)] = handler
+
+			if opts.GenerateObserver {
+				handler = jen.Id("observeEchoHandler").Call(
+					jen.Id("observer"),
+					jen.Lit(o.Name),
+					handler,
+				)
+			}
+
+			// If we have middleware, add them.
+			addMws := jen.Null()
+
+			switch {
+			case opts.ServerMiddleware && opts.GenerateRequestID:
+				addMws.Append(
+					jen.Index().Qual(echoPath, "MiddlewareFunc").Values(jen.Id("RequestIDMiddleware")),
+					jen.Id("middleware").Dot(strcase.ToCamel(o.Name)).Op("..."),
+				).Op("...")
+			case opts.ServerMiddleware:
+				addMws.Id("middleware").Dot(strcase.ToCamel(o.Name)).Op("...")
+			case opts.GenerateRequestID:
+				addMws.Id("RequestIDMiddleware")
+			}
+
+			funcBody = append(funcBody, addRoute(strings.ToUpper(o.Method), pathStr, altPathStr, handler, addMws))
+		}
+
+		if opts.GenerateOptionsHandlers {
+			optionsHandler := e.generateOptionsHandler(p, opts)
+			if optionsHandler != nil {
+				funcBody = append(funcBody, addRoute(http.MethodOptions, pathStr, altPathStr, optionsHandler, jen.Null()))
+			}
+		}
+	}
+
+	c.Add(funcHeader.Block(funcBody...))
+
+	if opts.ParamsInContext {
+		c.Add(generateParamAccessors(ctx, accessors, options))
+	}
+
+	if opts.GenerateHandlers {
+		c.Line().Line()
+
+		if options.Comments {
+			c.Comment("// Handlers returns the wrapped Echo handlers of the server by operation name.").Line()
+			c.Comment("// The handlers can be invoked directly with an Echo context,").Line()
+			c.Comment("// for example in tests, route parameters must be set on the context.").Line()
+			c.Comment("// ").Line()
+			c.Comment("// Middleware and observers are not applied to the handlers.").Line()
+		}
+
+		c.Func().Id("Handlers").Params(jen.Id("server").Id(opts.ServerName)).
+			Params(jen.Map(jen.String()).Qual(echoPath, "HandlerFunc")).Block(
+			append(handlersSetup,
+				jen.Return(jen.Map(jen.String()).Qual(echoPath, "HandlerFunc").Values(handlers)),
+			)...,
+		)
+	}
+
+	return c, nil
+}
+
+// generateRequestID generates the request ID middleware,
+// and the functions that return the ID of a request.
+func (e *Echo) generateRequestID(options *common.Options, opts *EchoOptions) jen.Code {
+	c := jen.Null()
+
+	if options.Comments {
+		c.Comment("// RequestIDHeader is the header of the request IDs.").Line()
+	}
+	c.Const().Id("RequestIDHeader").Op("=").Lit(opts.RequestIDHeader).Line().Line()
 
-			handleResponse := jen.Null()
-			handleResponse.Add(gen.MustTemplate(`return result.{{ .InfName }}(c)`,
-				gen.Values{
-					"InfName": jen.Id(o.Name + opts.ResponsePostfix),
-				},
-			)).Line()
+	c.Const().Id("requestIDKey").Op("=").Lit("repose.requestID").Line().Line()
 
-			handlerCall := gen.MustTemplate(`{{ .CallResultVars }} := server.{{ .Handler }}({{ .Params }})
-				if err != nil {
+	c.Type().Id("requestIDContextKey").Struct().Line().Line()
+
+	if options.Comments {
+		c.Comment("// RequestIDMiddleware assigns an ID to each request, it is taken from the").Line()
+		c.Comment("// RequestIDHeader header of the request, or a random one is generated if it is missing.").Line()
+		c.Comment("// The ID is stored in both the Echo context and the context of the request,").Line()
+		c.Comment("// and it is set in the same header of the response.").Line()
+	}
+	c.Add(gen.MustTemplate(`func RequestIDMiddleware(next {{ .HandlerFunc }}) {{ .HandlerFunc }} {
+		return func(c {{ .Context }}) error {
+			id := c.Request().Header.Get(RequestIDHeader)
+			if id == "" {
+				b := make([]byte, 16)
+				if _, err := {{ .RandRead }}(b); err != nil {
 					return err
 				}
-				{{ .HandleResponse }}`,
-				gen.Values{
-					"Handler":        jen.Id(strcase.ToCamel(o.Name)),
-					"CallResultVars": callResultVars,
-					"Params":         jen.List(paramNames...),
-					"HandleResponse": handleResponse,
-				},
-			)
+				id = {{ .EncodeToString }}(b)
+			}
 
-			// Then finally we can Append the body statements
-			// to the handler header
-			statements := make([]jen.Code, 0)
+			c.Set(requestIDKey, id)
+			c.SetRequest(c.Request().WithContext({{ .WithValue }}(c.Request().Context(), requestIDContextKey{}, id)))
+			c.Response().Header().Set(RequestIDHeader, id)
 
-			statements = append(statements, beforeStatements...)
-			statements = append(statements, handlerCall)
+			return next(c)
+		}
+	}`,
+		gen.Values{
+			"HandlerFunc":    jen.Qual(echoPath, "HandlerFunc"),
+			"Context":        jen.Qual(echoPath, "Context"),
+			"RandRead":       jen.Qual("crypto/rand", "Read"),
+			"EncodeToString": jen.Qual("encoding/hex", "EncodeToString"),
+			"WithValue":      jen.Qual("context", "WithValue"),
+		},
+	)).Line().Line()
 
-			handler.Block(statements...)
+	if options.Comments {
+		c.Comment("// RequestID returns the ID of the request assigned by RequestIDMiddleware,").Line()
+		c.Comment("// or an empty string if there is none.").Line()
+	}
+	c.Func().Id("RequestID").Params(jen.Id("c").Qual(echoPath, "Context")).String().Block(
+		jen.List(jen.Id("id"), jen.Id("_")).Op(":=").Id("c").Dot("Get").Call(jen.Id("requestIDKey")).Assert(jen.String()),
+		jen.Return(jen.Id("id")),
+	).Line().Line()
 
-			// If we have middleware, add them.
-			addMws := jen.Null()
+	if options.Comments {
+		c.Comment("// RequestIDFromContext returns the ID of the request assigned by RequestIDMiddleware").Line()
+		c.Comment("// from the context of the request, or an empty string if there is none.").Line()
+	}
+	c.Func().Id("RequestIDFromContext").Params(jen.Id("ctx").Qual("context", "Context")).String().Block(
+		jen.List(jen.Id("id"), jen.Id("_")).Op(":=").Id("ctx").Dot("Value").Call(jen.Id("requestIDContextKey").Values()).Assert(jen.String()),
+		jen.Return(jen.Id("id")),
+	)
 
-			if opts.ServerMiddleware {
-				addMws.Id("middleware").Dot(strcase.ToCamel(o.Name)).Op("...")
+	return c
+}
+
+// paramAccessor is a parameter of an operation
+// that is stored in the Echo context by the wrapper.
+type paramAccessor struct {
+	operation *spec.Operation
+	param     *spec.Parameter
+	tp        jen.Code
+}
+
+// paramInContext returns whether the parameter is stored
+// in the Echo context instead of being passed to the handler.
+func paramInContext(param *spec.Parameter, opts *EchoOptions) bool {
+	return opts.ParamsInContext && param.Type != spec.ParameterTypeBody
+}
+
+// paramKeyTypeName is the name of the generated unexported type
+// of the request context keys of the parameters, the location is part
+// of the key so that parameters with the same name do not overwrite each other.
+const paramKeyTypeName = "paramKey"
+
+// paramContextKey returns the request context key of a parameter.
+func paramContextKey(param *spec.Parameter) jen.Code {
+	return jen.Id(paramKeyTypeName).Values(jen.Dict{
+		jen.Id("in"):   jen.Lit(string(param.Type)),
+		jen.Id("name"): jen.Lit(param.Name),
+	})
+}
+
+// addParamAccessor adds the parameter of the operation to the accessors.
+func (e *Echo) addParamAccessor(ctx context.Context, accessors map[string][]*paramAccessor, o *spec.Operation, param *spec.Parameter, opts *EchoOptions) error {
+	g := &General{}
+
+	generalOpts, err := g.GetOpts(ctx)
+	if err != nil {
+		return err
+	}
+
+	generalOpts.TypesPackagePath = opts.TypesPackagePath
+
+	tp := jen.Null()
+
+	if param.IsPtr() {
+		tp.Op("*")
+	}
+
+	if param.Schema.Name != "" {
+		tp.Add(gen.Qual(opts.TypesPackagePath, param.Schema.Name))
+	} else {
+		c, err := g.GenerateType(ctx, param.Schema, generalOpts)
+		if err != nil {
+			return err
+		}
+		tp.Add(c)
+	}
+
+	accessors[param.Name] = append(accessors[param.Name], &paramAccessor{
+		operation: o,
+		param:     param,
+		tp:        tp,
+	})
+
+	return nil
+}
+
+// generateParamAccessors generates a Get function for each parameter
+// stored in the Echo context.
+//
+// Parameters with the same name share a single accessor if they have
+// the same type and location in every operation, otherwise an accessor
+// is generated for each operation, named after the operation
+// (e.g. GetPetID for getPet and GetDeletePetID for deletePet).
+func generateParamAccessors(ctx context.Context, accessors map[string][]*paramAccessor, options *common.Options) jen.Code {
+	names := make([]string, 0, len(accessors))
+	for name := range accessors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	code := jen.Line().Line()
+
+	if options.Comments {
+		code.Comment("// paramKey is the type of the request context keys of the parameters.").Line()
+	}
+	code.Type().Id(paramKeyTypeName).Struct(
+		jen.Id("in").String(),
+		jen.Id("name").String(),
+	)
+
+	accessor := func(funcName string, a *paramAccessor) {
+		code.Line().Line()
+
+		if options.Comments {
+			code.Commentf("// %v returns the %v %v parameter stored in the request context by the wrapper,", funcName, a.param.Name, a.param.Type).Line()
+			code.Comment("// or the zero value if it is not present.").Line()
+		}
+
+		code.Func().Id(funcName).Params(jen.Id("c").Qual(echoPath, "Context")).Add(a.tp).Block(
+			jen.List(jen.Id("v"), jen.Id("_")).Op(":=").Id("c").Dot("Request").Call().Dot("Context").Call().Dot("Value").Call(paramContextKey(a.param)).Assert(a.tp),
+			jen.Return(jen.Id("v")),
+		)
+	}
+
+	for _, name := range names {
+		params := accessors[name]
+		goName := common.Initialisms(ctx).ToGoName(strcase.ToCamel(name))
+
+		shared := true
+		for _, a := range params[1:] {
+			if a.param.Type != params[0].param.Type ||
+				fmt.Sprintf("%#v", a.tp) != fmt.Sprintf("%#v", params[0].tp) {
+				shared = false
+				break
 			}
+		}
 
-			funcBody = append(funcBody,
-				jen.Id("e").Op(".").Id("Add").Call(
-					jen.Lit(strings.ToUpper(o.Method)),
-					jen.Lit(pathStr),
-					handler,
-					addMws,
-				).Line(),
-			)
+		if shared {
+			accessor("Get"+goName, params[0])
+			continue
+		}
+
+		// Parameters of an operation with the same name
+		// are told apart by their locations.
+		perOperation := make(map[*spec.Operation]int)
+		for _, a := range params {
+			perOperation[a.operation]++
+		}
+
+		for _, a := range params {
+			// Operations that are already named
+			// as getters are not prefixed again.
+			funcName := strcase.ToCamel(a.operation.Name) + goName
+			if !strings.HasPrefix(funcName, "Get") {
+				funcName = "Get" + funcName
+			}
+			if perOperation[a.operation] > 1 {
+				funcName += strcase.ToCamel(string(a.param.Type))
+			}
+
+			accessor(funcName, a)
+		}
+	}
+
+	return code
+}
+
+// addRoute returns the statement that registers the handler with Echo,
+// if altPath is not empty, the handler is registered for it as well.
+func addRoute(method, path, altPath string, handler, middleware jen.Code) jen.Code {
+	if altPath == "" {
+		return jen.Id("e").Dot("Add").Call(jen.Lit(method), jen.Lit(path), handler, middleware).Line()
+	}
+
+	return jen.Block(
+		jen.Id("_h").Op(":=").Add(handler),
+		jen.Id("e").Dot("Add").Call(jen.Lit(method), jen.Lit(path), jen.Id("_h"), middleware),
+		jen.Id("e").Dot("Add").Call(jen.Lit(method), jen.Lit(altPath), jen.Id("_h"), middleware),
+	).Line()
+}
+
+// echoContextMethods are the methods of echo.Context,
+// the fields of the operation contexts must not hide them.
+var echoContextMethods = map[string]bool{
+	"Context": true, "Request": true, "SetRequest": true, "Response": true, "SetResponse": true,
+	"IsTLS": true, "IsWebSocket": true, "Scheme": true, "RealIP": true, "Path": true, "SetPath": true,
+	"Param": true, "ParamNames": true, "SetParamNames": true, "ParamValues": true, "SetParamValues": true,
+	"QueryParam": true, "QueryParams": true, "QueryString": true, "FormValue": true, "FormParams": true,
+	"FormFile": true, "MultipartForm": true, "Cookie": true, "SetCookie": true, "Cookies": true,
+	"Get": true, "Set": true, "Bind": true, "Validate": true, "Render": true, "HTML": true, "HTMLBlob": true,
+	"String": true, "JSON": true, "JSONPretty": true, "JSONBlob": true, "JSONP": true, "JSONPBlob": true,
+	"XML": true, "XMLPretty": true, "XMLBlob": true, "Blob": true, "Stream": true, "File": true,
+	"Attachment": true, "Inline": true, "NoContent": true, "Redirect": true, "Error": true,
+	"Handler": true, "SetHandler": true, "Logger": true, "SetLogger": true, "Echo": true, "Reset": true,
+}
+
+// operationContextField returns the name of the field
+// of the parameter in the context struct of its operation.
+func operationContextField(ctx context.Context, param *spec.Parameter) string {
+	name := common.Initialisms(ctx).ToGoName(strcase.ToCamel(param.Name))
+
+	if echoContextMethods[name] {
+		name += "Param"
+	}
+
+	return name
+}
+
+// contentTypeBodies returns the body parameters of the operation,
+// there are more than one if there is a body for each content type.
+func contentTypeBodies(o *spec.Operation) []*spec.Parameter {
+	var bodies []*spec.Parameter
+
+	for _, param := range o.Parameters {
+		if param.Type == spec.ParameterTypeBody {
+			bodies = append(bodies, param)
+		}
+	}
+
+	return bodies
+}
+
+// mediaType returns the content type without its parameters.
+func mediaType(contentType string) string {
+	return strings.TrimSpace(strings.Split(contentType, ";")[0])
+}
+
+// requestMediaType returns the code for the media type
+// of the request in an Echo handler.
+func requestMediaType() jen.Code {
+	return jen.Qual("strings", "TrimSpace").Call(
+		jen.Qual("strings", "Split").Call(
+			jen.Id("c").Dot("Request").Call().Dot("Header").Dot("Get").Call(jen.Lit("Content-Type")),
+			jen.Lit(";"),
+		).Index(jen.Lit(0)),
+	)
+}
+
+// jsonParts returns the statements that decode the parts of a multipart
+// body that are JSON according to its encoding, Echo's binder only
+// binds them as plain form values.
+func jsonParts(param *spec.Parameter, paramName string) []jen.Code {
+	if !isMultipartBody(param) || len(param.Encoding) == 0 {
+		return nil
+	}
+
+	fieldNames := make([]string, 0, len(param.Schema.Children.Map))
+	for name := range param.Schema.Children.Map {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	var parts []jen.Code
+
+	for _, name := range fieldNames {
+		child := param.Schema.Children.Map[name]
+
+		formName := child.FieldName
+		if formName == "" {
+			formName = name
 		}
+
+		enc, ok := param.Encoding[formName]
+		if !ok || enc == nil || !util.IsJSONMediaType(strings.Split(enc.ContentType, ",")[0]) {
+			continue
+		}
+
+		parts = append(parts, jen.If(
+			jen.Id("_part").Op(":=").Id("c").Dot("FormValue").Call(jen.Lit(formName)),
+			jen.Id("_part").Op("!=").Lit(""),
+		).Block(
+			jen.If(
+				jen.Err().Op(":=").Add((&General{}).jsonCall("Unmarshal")).Call(
+					jen.Index().Byte().Call(jen.Id("_part")),
+					jen.Op("&").Id(paramName).Dot(name),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+					jen.Qual("net/http", "StatusBadRequest"),
+					jen.Lit(fmt.Sprintf("invalid %v part", formName)),
+				)),
+			),
+		))
+	}
+
+	return parts
+}
+
+// generateOptionsHandler generates a handler for OPTIONS requests
+// of a path, it returns nil if the path already has an OPTIONS operation.
+func (e *Echo) generateOptionsHandler(p *spec.Path, opts *EchoOptions) jen.Code {
+	methods := make([]string, 0, len(p.Operations)+1)
+
+	for _, o := range p.Operations {
+		method := strings.ToUpper(o.Method)
+		if method == http.MethodOptions {
+			return nil
+		}
+		methods = append(methods, method)
+	}
+
+	methods = append(methods, http.MethodOptions)
+	sort.Strings(methods)
+
+	allowed := strings.Join(methods, ", ")
+
+	statements := []jen.Code{
+		jen.Id("c").Dot("Response").Call().Dot("Header").Call().Dot("Set").Call(jen.Lit("Allow"), jen.Lit(allowed)),
+	}
+
+	if opts.CORSAllowOrigin != "" {
+		statements = append(statements,
+			jen.Id("c").Dot("Response").Call().Dot("Header").Call().Dot("Set").
+				Call(jen.Lit("Access-Control-Allow-Origin"), jen.Lit(opts.CORSAllowOrigin)),
+			jen.Id("c").Dot("Response").Call().Dot("Header").Call().Dot("Set").
+				Call(jen.Lit("Access-Control-Allow-Methods"), jen.Lit(allowed)),
+			jen.If(
+				jen.Id("_h").Op(":=").Id("c").Dot("Request").Call().Dot("Header").Dot("Get").
+					Call(jen.Lit("Access-Control-Request-Headers")),
+				jen.Id("_h").Op("!=").Lit(""),
+			).Block(
+				jen.Id("c").Dot("Response").Call().Dot("Header").Call().Dot("Set").
+					Call(jen.Lit("Access-Control-Allow-Headers"), jen.Id("_h")),
+			),
+		)
 	}
-	return c.Add(funcHeader.Block(funcBody...)), nil
+
+	statements = append(statements,
+		jen.Return(jen.Id("c").Dot("NoContent").Call(jen.Qual("net/http", "StatusNoContent"))),
+	)
+
+	return jen.Func().Params(jen.Id("c").Qual(echoPath, "Context")).Params(jen.Error()).Block(statements...)
 }
 
-func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter, opts *EchoOptions) (jen.Code, error) {
+func (e *Echo) generateExtractParam(ctx context.Context, o *spec.Operation, param *spec.Parameter, opts *EchoOptions) (jen.Code, error) {
 	// TODO implement arrays and objects
 
 	g := &General{}
@@ -571,6 +2146,10 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 
 	generalOpts.TypesPackagePath = opts.TypesPackagePath
 
+	primitiveFromString := func(s *spec.Schema, ptr bool, varName, strName jen.Code) (jen.Code, error) {
+		return gen.PrimitiveFromString(s, ptr, varName, strName, generalOpts.PrimitiveParsers)
+	}
+
 	paramC := jen.Null()
 
 	var pType jen.Code
@@ -604,7 +2183,7 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 		case spec.VariantPrimitive:
 			switch param.Serialization.Style {
 			case spec.SerializationSimple:
-				c, err := gen.PrimitiveFromString(
+				c, err := primitiveFromString(
 					param.Schema,
 					param.IsPtr(),
 					jen.Id(param.Name),
@@ -618,7 +2197,7 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				// .paramName
 				prefixLen := 1
 
-				c, err := gen.PrimitiveFromString(
+				c, err := primitiveFromString(
 					param.Schema,
 					param.IsPtr(),
 					jen.Id(param.Name),
@@ -634,7 +2213,7 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				// ;paramName=
 				prefixLen := len(param.Name) + 2
 
-				c, err := gen.PrimitiveFromString(
+				c, err := primitiveFromString(
 					param.Schema,
 					param.IsPtr(),
 					jen.Id(param.Name),
@@ -646,11 +2225,13 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				paramC.If(gen.Raw("len(c.Param(\"" + param.Name + "\")) > " + strconv.Itoa(prefixLen))).Block(
 					c,
 				).Line().Line()
+			default:
+				return nil, errUnsupportedParam(param)
 			}
 		case spec.VariantArray:
 			switch param.Serialization.Style {
 			case spec.SerializationSimple:
-				c, err := gen.PrimitiveFromString(
+				c, err := primitiveFromString(
 					param.Schema.Children.GetSchema(),
 					param.Schema.Children.GetSchema().ShouldBePtr(),
 					jen.Id("_param"),
@@ -687,12 +2268,16 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				}
 
 				paramC.Add(arrayC).Line().Line()
+			default:
+				return nil, errUnsupportedParam(param)
 			}
+		default:
+			return nil, errUnsupportedParam(param)
 		}
 	case spec.ParameterTypeCookie:
 		switch param.Schema.Variant {
 		case spec.VariantPrimitive:
-			c, err := gen.PrimitiveFromString(
+			c, err := primitiveFromString(
 				param.Schema,
 				param.IsPtr(),
 				jen.Id(param.Name),
@@ -702,6 +2287,8 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				return nil, err
 			}
 			paramC.Add(c).Line().Line()
+		default:
+			return nil, errUnsupportedParam(param)
 		}
 
 	case spec.ParameterTypeBody:
@@ -710,18 +2297,139 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 			addrOp.Op("&")
 		}
 
-		// TODO this has to be changed, as the body is not always required.
+		noBody := jen.Id("c").Dot("Request").Call().Dot("ContentLength").Op("==").Lit(0)
+
+		maxBodyBytes := opts.MaxBodyBytes
+		if o.MaxBodyBytes > 0 {
+			maxBodyBytes = o.MaxBodyBytes
+		}
+
+		// Bodies with a known length are rejected early,
+		// others fail to bind once the limit is reached.
+		if maxBodyBytes > 0 {
+			paramC.If(jen.Id("c").Dot("Request").Call().Dot("ContentLength").Op(">").Lit(maxBodyBytes)).Block(
+				jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+					jen.Qual("net/http", "StatusRequestEntityTooLarge"),
+					jen.Lit("request body is too large"),
+				)),
+			).Line()
+			paramC.Id("c").Dot("Request").Call().Dot("Body").Op("=").Qual("net/http", "MaxBytesReader").Call(
+				jen.Id("c").Dot("Response").Call(),
+				jen.Id("c").Dot("Request").Call().Dot("Body"),
+				jen.Lit(maxBodyBytes),
+			).Line()
+		}
+
+		// Operations with a body for each content type only
+		// bind the body of the content type of the request.
+		if bodies := contentTypeBodies(o); len(bodies) > 1 {
+			if bodies[0] == param {
+				supported := jen.Null()
+				for i, body := range bodies {
+					if i > 0 {
+						supported.Op("||")
+					}
+					supported.Qual("strings", "EqualFold").Call(requestMediaType(), jen.Lit(mediaType(body.ContentType)))
+				}
+
+				paramC.If(
+					jen.Id("c").Dot("Request").Call().Dot("ContentLength").Op("!=").Lit(0).
+						Op("&&").Op("!").Parens(supported),
+				).Block(
+					jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+						jen.Qual("net/http", "StatusUnsupportedMediaType"),
+						jen.Lit("unsupported content type"),
+					)),
+				).Line()
+			}
+
+			noBody.Op("||").Op("!").Qual("strings", "EqualFold").Call(requestMediaType(), jen.Lit(mediaType(param.ContentType)))
+		}
 
 		// We use Echo's binder to bind the value to its type.
-		paramC.Id("_").Op("=").Id("c").Op(".").Id("Bind").Call(addrOp.Id(paramName)).
-			Line().Line()
+		bind := jen.Id("_").Op("=").Id("c").Op(".").Id("Bind").Call(addrOp.Id(paramName))
+
+		// Other binding errors are ignored, but bodies
+		// that reached the limit must not be used partially.
+		//
+		// http.MaxBytesError is not available in older Go versions,
+		// and Echo only keeps the message of the error.
+		if maxBodyBytes > 0 {
+			bind = jen.If(
+				jen.Err().Op(":=").Id("c").Dot("Bind").Call(addrOp.Id(paramName)),
+				jen.Err().Op("!=").Nil().Op("&&").
+					Qual("strings", "Contains").Call(jen.Err().Dot("Error").Call(), jen.Lit("http: request body too large")),
+			).Block(
+				jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+					jen.Qual("net/http", "StatusRequestEntityTooLarge"),
+					jen.Lit("request body is too large"),
+				)),
+			)
+		}
+
+		switch {
+		case param.Required:
+			paramC.If(noBody).Block(
+				jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+					jen.Qual("net/http", "StatusBadRequest"),
+					jen.Lit("request body is required"),
+				)),
+			).Line()
+			paramC.Add(bind).Line().Line()
+		case param.IsPtr():
+			// An optional body is nil if it is missing.
+			paramC.If(noBody).Block(
+				jen.Id(paramName).Op("=").Nil(),
+			).Else().Block(bind).Line().Line()
+		default:
+			paramC.Add(bind).Line().Line()
+		}
+
+		if parts := jsonParts(param, paramName); parts != nil {
+			if param.IsPtr() {
+				paramC.If(jen.Id(paramName).Op("!=").Nil()).Block(parts...).Line().Line()
+			} else {
+				for _, part := range parts {
+					paramC.Add(part).Line()
+				}
+				paramC.Line()
+			}
+		}
+
+		// Validators such as go-playground/validator only validate structs.
+		if opts.UseValidator &&
+			(param.Schema.Variant == spec.VariantStruct || param.Schema.Variant == spec.VariantAllOf) {
+			validateAddrOp := jen.Null()
+			if !param.IsPtr() {
+				validateAddrOp.Op("&")
+			}
+
+			validate := jen.If(
+				jen.Err().Op(":=").Id("c").Dot("Validate").Call(validateAddrOp.Id(paramName)),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual(echoPath, "NewHTTPError").Call(
+					jen.Qual("net/http", "StatusBadRequest"),
+					jen.Err().Dot("Error").Call(),
+				)),
+			)
+
+			if param.IsPtr() {
+				paramC.If(jen.Id(paramName).Op("!=").Nil()).Block(validate).Line().Line()
+			} else {
+				paramC.Add(validate).Line().Line()
+			}
+		}
 
 	case spec.ParameterTypeHeader:
-		c, err := gen.PrimitiveFromString(
-			param.Schema,
-			param.IsPtr(),
-			jen.Id(param.Name),
-			jen.Id("c").Dot("Header").Dot("Get").Call(jen.Lit(param.Name)),
+		if param.Schema.Variant != spec.VariantPrimitive {
+			return nil, errUnsupportedParam(param)
+		}
+
+		c, err := withDefault(param, jen.Id("c").Dot("Request").Call().Dot("Header").Dot("Get").Call(jen.Lit(param.Name)),
+			func(raw jen.Code) (jen.Code, error) {
+				return primitiveFromString(param.Schema, param.IsPtr(), jen.Id(param.Name), raw)
+			},
 		)
 		if err != nil {
 			return nil, err
@@ -731,19 +2439,24 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 	case spec.ParameterTypeQuery:
 		switch param.Schema.Variant {
 		case spec.VariantPrimitive:
-			c, err := gen.PrimitiveFromString(
-				param.Schema,
-				param.IsPtr(),
-				jen.Id(param.Name),
-				jen.Id("c").Dot("QueryParam").Call(jen.Lit(param.Name)),
+			c, err := withDefault(param, jen.Id("c").Dot("QueryParam").Call(jen.Lit(param.Name)),
+				func(raw jen.Code) (jen.Code, error) {
+					return primitiveFromString(param.Schema, param.IsPtr(), jen.Id(param.Name), raw)
+				},
 			)
 			if err != nil {
 				return nil, err
 			}
 			paramC.Add(c).Line().Line()
 		case spec.VariantStruct:
+			// Only exploded form objects are supported,
+			// where each property is a separate query parameter.
+			if param.Serialization.Style != spec.SerializationForm || !param.Serialization.Explode {
+				return nil, errUnsupportedParam(param)
+			}
+
 			for field, fieldSchema := range param.Schema.Children.GetMap() {
-				c, err := gen.PrimitiveFromString(
+				c, err := primitiveFromString(
 					fieldSchema,
 					fieldSchema.ShouldBePtr() && !fieldSchema.CanBeNil() || fieldSchema.Nullable,
 					jen.Id(param.Name).Dot(field),
@@ -756,7 +2469,11 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 			}
 
 		case spec.VariantArray:
-			c, err := gen.PrimitiveFromString(
+			if param.Serialization.Style != spec.SerializationForm {
+				return nil, errUnsupportedParam(param)
+			}
+
+			c, err := primitiveFromString(
 				param.Schema.Children.GetSchema(),
 				param.Schema.Children.GetSchema().ShouldBePtr(),
 				jen.Id("_param"),
@@ -771,28 +2488,64 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 				return nil, err
 			}
 
-			arrayC, err := gen.Template(
-				`
-				for _, _s := range {{ .ParamArr }} {
+			extractArray := func(values jen.Code) (jen.Code, error) {
+				return gen.Template(
+					`
+					for _, _s := range {{ .ParamArr }} {
 						var _param {{ .paramType }}
 						{{ .deserialize }}
 						{{ .paramName }} = append({{ .paramName }}, _param)
 					}`[1:],
-				gen.Values{
-					"paramType":   jen.Add(arrType),
-					"deserialize": c,
-					"paramName":   jen.Id(param.Name),
-					"paramArr": jen.Qual("strings", "Split").Call(
-						jen.Id("c").Dot("QueryParam").Call(jen.Lit(param.Name)),
-						jen.Lit(","),
-					),
-				},
-			)
-			if err != nil {
-				return nil, err
+					gen.Values{
+						"paramType":   jen.Add(arrType),
+						"deserialize": c,
+						"paramName":   jen.Id(param.Name),
+						"paramArr":    values,
+					},
+				)
+			}
+
+			var arrayC jen.Code
+
+			if param.Serialization.Explode {
+				// Exploded arrays are sent as repeated keys.
+				arrayC, err = extractArray(jen.Id("_values"))
+				if err != nil {
+					return nil, err
+				}
+
+				values := jen.Id("_values").Op(":=").Id("c").Dot("QueryParams").Call().Index(jen.Lit(param.Name))
+
+				if def, ok := defaultString(param.Schema.Default); ok {
+					defValues := make([]jen.Code, 0)
+					for _, v := range strings.Split(def, ",") {
+						defValues = append(defValues, jen.Lit(v))
+					}
+
+					arrayC = jen.Block(
+						values,
+						jen.If(jen.Len(jen.Id("_values")).Op("==").Lit(0)).Block(
+							jen.Id("_values").Op("=").Index().String().Values(defValues...),
+						),
+						arrayC,
+					)
+				} else {
+					arrayC = jen.Block(values, arrayC)
+				}
+			} else {
+				arrayC, err = withDefault(param, jen.Id("c").Dot("QueryParam").Call(jen.Lit(param.Name)),
+					func(raw jen.Code) (jen.Code, error) {
+						return extractArray(jen.Qual("strings", "Split").Call(raw, jen.Lit(",")))
+					},
+				)
+				if err != nil {
+					return nil, err
+				}
 			}
 
 			paramC.Add(arrayC).Line().Line()
+		default:
+			return nil, errUnsupportedParam(param)
 		}
 
 	}
@@ -800,6 +2553,64 @@ func (e *Echo) generateExtractParam(ctx context.Context, param *spec.Parameter,
 	return paramC, nil
 }
 
+// withDefault generates the extraction of a parameter from its raw value,
+// and uses the default value of the parameter if the raw value is empty.
+func withDefault(param *spec.Parameter, raw jen.Code, extract func(raw jen.Code) (jen.Code, error)) (jen.Code, error) {
+	def, ok := defaultString(param.Schema.Default)
+	if !ok {
+		return extract(raw)
+	}
+
+	c, err := extract(jen.Id("_raw"))
+	if err != nil {
+		return nil, err
+	}
+
+	return jen.Block(
+		jen.Id("_raw").Op(":=").Add(raw),
+		jen.If(jen.Id("_raw").Op("==").Lit("")).Block(
+			jen.Id("_raw").Op("=").Lit(def),
+		),
+		c,
+	), nil
+}
+
+// defaultString returns the default value of a parameter
+// in its serialized form, arrays are separated by commas.
+func defaultString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int, int32, int64:
+		return fmt.Sprint(v), true
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := defaultString(item)
+			if !ok {
+				return "", false
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), true
+	default:
+		return "", false
+	}
+}
+
+// errUnsupportedParam returns an error for parameters
+// that cannot be extracted from the request by the wrapper.
+func errUnsupportedParam(param *spec.Parameter) error {
+	return fmt.Errorf(
+		`%v parameter "%v" with style %v and %v schema is not supported`,
+		param.Type, param.Name, param.Serialization.Style, param.Schema.Variant,
+	)
+}
+
 func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoOptions) (jen.Code, error) {
 	resC := jen.Null()
 
@@ -817,14 +2628,42 @@ func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoO
 		resC.Const().Id("NoResponse").Id("noResponse").Op("=").Lit("").Line().Line()
 	}
 
+	sharedNames := sharedEmptyResponseNames(sp)
+
 	for _, p := range sp.Paths {
 		for _, o := range p.Operations {
 
+			writerMethodName := "Write" + o.Name + opts.ResponsePostfix
+
+			resMethods := []jen.Code{
+				jen.Id(o.Name + opts.ResponsePostfix).Params(jen.Qual(echoPath, "Context")).Params(jen.Error()),
+			}
+
+			if opts.StdlibResponses {
+				resMethods = append(resMethods,
+					jen.Id(writerMethodName).Params(jen.Qual("net/http", "ResponseWriter")).Params(jen.Error()),
+				)
+			}
+
 			if options.Comments {
 				resC.Commentf("// %v defines responses for the %v operation.", o.Name+opts.ResponsePostfix, o.Name).Line()
 			}
+
+			// The gaps are always reported, otherwise returning the
+			// schema types would fail to compile without any explanation.
+			if unwired := unwiredResponses(o); len(unwired) > 0 {
+				if options.Comments {
+					resC.Comment("//").Line()
+				}
+				resC.Commentf("// WARNING: the following responses of %v are not implemented", o.Name).Line()
+				resC.Commentf("// by any type, and cannot be returned from the handler:").Line()
+				for _, reason := range unwired {
+					resC.Comment("//   - " + reason).Line()
+				}
+			}
+
 			resC.Type().Id(o.Name + opts.ResponsePostfix).Interface(
-				jen.Id(o.Name + opts.ResponsePostfix).Params(jen.Qual(echoPath, "Context")).Params(jen.Error()),
+				resMethods...,
 			).Line().Line()
 
 			if opts.AllowNoResponse {
@@ -832,29 +2671,89 @@ func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoO
 					Id(o.Name + opts.ResponsePostfix).
 					Params(jen.Id("ctx").Qual(echoPath, "Context")).Params(jen.Error()).
 					Block(jen.Return(jen.Nil())).Line().Line()
+
+				if opts.StdlibResponses {
+					resC.Func().Params(jen.Id("n").Id("noResponse")).
+						Id(writerMethodName).
+						Params(jen.Id("w").Qual("net/http", "ResponseWriter")).Params(jen.Error()).
+						Block(jen.Return(jen.Nil())).Line().Line()
+				}
 			}
 
+			// Empty responses that were already generated,
+			// responses with multiple content types would be duplicated.
+			emptyResponses := make(map[string]bool)
+
+			// The same for responses with headers,
+			// only the first content type is used for them.
+			headerResponses := make(map[string]bool)
+
+			// Schemas can implement the response interface only once,
+			// with the first status code they are documented with.
+			schemaResponses := make(map[string]bool)
+
+			// The responses of the schemas by schema name, one for each status code.
+			statusResponses := make(map[string][]*spec.Response)
+			schemaNames := make([]string, 0)
+
 			for _, res := range o.Responses {
 				// TODO default and range responses
-				if strings.ToLower(strings.TrimSpace(res.Code)) == "default" ||
-					strings.Contains(strings.ToLower(res.Code), "x") {
+				if unsupportedResponse(res) != "" {
 					continue
 				}
 
-				// The response is empty
-				if res.Schema == nil {
+				// Responses with headers are wrapped in a struct.
+				if len(res.Headers) > 0 {
+					if headerResponses[res.Name] {
+						continue
+					}
+					headerResponses[res.Name] = true
+
+					c, err := e.generateHeaderResponse(ctx, o, res, opts)
+					if err != nil {
+						return nil, err
+					}
+					resC.Add(c)
+
+					continue
+				}
 
-					emptyResName := "res" + o.Name + res.Code
-					if res.Name != "" {
-						emptyResName = "res" + strings.Title(res.Name)
+				// The response is empty, responses that cannot have
+				// a body are always empty, even if a body is documented.
+				if res.Schema == nil || isEmptyStatus(res.Code) {
+					if emptyResponses[res.Code] {
+						continue
 					}
+					emptyResponses[res.Code] = true
+
+					constName := emptyResponseName(o, res, sharedNames, opts)
+					emptyResName := "res" + constName
 
 					resC.Type().Id(emptyResName).String().Line().Line()
 
 					if options.Comments {
-						resC.Commentf("// %v defines an empty response for the %v operation.", strings.Title(res.Name), o.Name).Line()
+						resC.Commentf("// %v defines an empty response for the %v operation.", constName, o.Name).Line()
+					}
+					resC.Const().Id(constName).Id(emptyResName).Op("=").Lit("").Line().Line()
+
+					if opts.StdlibResponses {
+						resC.Func().Params(jen.Id("r").Id(emptyResName)).
+							Id(writerMethodName).
+							Params(jen.Id("w").Qual("net/http", "ResponseWriter")).Params(jen.Error()).
+							Block(
+								jen.Id("w").Dot("WriteHeader").Call(jen.Lit(util.MustParseInt(res.Code))),
+								jen.Return(jen.Nil()),
+							).Line().Line()
+
+						resC.Func().Params(jen.Id("r").Id(emptyResName)).
+							Id(o.Name + opts.ResponsePostfix).
+							Params(jen.Id("ctx").Qual(echoPath, "Context")).Params(jen.Error()).
+							Block(
+								jen.Return(jen.Id("r").Dot(writerMethodName).Call(jen.Id("ctx").Dot("Response").Call())),
+							).Line().Line()
+
+						continue
 					}
-					resC.Const().Id(strings.Title(res.Name)).Id(emptyResName).Op("=").Lit("").Line().Line()
 
 					resC.Func().Params(jen.Id("r").Id(emptyResName)).
 						Id(o.Name+opts.ResponsePostfix).
@@ -867,10 +2766,22 @@ func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoO
 					continue
 				}
 
-				// We can't handle unnamed schemas
-				if res.Schema.Name == "" {
+				if _, ok := statusResponses[res.Schema.Name]; !ok {
+					schemaNames = append(schemaNames, res.Schema.Name)
+				}
+
+				if !hasStatus(statusResponses[res.Schema.Name], res.Code) {
+					statusResponses[res.Schema.Name] = append(statusResponses[res.Schema.Name], res)
+				}
+
+				// The schema already implements the response interface
+				// for another status code or content type.
+				if schemaResponses[res.Schema.Name] {
 					continue
 				}
+				schemaResponses[res.Schema.Name] = true
+
+				status := jen.Lit(util.MustParseInt(res.Code))
 
 				var rTypeName string
 				if res.IsPtr() {
@@ -879,9 +2790,37 @@ func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoO
 					rTypeName = res.Schema.Name
 				}
 
-				resCode, err := e.generateResponseInterfaceBody(ctx, res, opts)
-				if err != nil {
-					return nil, err
+				rName := responseValueName
+
+				var resCode jen.Code
+
+				if opts.StdlibResponses {
+					writerCode, err := e.generateResponseWriterBody(ctx, res, status, isHeadOperation(o), opts)
+					if err != nil {
+						return nil, err
+					}
+
+					if options.Comments {
+						resC.Add(gen.Comments(
+							fmt.Sprintf("%v writes %v as a response of the %v operation.",
+								writerMethodName,
+								res.Schema.Name,
+								o.Name,
+							),
+						))
+					}
+					resC.Func().Params(jen.Id(rName).Id(rTypeName)).
+						Id(writerMethodName).
+						Params(jen.Id("w").Qual("net/http", "ResponseWriter")).Params(jen.Error()).
+						Block(writerCode).Line().Line()
+
+					resCode = jen.Return(jen.Id(rName).Dot(writerMethodName).Call(jen.Id("ctx").Dot("Response").Call()))
+				} else {
+					c, err := e.generateResponseInterfaceBody(ctx, res, status, isHeadOperation(o), opts)
+					if err != nil {
+						return nil, err
+					}
+					resCode = c
 				}
 
 				if options.Comments {
@@ -892,36 +2831,432 @@ func (e *Echo) generateResponses(ctx context.Context, sp *spec.Spec, opts *EchoO
 						),
 					))
 				}
-				resC.Func().Params(jen.Id(strings.ToLower(res.Schema.Name[:1])).Id(rTypeName)).
+				resC.Func().Params(jen.Id(rName).Id(rTypeName)).
 					Id(o.Name + opts.ResponsePostfix).
 					Params(jen.Id("ctx").Qual(echoPath, "Context")).Params(jen.Error()).
 					Block(resCode).Line().Line()
 			}
+
+			if opts.StatusResponses {
+				for _, name := range schemaNames {
+					if len(statusResponses[name]) < 2 {
+						continue
+					}
+
+					c, err := e.generateStatusResponse(ctx, o, statusResponses[name], opts)
+					if err != nil {
+						return nil, err
+					}
+					resC.Add(c)
+				}
+			}
 		}
 	}
 
 	return resC, nil
 }
 
-func (e *Echo) generateResponseInterfaceBody(ctx context.Context, res *spec.Response, opts *EchoOptions) (jen.Code, error) {
-	// It is assumed that echo context is named "ctx"
+// hasStatus checks whether any of the responses has the status code.
+func hasStatus(responses []*spec.Response, code string) bool {
+	for _, res := range responses {
+		if res.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generateStatusResponse generates a constructor for a response schema
+// that is documented with multiple status codes, the returned value
+// implements the response interface of the operation with the chosen status code.
+func (e *Echo) generateStatusResponse(ctx context.Context, o *spec.Operation, responses []*spec.Response, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	res := responses[0]
+
+	writerMethodName := "Write" + o.Name + opts.ResponsePostfix
+	funcName := o.Name + res.Schema.Name + "WithStatus"
+	typeName := strcase.ToLowerCamel(funcName)
+	rName := responseValueName
+	status := func() *jen.Statement {
+		return jen.Id("response").Dot("status")
+	}
+
+	bodyType := jen.Null()
+	if res.IsPtr() {
+		bodyType.Op("*")
+	}
+	bodyType.Id(res.Schema.Name)
+
+	codes := make([]jen.Code, 0, len(responses))
+	codeNames := make([]string, 0, len(responses))
+	for _, r := range responses {
+		codes = append(codes, jen.Lit(util.MustParseInt(r.Code)))
+		codeNames = append(codeNames, r.Code)
+	}
+
+	// Only the documented status codes are accepted.
+	checkStatus := jen.Switch(status()).Block(
+		jen.Case(codes...),
+		jen.Default().Block(
+			jen.Return(jen.Qual("fmt", "Errorf").Call(
+				jen.Lit(fmt.Sprintf("status %%v is not documented for %v in the %v operation", res.Schema.Name, o.Name)),
+				status(),
+			)),
+		),
+	).Line()
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v responds with the given status code in the %v operation,", funcName, o.Name).Line()
+		code.Commentf("// it must be one of %v, otherwise the response fails with an error.", strings.Join(codeNames, ", ")).Line()
+	}
+	code.Func().Id(funcName).Params(jen.Id("status").Int(), jen.Id("body").Add(bodyType)).Id(o.Name + opts.ResponsePostfix).Block(
+		jen.Return(jen.Id(typeName).Values(jen.Dict{
+			jen.Id("status"): jen.Id("status"),
+			jen.Id("body"):   jen.Id("body"),
+		})),
+	).Line().Line()
+
+	code.Type().Id(typeName).Struct(
+		jen.Id("status").Int(),
+		jen.Id("body").Add(bodyType),
+	).Line().Line()
+
+	// Responses of HEAD operations do not use the body.
+	body := jen.Null()
+	if !isHeadOperation(o) {
+		body.Id(rName).Op(":=").Id("response").Dot("body").Line()
+	}
+
+	var bodyCode jen.Code
+
+	if opts.StdlibResponses {
+		c, err := e.generateResponseWriterBody(ctx, res, status(), isHeadOperation(o), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		code.Func().Params(jen.Id("response").Id(typeName)).Id(writerMethodName).
+			Params(jen.Id("w").Qual("net/http", "ResponseWriter")).Params(jen.Error()).
+			Block(
+				checkStatus,
+				body,
+				c,
+			).Line().Line()
+
+		bodyCode = jen.Return(jen.Id("response").Dot(writerMethodName).Call(jen.Id("ctx").Dot("Response").Call()))
+	} else {
+		c, err := e.generateResponseInterfaceBody(ctx, res, status(), isHeadOperation(o), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		bodyCode = jen.Add(checkStatus, body, c)
+	}
+
+	code.Func().Params(jen.Id("response").Id(typeName)).Id(o.Name + opts.ResponsePostfix).
+		Params(jen.Id("ctx").Qual(echoPath, "Context")).Params(jen.Error()).
+		Block(bodyCode).Line().Line()
+
+	return code, nil
+}
+
+// emptyResponseName returns the name of the
+// value of an empty response of the operation.
+//
+// The name is prefixed with the name of the operation if
+// it is shared with other operations, or if it is required by the options.
+func emptyResponseName(o *spec.Operation, res *spec.Response, sharedNames map[string]bool, opts *EchoOptions) string {
+	name := o.Name + res.Code
+	if res.Name != "" {
+		name = strings.Title(res.Name)
+	}
+
+	if (opts.ScopeEmptyResponses || sharedNames[name]) && !strings.HasPrefix(name, o.Name) {
+		name = o.Name + name
+	}
+
+	return name
+}
+
+// sharedEmptyResponseNames returns the names of the
+// empty responses that are used by more than one operation.
+func sharedEmptyResponseNames(sp *spec.Spec) map[string]bool {
+	operations := make(map[string]*spec.Operation)
+	shared := make(map[string]bool)
+
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			for _, res := range o.Responses {
+				if res.Name == "" || len(res.Headers) > 0 || unsupportedResponse(res) != "" ||
+					(res.Schema != nil && !isEmptyStatus(res.Code)) {
+					continue
+				}
+
+				name := strings.Title(res.Name)
+
+				if other, ok := operations[name]; ok && other != o {
+					shared[name] = true
+				}
+				operations[name] = o
+			}
+		}
+	}
+
+	return shared
+}
+
+// unsupportedResponse returns the reason why the response cannot implement
+// the response interface of its operation, or an empty string if it can.
+func unsupportedResponse(res *spec.Response) string {
+	code := strings.ToLower(strings.TrimSpace(res.Code))
+
+	switch {
+	case code == "default":
+		return "default responses are not supported"
+	case strings.Contains(code, "x"):
+		return "status code ranges are not supported"
+	case res.Schema != nil && res.Schema.Name == "" && !isEmptyStatus(res.Code):
+		return "inline schemas are not supported, use a named schema instead"
+	}
+
+	return ""
+}
+
+// unwiredResponses returns the reasons why responses of the operation
+// do not implement its response interface.
+func unwiredResponses(o *spec.Operation) []string {
+	reasons := make([]string, 0)
+
+	for _, res := range o.Responses {
+		reason := unsupportedResponse(res)
+		if reason == "" {
+			continue
+		}
+
+		contentType := res.ContentType
+		if contentType == "" {
+			contentType = "no content type"
+		}
+
+		reasons = append(reasons, fmt.Sprintf("%v (%v): %v", res.Code, contentType, reason))
+	}
+
+	return reasons
+}
+
+// generateHeaderResponse generates a struct for a response with headers
+// that contains the body (if any) and the headers, and implements the
+// response interface of the operation.
+func (e *Echo) generateHeaderResponse(ctx context.Context, o *spec.Operation, res *spec.Response, opts *EchoOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	g := &General{}
+	generalOpts, err := g.GetOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generalOpts.TypesPackagePath = opts.TypesPackagePath
+
+	writerMethodName := "Write" + o.Name + opts.ResponsePostfix
+	hasBody := res.Schema != nil && !isEmptyStatus(res.Code)
+	status := jen.Lit(util.MustParseInt(res.Code))
+
+	fields := make([]jen.Code, 0, len(res.Headers)+1)
+
+	if hasBody {
+		if options.Comments {
+			fields = append(fields, jen.Comment("// Body is the body of the response."))
+		}
+
+		bodyType := jen.Null()
+		if res.IsPtr() {
+			bodyType.Op("*")
+		}
+		bodyType.Add(gen.Qual(opts.TypesPackagePath, res.Schema.Name))
+
+		fields = append(fields, jen.Id("Body").Add(bodyType), jen.Line())
+	}
+
+	// Sets the headers on the http.Header named "header".
+	setHeaders := make([]jen.Code, 0, len(res.Headers))
+
+	for _, h := range res.Headers {
+		fieldName := common.Initialisms(ctx).ToGoName(strcase.ToCamel(h.Name))
+
+		tp, err := g.GenerateType(ctx, h.Schema, generalOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		isPtr := !h.Required && !h.Schema.CanBeNil()
+
+		fieldType := jen.Null()
+		if isPtr {
+			fieldType.Op("*")
+		}
+		fieldType.Add(tp)
+
+		if options.Comments {
+			comment := fmt.Sprintf("%v is the value of the %v header.", fieldName, h.Name)
+			if h.Description != "" {
+				comment += " " + strings.TrimSpace(h.Description)
+			}
+			fields = append(fields, gen.Comments(comment))
+		}
+		fields = append(fields, jen.Id(fieldName).Add(fieldType))
+
+		value := func() *jen.Statement {
+			if isPtr {
+				return jen.Op("*").Id("response").Dot(fieldName)
+			}
+			return jen.Id("response").Dot(fieldName)
+		}
+
+		var set jen.Code
+		if h.Schema.Variant == spec.VariantArray {
+			set = jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Add(value())).Block(
+				jen.Id("header").Dot("Add").Call(jen.Lit(h.Name), headerValue(h.Schema.Children.GetSchema(), jen.Id("v"))),
+			)
+		} else {
+			set = jen.Id("header").Dot("Set").Call(jen.Lit(h.Name), headerValue(h.Schema, value()))
+		}
+
+		if isPtr || h.Schema.CanBeNil() {
+			set = jen.If(jen.Id("response").Dot(fieldName).Op("!=").Nil()).Block(set)
+		}
+
+		setHeaders = append(setHeaders, set)
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v is a response of the %v operation with headers.", res.Name, o.Name).Line()
+	}
+	code.Type().Id(res.Name).Struct(fields...).Line().Line()
+
+	var bodyCode jen.Code
+	var writerCode jen.Code
+
+	if hasBody {
+		rName := responseValueName
+		head := isHeadOperation(o)
+
+		// Responses of HEAD operations do not use the body.
+		body := jen.Null()
+		if !head {
+			body.Id(rName).Op(":=").Id("response").Dot("Body").Line()
+		}
+
+		c, err := e.generateResponseInterfaceBody(ctx, res, status, head, opts)
+		if err != nil {
+			return nil, err
+		}
+		bodyCode = jen.Add(body, c)
+
+		if opts.StdlibResponses {
+			c, err := e.generateResponseWriterBody(ctx, res, status, head, opts)
+			if err != nil {
+				return nil, err
+			}
+			writerCode = jen.Add(body, c)
+		}
+	} else {
+		bodyCode = jen.Id("ctx").Dot("NoContent").Call(status).Line().Return(jen.Nil())
+		writerCode = jen.Id("w").Dot("WriteHeader").Call(status).Line().Return(jen.Nil())
+	}
+
+	if opts.StdlibResponses {
+		if options.Comments {
+			code.Commentf("// %v writes the headers and the body of the response.", writerMethodName).Line()
+		}
+		writer := make([]jen.Code, 0, len(setHeaders)+3)
+		writer = append(writer, jen.Id("header").Op(":=").Id("w").Dot("Header").Call())
+		writer = append(writer, setHeaders...)
+		writer = append(writer, jen.Line(), writerCode)
 
-	resStatus := util.MustParseInt(res.Code)
+		code.Func().Params(jen.Id("response").Id(res.Name)).Id(writerMethodName).
+			Params(jen.Id("w").Qual("net/http", "ResponseWriter")).Params(jen.Error()).
+			Block(writer...).Line().Line()
+
+		bodyCode = jen.Return(jen.Id("response").Dot(writerMethodName).Call(jen.Id("ctx").Dot("Response").Call()))
+		setHeaders = nil
+	}
+
+	body := make([]jen.Code, 0, len(setHeaders)+2)
+
+	if len(setHeaders) > 0 {
+		body = append(body, jen.Id("header").Op(":=").Id("ctx").Dot("Response").Call().Dot("Header").Call())
+		body = append(body, setHeaders...)
+		body = append(body, jen.Line())
+	}
+
+	body = append(body, bodyCode)
+
+	if options.Comments {
+		code.Commentf("// %v sets the headers, and writes the body of the response.", o.Name+opts.ResponsePostfix).Line()
+	}
+	code.Func().Params(jen.Id("response").Id(res.Name)).Id(o.Name + opts.ResponsePostfix).
+		Params(jen.Id("ctx").Qual(echoPath, "Context")).Params(jen.Error()).
+		Block(body...).Line().Line()
+
+	return code, nil
+}
+
+// headerValue converts a value to a header string.
+func headerValue(schema *spec.Schema, value jen.Code) jen.Code {
+	if schema == nil {
+		return jen.Qual("fmt", "Sprint").Call(value)
+	}
+
+	switch {
+	case schema.Name == "" && schema.PrimitiveType == "string":
+		return value
+	case schema.PrimitiveType == "time.Time":
+		return jen.Parens(value).Dot("UTC").Call().Dot("Format").Call(jen.Qual("net/http", "TimeFormat"))
+	default:
+		return jen.Qual("fmt", "Sprint").Call(value)
+	}
+}
+
+// generateResponseInterfaceBody generates the body of a method
+// that writes a response into an Echo context named "ctx".
+//
+// Responses of HEAD operations only set the headers and the status code.
+func (e *Echo) generateResponseInterfaceBody(ctx context.Context, res *spec.Response, status jen.Code, head bool, opts *EchoOptions) (jen.Code, error) {
+	// It is assumed that echo context is named "ctx"
 
 	resCode := jen.Null()
 
-	rName := strings.ToLower(res.Schema.Name[:1])
+	if head {
+		return resCode.Id("ctx").Dot("Response").Call().Dot("Header").Call().Dot("Set").
+			Call(jen.Lit("Content-Type"), jen.Lit(res.ContentType)).Line().
+			Return(jen.Id("ctx").Dot("NoContent").Call(status)), nil
+	}
+
+	rName := responseValueName
 
 	ptrCheck := jen.Null()
 
-	if res.IsPtr() {
+	if res.IsPtr() && !writesNull(res) {
 		c := gen.MustTemplate(`if {{ .Value }} == nil {
 				ctx.NoContent({{ .Status }})
 				return nil
 			}`,
 			gen.Values{
 				"EmptyResponse": ptrCheck,
-				"Status":        jen.Lit(resStatus),
+				"Status":        status,
 				"Value":         jen.Id(rName),
 			},
 		)
@@ -929,30 +3264,34 @@ func (e *Echo) generateResponseInterfaceBody(ctx context.Context, res *spec.Resp
 		ptrCheck.Add(c).Line().Line()
 	}
 
+	// Echo only sets its own content type if there is none,
+	// so the exact content type from the specification is used.
+	ptrCheck.Id("ctx").Dot("Response").Call().Dot("Header").Call().Dot("Set").
+		Call(jen.Lit("Content-Type"), jen.Lit(res.ContentType)).Line()
+
 	switch {
-	case strings.HasPrefix(res.ContentType, "application/json"):
+	case util.IsJSONMediaType(res.ContentType):
 
 		c := gen.MustTemplate(`{{ .EmptyResponse }}
 		err := ctx.JSON({{ .Status }}, {{ .Value }})
 			return err`,
 			gen.Values{
 				"EmptyResponse": ptrCheck,
-				"Status":        jen.Lit(resStatus),
+				"Status":        status,
 				"Value":         jen.Id(rName),
 			},
 		)
 
 		resCode.Add(c)
 
-	case strings.HasPrefix(res.ContentType, "application/xml"),
-		strings.HasPrefix(res.ContentType, "text/xml"):
+	case isXMLContentType(res.ContentType):
 
 		c := gen.MustTemplate(`{{ .EmptyResponse }}
 		err := ctx.XML({{ .Status }}, {{ .Value }})
 		return err`,
 			gen.Values{
 				"EmptyResponse": ptrCheck,
-				"Status":        jen.Lit(resStatus),
+				"Status":        status,
 				"Value":         jen.Id(rName),
 			},
 		)
@@ -967,7 +3306,7 @@ func (e *Echo) generateResponseInterfaceBody(ctx context.Context, res *spec.Resp
 		return err`,
 			gen.Values{
 				"EmptyResponse": ptrCheck,
-				"Status":        jen.Lit(resStatus),
+				"Status":        status,
 				"Value":         jen.Qual("fmt", "Sprint").Call(jen.Id(rName)),
 			},
 		)
@@ -980,3 +3319,95 @@ func (e *Echo) generateResponseInterfaceBody(ctx context.Context, res *spec.Resp
 
 	return resCode, nil
 }
+
+// isHeadOperation checks whether the operation is for the HEAD method,
+// the responses of which must not have a body.
+func isHeadOperation(o *spec.Operation) bool {
+	return strings.EqualFold(o.Method, http.MethodHead)
+}
+
+// writesNull checks whether a nil response value is sent as a JSON null
+// instead of an empty body, which is the case for nullable JSON responses.
+func writesNull(res *spec.Response) bool {
+	return res.Schema != nil && res.Schema.Nullable && util.IsJSONMediaType(res.ContentType)
+}
+
+// isEmptyStatus checks whether responses with the status code
+// must not have a body according to HTTP (1xx, 204 and 304).
+func isEmptyStatus(code string) bool {
+	code = strings.TrimSpace(code)
+
+	return code == "204" || code == "304" || (len(code) == 3 && code[0] == '1')
+}
+
+// isXMLContentType checks whether the content type is XML,
+// including structured syntax suffixes like application/atom+xml.
+func isXMLContentType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// generateResponseWriterBody is the same as generateResponseInterfaceBody,
+// but writes the response into a http.ResponseWriter named "w"
+// instead of an Echo context.
+func (e *Echo) generateResponseWriterBody(ctx context.Context, res *spec.Response, status jen.Code, head bool, opts *EchoOptions) (jen.Code, error) {
+	resCode := jen.Null()
+
+	if head {
+		return resCode.Id("w").Dot("Header").Call().Dot("Set").
+			Call(jen.Lit("Content-Type"), jen.Lit(res.ContentType)).Line().
+			Id("w").Dot("WriteHeader").Call(status).Line().
+			Return(jen.Nil()), nil
+	}
+
+	rName := responseValueName
+
+	if res.IsPtr() && !writesNull(res) {
+		resCode.Add(gen.MustTemplate(`if {{ .Value }} == nil {
+				w.WriteHeader({{ .Status }})
+				return nil
+			}`,
+			gen.Values{
+				"Status": status,
+				"Value":  jen.Id(rName),
+			},
+		)).Line().Line()
+	}
+
+	encoderValues := templates.HTTPRespondJSONDefaults()
+	encoderValues.ContentType = jen.Lit(res.ContentType)
+	encoderValues.StatusCode = status
+	encoderValues.Value = jen.Id(rName)
+	encoderValues.HandleErr = jen.Return(jen.Err())
+
+	switch {
+	case util.IsJSONMediaType(res.ContentType):
+		resCode.Add(gen.MustTemplate(templates.HTTPRespondEncoder, encoderValues))
+
+	case isXMLContentType(res.ContentType):
+		encoderValues.NewEncoder = jen.Qual("encoding/xml", "NewEncoder")
+
+		resCode.Add(gen.MustTemplate(templates.HTTPRespondEncoder, encoderValues))
+
+	case strings.HasPrefix(res.ContentType, "text/plain"):
+		c := gen.MustTemplate(`w.Header().Set("Content-Type", {{ .ContentType }})
+		w.WriteHeader({{ .Status }})
+		_, err := {{ .Fprint }}(w, {{ .Value }})
+		return err`,
+			gen.Values{
+				"ContentType": jen.Lit(res.ContentType),
+				"Status":      status,
+				"Fprint":      jen.Qual("fmt", "Fprint"),
+				"Value":       jen.Id(rName),
+			},
+		)
+
+		resCode.Add(c)
+
+	default:
+		return nil, fmt.Errorf("MIME type %v not supported", res.ContentType)
+	}
+
+	return resCode, nil
+}
@@ -0,0 +1,1021 @@
+package golang
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/labstack/echo/v4"
+	"github.com/tamasfe/repose/pkg/common"
+	reposeparser "github.com/tamasfe/repose/pkg/parser"
+	"github.com/tamasfe/repose/pkg/transformer"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestEmptyStatusResponses(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "put": {
+        "operationId": "updatePets",
+        "responses": {
+          "204": {
+            "description": "Updated, but the body is documented by mistake",
+            "content": {
+              "application/json": {"schema": {"$ref": "#/components/schemas/Pet"}},
+              "application/xml": {"schema": {"$ref": "#/components/schemas/Pet"}}
+            }
+          },
+          "304": {
+            "description": "Not modified",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.Equal(t, strings.Contains(src, "ctx.NoContent(204)"), true)
+	assert.Equal(t, strings.Contains(src, "ctx.NoContent(304)"), true)
+	assert.Equal(t, strings.Contains(src, "ctx.JSON("), false)
+	assert.Equal(t, strings.Contains(src, "ctx.XML("), false)
+	assert.Equal(t, strings.Count(src, "type resUpdatePetsResponse204 string"), 1)
+}
+
+func TestUnsupportedParameterStyle(t *testing.T) {
+	_, err := generateTestCode(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {
+            "name": "filter",
+            "in": "query",
+            "style": "deepObject",
+            "explode": true,
+            "schema": {"type": "object", "properties": {"name": {"type": "string"}}}
+          }
+        ],
+        "responses": {"204": {"description": "Pets"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", nil)
+
+	if err == nil {
+		t.Fatal("expected an error for the deepObject parameter")
+	}
+
+	assert.Equal(t, strings.Contains(strings.ToLower(err.Error()), "listpets"), true)
+	assert.Equal(t, strings.Contains(err.Error(), `"filter"`), true)
+	assert.Equal(t, strings.Contains(err.Error(), "deepObject"), true)
+}
+
+func TestParameterDefaults(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "default": 20}},
+          {"name": "sort", "in": "query", "schema": {"type": "string"}},
+          {"name": "tags", "in": "query", "explode": false, "schema": {"type": "array", "items": {"type": "string"}, "default": ["a", "b"]}}
+        ],
+        "responses": {"204": {"description": "Pets"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.Equal(t, strings.Contains(src, `_raw = "20"`), true)
+	assert.Equal(t, strings.Contains(src, `_raw = "a,b"`), true)
+	assert.Equal(t, strings.Count(src, "_raw := "), 2)
+}
+
+func TestExplodedQueryArrays(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "ids", "in": "query", "schema": {"type": "array", "items": {"type": "integer"}, "default": [1, 2]}}
+        ],
+        "responses": {"204": {"description": "Pets"}}
+      }
+    }
+  }
+}`
+
+	// The client sends every element as a repeated key,
+	// the server must read all of them.
+	clientSrc := string(generateTestFile(t, spec, &StdLib{}, "client", nil))
+	assert.MatchRegex(t, clientSrc, `_q\.Add\("tags", fmt\.Sprint\(_p\)\)`)
+	assert.MatchRegex(t, clientSrc, `_q\.Add\("ids", fmt\.Sprint\(_p\)\)`)
+
+	serverSrc := generateTestFile(t, spec, &Echo{}, "server", nil)
+	src := string(serverSrc)
+
+	assert.Equal(t, strings.Contains(src, `_values := c.QueryParams()["tags"]`), true)
+	assert.Equal(t, strings.Contains(src, `_values := c.QueryParams()["ids"]`), true)
+	assert.MatchRegex(t, src, `if len\(_values\) == 0 \{\s+_values = \[\]string\{"1", "2"\}\s+\}`)
+	assert.Equal(t, strings.Contains(src, `c.QueryParam("tags")`), false)
+	assert.Equal(t, strings.Contains(src, `strings.Split`), false)
+
+	typeCheck(t, serverSrc)
+}
+
+func TestNullableResponse(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/current": {
+      "get": {
+        "operationId": "getCurrentPet",
+        "responses": {
+          "200": {
+            "description": "The current pet, or null if there is none",
+            "content": {
+              "application/json": {
+                "schema": {"type": "object", "nullable": true, "properties": {"name": {"type": "string"}}}
+              }
+            }
+          }
+        }
+      }
+    },
+    "/pets/first": {
+      "get": {
+        "operationId": "getFirstPet",
+        "responses": {
+          "201": {
+            "description": "The first pet",
+            "content": {
+              "application/json": {
+                "schema": {"type": "object", "properties": {"name": {"type": "string"}}}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.Equal(t, strings.Contains(src, "ctx.JSON(200, "), true)
+	assert.Equal(t, strings.Contains(src, "ctx.NoContent(200)"), false)
+	assert.Equal(t, strings.Contains(src, "ctx.NoContent(201)"), true)
+}
+
+const basePathTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "servers": [{"url": "/api/v1"}],
+  "paths": {
+    "/pets/{id}": {
+      "delete": {
+        "operationId": "deletePet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`
+
+func TestRelativeServerBasePath(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, `"/api/v1/pets/:id"`), true)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"ignoreBasePath": true,
+	}))
+	assert.Equal(t, strings.Contains(src, `"/pets/:id"`), true)
+	assert.Equal(t, strings.Contains(src, "/api/v1"), false)
+}
+
+func TestTrailingSlashInsensitive(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, `"/api/v1/pets/:id/"`), false)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"trailingSlashInsensitive": true,
+	}))
+	assert.MatchRegex(t, src, `e\.Add\("DELETE", "/api/v1/pets/:id", _h, middleware\.DeletePet\.\.\.\)`)
+	assert.MatchRegex(t, src, `e\.Add\("DELETE", "/api/v1/pets/:id/", _h, middleware\.DeletePet\.\.\.\)`)
+}
+
+func TestOperationContext(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, "DeletePetContext"), false)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"operationContext": true,
+	}))
+	assert.MatchRegex(t, src, `type DeletePetContext struct \{\s+echo\.Context\s+ID\s+string\s+\}`)
+	assert.MatchRegex(t, src, `DeletePet\(c \*DeletePetContext\) \(DeletePetHandlerResponse, error\)`)
+	assert.MatchRegex(t, src, `&DeletePetContext\{\s*Context:\s+c,\s+ID:\s+id,?\s*\}`)
+}
+
+func TestTimeParameters(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "bornAfter", "in": "query", "schema": {"type": "string", "format": "date"}},
+          {"name": "seenAfter", "in": "query", "schema": {"type": "string", "format": "date-time"}}
+        ],
+        "responses": {"204": {"description": "Pets"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.Equal(t, strings.Contains(src, `time.Parse("2006-01-02", c.QueryParam("bornAfter"))`), true)
+	assert.Equal(t, strings.Contains(src, `time.Parse(time.RFC3339, c.QueryParam("seenAfter"))`), true)
+}
+
+func TestParamsInContext(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer", "format": "int32"}}],
+        "responses": {"204": {"description": "ok"}}
+      }
+    },
+    "/owners": {
+      "get": {
+        "operationId": "listOwners",
+        "parameters": [{"name": "limit", "in": "query", "schema": {"type": "integer", "format": "int32"}}],
+        "responses": {"204": {"description": "ok"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", map[string]interface{}{
+		"paramsInContext": true,
+	}))
+
+	assert.MatchRegex(t, src, `ListPets\(c echo\.Context\) \(`)
+	assert.MatchRegex(t, src, `type paramKey struct \{\s+in\s+string\s+name string\s+\}`)
+	assert.Equal(t, strings.Contains(src, `context.WithValue(c.Request().Context(), paramKey{`), true)
+	assert.MatchRegex(t, src, `in:\s+"query",\s+name: "limit",`)
+	assert.Equal(t, strings.Count(src, "func GetLimit(c echo.Context) *int32 {"), 1)
+
+	// Parameters with the same name in different locations
+	// are stored under different keys.
+	src = string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "id", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {"204": {"description": "ok"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", map[string]interface{}{
+		"paramsInContext": true,
+	}))
+
+	assert.MatchRegex(t, src, `in:\s+"path",\s+name: "id",`)
+	assert.MatchRegex(t, src, `in:\s+"header",\s+name: "id",`)
+	// Each parameter is extracted in its own block.
+	assert.MatchRegex(t, src, `\{\s+var id string`)
+	assert.Equal(t, strings.Contains(src, "func GetPetIDPath(c echo.Context)"), true)
+	assert.Equal(t, strings.Contains(src, "func GetPetIDHeader(c echo.Context)"), true)
+	assert.Equal(t, strings.Contains(src, "func GetID("), false)
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "requestBody": {"content": {"application/json": {"schema": {"type": "string"}}}},
+        "responses": {"204": {"description": "ok"}}
+      }
+    },
+    "/uploads": {
+      "post": {
+        "operationId": "upload",
+        "x-repose": {"maxBodyBytes": 1048576},
+        "requestBody": {"content": {"application/json": {"schema": {"type": "string"}}}},
+        "responses": {"204": {"description": "ok"}}
+      }
+    }
+  }
+}`, &Echo{}, "server", map[string]interface{}{
+		"maxBodyBytes": int64(1024),
+	}))
+
+	assert.Equal(t, strings.Count(src, "http.StatusRequestEntityTooLarge"), 4)
+	assert.MatchRegex(t, src, `http\.MaxBytesReader\(c\.Response\(\), c\.Request\(\)\.Body, 1024\)`)
+	assert.MatchRegex(t, src, `http\.MaxBytesReader\(c\.Response\(\), c\.Request\(\)\.Body, 1048576\)`)
+	assert.Equal(t, strings.Count(src, `err != nil && strings.Contains(err.Error(), "http: request body too large")`), 2)
+}
+
+func TestMaxBodyBytesBindError(t *testing.T) {
+	// The generated handlers rely on the message of the error
+	// returned by Echo's binder for bodies over the limit.
+	req := httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`"`+strings.Repeat("a", 2048)+`"`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.ContentLength = -1
+
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, 1024)
+
+	var body string
+	err := c.Bind(&body)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), "http: request body too large"), true)
+}
+
+func TestServerErrorHandler(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"serverErrorHandler": true,
+		"generateHandlers":   true,
+	}))
+
+	assert.MatchRegex(t, src, `type ServerErrorHandler interface \{\s+HandleError\(c echo\.Context, err error\) error\s+\}`)
+	assert.Equal(t, strings.Count(src, "errorHandler, _ := server.(ServerErrorHandler)"), 2)
+	assert.MatchRegex(t, src, `if errorHandler != nil \{\s+return errorHandler\.HandleError\(c, err\)\s+\}\s+return err`)
+}
+
+func TestUnwiredResponses(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "200": {
+            "description": "ok",
+            "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}
+          },
+          "204": {"description": "ok"},
+          "default": {
+            "description": "error",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Error": {"type": "object", "properties": {"message": {"type": "string"}}}
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.MatchRegex(t, src, `// WARNING: the following responses of ListPets are not implemented`)
+	assert.MatchRegex(t, src, `//   - default \(application/json\): default responses are not supported`)
+	assert.MatchRegex(t, src, `//   - 200 \(application/json\): inline schemas are not supported`)
+	// The supported empty response is implemented.
+	assert.Equal(t, strings.Contains(src, "204 ("), false)
+}
+
+func TestEmptyResponseNames(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "delete": {
+        "operationId": "deletePets",
+        "responses": {
+          "204": {"description": "ok"},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    },
+    "/owners": {
+      "delete": {
+        "operationId": "deleteOwners",
+        "responses": {
+          "204": {"description": "ok"},
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "NotFound": {"description": "not found", "x-repose": {"name": "notFound"}}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, data, &Echo{}, "server", nil))
+
+	assert.Equal(t, strings.Count(src, "const DeletePetsResponse204 resDeletePetsResponse204"), 1)
+	assert.Equal(t, strings.Count(src, "const DeleteOwnersResponse204 resDeleteOwnersResponse204"), 1)
+	assert.Equal(t, strings.Count(src, "const DeletePetsNotFound resDeletePetsNotFound"), 1)
+	assert.Equal(t, strings.Count(src, "const DeleteOwnersNotFound resDeleteOwnersNotFound"), 1)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"scopeEmptyResponses": true,
+	}))
+	assert.Equal(t, strings.Count(src, "const DeletePetResponse204 resDeletePetResponse204"), 1)
+}
+
+func TestStatusResponses(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "put": {
+        "operationId": "putPet",
+        "responses": {
+          "200": {
+            "description": "Updated",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+          },
+          "201": {
+            "description": "Created",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, spec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Count(src, "func (_r *Pet) PutPetHandlerResponse(ctx echo.Context) error"), 1)
+	assert.Equal(t, strings.Contains(src, "PutPetPetWithStatus"), false)
+
+	src = string(generateTestFile(t, spec, &Echo{}, "server", map[string]interface{}{
+		"statusResponses": true,
+	}))
+	assert.MatchRegex(t, src, `func PutPetPetWithStatus\(status int, body \*Pet\) PutPetHandlerResponse`)
+	assert.MatchRegex(t, src, `case 200, 201:`)
+	assert.MatchRegex(t, src, `ctx\.JSON\(response\.status, _r\)`)
+}
+
+func TestContentTypeBodies(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/Pet"}},
+            "application/xml": {"schema": {"$ref": "#/components/schemas/PetXML"}}
+          }
+        },
+        "responses": {"204": {"description": "Created"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "PetXML": {"type": "object", "properties": {"petName": {"type": "string"}}}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, spec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, "bodyXML"), false)
+
+	src = string(generateTransformedTestFile(t, spec, map[string]interface{}{
+		"contentTypeBodies": true,
+	}, &Echo{}, "server", nil))
+	assert.MatchRegex(t, src, `CreatePet\(c echo\.Context, bodyJSON \*Pet, bodyXML \*PetXML\)`)
+	assert.Equal(t, strings.Contains(src, "http.StatusUnsupportedMediaType"), true)
+	assert.Equal(t, strings.Contains(src, `!strings.EqualFold(strings.TrimSpace(strings.Split(c.Request().Header.Get("Content-Type"), ";")[0]), "application/xml")`), true)
+
+	src = string(generateTransformedTestFile(t, spec, map[string]interface{}{
+		"contentTypeBodies": true,
+	}, &StdLib{}, "client", nil))
+	assert.MatchRegex(t, src, `if bodyXML != nil \{\s+_req\.Header\.Set\("Content-Type", "application/xml"\)`)
+}
+
+func TestHeadResponses(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "head": {
+        "operationId": "checkPet",
+        "responses": {
+          "200": {
+            "description": "Exists",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+          }
+        }
+      },
+      "get": {
+        "operationId": "getPet",
+        "responses": {
+          "200": {
+            "description": "Found",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`, &Echo{}, "server", nil))
+
+	assert.MatchRegex(t, src, `\) CheckPetHandlerResponse\(ctx echo\.Context\) error \{\s+ctx\.Response\(\)\.Header\(\)\.Set\("Content-Type", "application/json"\)\s+return ctx\.NoContent\(200\)\s+\}`)
+	assert.MatchRegex(t, src, `\) GetPetHandlerResponse\(ctx echo\.Context\) error \{[^}]+\}[^}]+ctx\.JSON\(200, _r\)`)
+}
+
+func TestUseValidator(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+        },
+        "responses": {"204": {"description": "Added"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, spec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, "c.Validate("), false)
+
+	src = string(generateTestFile(t, spec, &Echo{}, "server", map[string]interface{}{
+		"useValidator": true,
+	}))
+	assert.MatchRegex(t, src, `if err := c\.Validate\(&?body\); err != nil \{\s+return echo\.NewHTTPError\(http\.StatusBadRequest, err\.Error\(\)\)`)
+}
+
+func TestValidateResponses(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "validator", nil))
+	assert.Equal(t, strings.Contains(src, "validatorResponseRecorder"), false)
+
+	specSrc := generateTestFile(t, basePathTestSpec, &General{}, "spec", nil)
+	validatorSrc := generateTestFile(t, basePathTestSpec, &Echo{}, "validator", map[string]interface{}{
+		"validateResponses": true,
+	})
+	src = string(validatorSrc)
+
+	// Responses written before an error is returned are not dropped.
+	assert.MatchRegex(t, src, `err = next\(c\)\s+res\.Writer = writer\s+if !res\.Committed \{\s+return err\s+\}`)
+	assert.MatchRegex(t, src, `if _, writeErr := writer\.Write\(rec\.body\.Bytes\(\)\); writeErr != nil && err == nil \{\s+err = writeErr\s+\}\s+return err`)
+
+	// Echo's Response.Flush requires an http.Flusher.
+	assert.Equal(t, strings.Contains(src, "func (r *validatorResponseRecorder) Flush() {}"), true)
+
+	typeCheck(t, specSrc, validatorSrc)
+}
+
+func TestValidatorSpecFuncName(t *testing.T) {
+	_, err := generateTestCode(t, basePathTestSpec, &General{}, "spec", map[string]interface{}{
+		"specFuncName": "api-spec",
+	})
+	assert.NotEqual(t, err, nil)
+
+	generalOpts := map[string]interface{}{"specFuncName": "apiSpec"}
+
+	specSrc := generateTestFile(t, basePathTestSpec, &General{}, "spec", generalOpts)
+	assert.Equal(t, strings.Contains(string(specSrc), "func apiSpec()"), true)
+
+	// The validator uses the name from the options of the general generator.
+	generateValidator := func(generalOpts map[string]interface{}) (interface{}, error) {
+		ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+		ctx = context.WithValue(ctx, common.ContextGeneratorOptions, map[string]interface{}{
+			(&General{}).Name(): generalOpts,
+			(&Echo{}).Name():    nil,
+		})
+
+		sp, err := (&reposeparser.OpenAPI3{}).Parse(ctx, nil, []byte(basePathTestSpec))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = (&transformer.Default{}).Transform(ctx, nil, sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return (&Echo{}).Generate(ctx, nil, sp, "validator")
+	}
+
+	out, err := generateValidator(generalOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := jen.NewFile("types")
+	f.Add(out.(jen.Code))
+	validatorSrc := []byte(f.GoString())
+
+	assert.Equal(t, strings.Contains(string(validatorSrc), "apiSpec()"), true)
+	typeCheck(t, specSrc, validatorSrc)
+
+	_, err = generateValidator(map[string]interface{}{"specFuncName": "api-spec"})
+	assert.NotEqual(t, err, nil)
+}
+
+func TestRequestID(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, "RequestIDMiddleware"), false)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"generateRequestID": true,
+		"requestIDHeader":   "X-Correlation-ID",
+	}))
+	assert.Equal(t, strings.Contains(src, `const RequestIDHeader = "X-Correlation-ID"`), true)
+	assert.Equal(t, strings.Contains(src, "func RequestID(c echo.Context) string"), true)
+	assert.Equal(t, strings.Contains(src, "func RequestIDFromContext(ctx context.Context) string"), true)
+	assert.Equal(t, strings.Contains(src, "append([]echo.MiddlewareFunc{RequestIDMiddleware}, middleware.DeletePet...)..."), true)
+
+	src = string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"generateRequestID": true,
+		"serverMiddleware":  false,
+	}))
+	assert.MatchRegex(t, src, `e\.Add\("DELETE", "/api/v1/pets/:id", func\(c echo\.Context\) error \{[\s\S]*\}, RequestIDMiddleware\)`)
+
+	_, err := generateTestCode(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"generateRequestID": true,
+		"requestIDHeader":   " ",
+	})
+	assert.NotEqual(t, err, nil)
+}
+
+func TestSetOperationContextKey(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, "c.Set("), false)
+
+	serverSrc := generateTestFile(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"setOperationContextKey": "operation",
+	})
+	src = string(serverSrc)
+
+	// The name is stored before the parameters are extracted.
+	assert.MatchRegex(t, src, `c\.Set\("operation", "DeletePet"\)[\s\S]*c\.Param\("id"\)`)
+
+	typeCheck(t, serverSrc)
+}
+
+func TestServerFake(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "findPets",
+        "responses": {
+          "200": {"description": "Pets", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pets"}}}}
+        }
+      },
+      "post": {
+        "operationId": "addPet",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewPet"}}}
+        },
+        "responses": {
+          "200": {"description": "Pet", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+        }
+      }
+    },
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "format": "int64"}}],
+      "get": {
+        "operationId": "findPetById",
+        "responses": {
+          "200": {"description": "Pet", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}
+        }
+      },
+      "delete": {
+        "operationId": "deletePet",
+        "responses": {"204": {"description": "Deleted"}}
+      },
+      "patch": {
+        "operationId": "feedPet",
+        "responses": {"204": {"description": "Fed"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "NewPet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Pet": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer", "format": "int64"}, "name": {"type": "string"}}},
+      "Pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	serverSrc := generateTestFile(t, spec, &Echo{}, "server", nil)
+	fakeSrc := generateTestFile(t, spec, &Echo{}, "server-fake", nil)
+	src := string(fakeSrc)
+
+	assert.Equal(t, strings.Contains(src, "type ServerFake struct"), true)
+	assert.Equal(t, strings.Contains(src, "func NewServerFake() *ServerFake"), true)
+	assert.MatchRegex(t, src, `petItems\s+map\[string\]Pet`)
+	assert.Equal(t, strings.Contains(src, "json.Unmarshal(_data, &_item)"), true)
+	assert.Equal(t, strings.Contains(src, "_item.ID = int64(f.nextID)"), true)
+	assert.Equal(t, strings.Contains(src, `f.petItems[c.Param("id")]`), true)
+	assert.Equal(t, strings.Contains(src, `delete(f.petItems, c.Param("id"))`), true)
+	assert.Equal(t, strings.Contains(src, "make(Pets, 0, len(_keys))"), true)
+	assert.Equal(t, strings.Contains(src, "echo.NewHTTPError(http.StatusNotImplemented)"), true)
+
+	typeCheck(t, typesSrc, serverSrc, fakeSrc)
+}
+
+func TestScaffoldTypesPackage(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "findPets",
+        "parameters": [{"name": "kind", "in": "query", "schema": {"$ref": "#/components/schemas/Kind"}}],
+        "responses": {"204": {"description": "ok"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Kind": {"type": "string"}
+    }
+  }
+}`, &Echo{}, "server-scaffold", map[string]interface{}{
+		"serverPackagePath": "example.com/api/server",
+		"typesPackagePath":  "example.com/api/types",
+	}))
+
+	// Named parameter types are in the types package, like in the server interface.
+	assert.MatchRegex(t, src, `FindPets\(c echo\.Context, kind \*types\.Kind\) \(server\.FindPetsHandlerResponse, error\)`)
+}
+
+func TestInvalidPackagePaths(t *testing.T) {
+	for _, path := range []string{"api/types", "./types", "/home/user/api/types", "example.com//types"} {
+		_, err := generateTestCode(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+			"typesPackagePath": path,
+		})
+		assert.NotEqual(t, err, nil)
+	}
+
+	_, err := generateTestCode(t, basePathTestSpec, &Echo{}, "server", map[string]interface{}{
+		"typesPackagePath": "example.com/api/types/",
+	})
+	assert.Equal(t, err, nil)
+}
+
+func TestRouteTest(t *testing.T) {
+	serverSrc := generateTestFile(t, basePathTestSpec, &Echo{}, "server", nil)
+	routeTestSrc := generateTestFile(t, basePathTestSpec, &Echo{}, "server-route-test", nil)
+	src := string(routeTestSrc)
+
+	assert.MatchRegex(t, src, `type serverRouteTest struct \{\s+Server\s+\}`)
+	assert.Equal(t, strings.Contains(src, "func (s *serverRouteTest) Middleware() *ServerMiddleware"), true)
+	assert.Equal(t, strings.Contains(src, "func TestServerRoutes(t *testing.T)"), true)
+	assert.Equal(t, strings.Contains(src, "RegisterEchoServer(e, &serverRouteTest{})"), true)
+	assert.Equal(t, strings.Contains(src, `{"DELETE", "/api/v1/pets/1"}`), true)
+	assert.Equal(t, strings.Contains(src, "rec.Code == http.StatusMethodNotAllowed"), true)
+
+	typeCheck(t, serverSrc, routeTestSrc)
+}
+
+func TestStdlibResponsesReceiver(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/widget": {
+      "get": {
+        "operationId": "getWidget",
+        "responses": {
+          "200": {
+            "description": "Widget",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}
+          }
+        }
+      },
+      "put": {
+        "operationId": "putWidget",
+        "responses": {
+          "200": {
+            "description": "Updated",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}
+          },
+          "201": {
+            "description": "Created",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Widget": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	opts := map[string]interface{}{
+		"stdlibResponses": true,
+		"statusResponses": true,
+	}
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	serverSrc := generateTestFile(t, spec, &Echo{}, "server", opts)
+	src := string(serverSrc)
+
+	// The response value must not shadow the http.ResponseWriter.
+	assert.MatchRegex(t, src, `func \(_r \*Widget\) WriteGetWidgetResponse\(w http\.ResponseWriter\) error`)
+	assert.Equal(t, strings.Contains(src, "_r := response.body"), true)
+
+	typeCheck(t, typesSrc, serverSrc)
+}
+
+func TestStdlibResponsesHeaders(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/widgets": {
+      "get": {
+        "operationId": "listWidgets",
+        "responses": {
+          "200": {
+            "description": "Widgets",
+            "headers": {
+              "X-Total-Count": {"required": true, "schema": {"type": "integer"}},
+              "X-Request-Id": {"required": true, "schema": {"type": "string"}}
+            },
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Widget"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Widget": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	serverSrc := generateTestFile(t, spec, &Echo{}, "server", map[string]interface{}{
+		"stdlibResponses": true,
+	})
+	src := string(serverSrc)
+
+	assert.Equal(t, strings.Count(src, `header.Set("X-Total-Count"`), 1)
+	assert.Equal(t, strings.Count(src, `header.Set("X-Request-Id"`), 1)
+
+	// Every header is set in its own statement.
+	for _, line := range strings.Split(src, "\n") {
+		assert.Equal(t, strings.Count(line, "header.Set(") <= 1, true)
+	}
+
+	typeCheck(t, typesSrc, serverSrc)
+}
+
+func TestCallbacksServerRoutes(t *testing.T) {
+	callbacksSpec := func(addedURL, removedURL string) string {
+		return `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/subscriptions": {
+      "post": {
+        "operationId": "subscribe",
+        "responses": {"204": {"description": "Subscribed"}},
+        "callbacks": {
+          "onPetAdded": {
+            "` + addedURL + `": {
+              "post": {"operationId": "petAdded", "responses": {"204": {"description": "Received"}}}
+            }
+          },
+          "onPetRemoved": {
+            "` + removedURL + `": {
+              "post": {"operationId": "petRemoved", "responses": {"204": {"description": "Received"}}}
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+	}
+
+	src := string(generateTestFile(t,
+		callbacksSpec("{$request.body#/addedUrl}", "{$request.body#/removedUrl}"),
+		&Echo{}, "callbacks-server", nil,
+	))
+	assert.Equal(t, strings.Contains(src, `"/onPetAdded"`), true)
+	assert.Equal(t, strings.Contains(src, `"/onPetRemoved"`), true)
+
+	_, err := generateTestCode(t,
+		callbacksSpec("{$request.body#/url}/pets", "{$request.body#/otherUrl}/pets"),
+		&Echo{}, "callbacks-server", nil,
+	)
+	if err == nil {
+		t.Fatal("expected an error for callbacks with the same route")
+	}
+
+	// Callbacks shared by multiple operations are handled once.
+	srcB := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/subscriptions": {
+      "post": {
+        "operationId": "subscribe",
+        "responses": {"204": {"description": "Subscribed"}},
+        "callbacks": {"onPetEvent": {"$ref": "#/components/callbacks/PetEvent"}}
+      },
+      "put": {
+        "operationId": "resubscribe",
+        "responses": {"204": {"description": "Subscribed"}},
+        "callbacks": {"onPetEvent": {"$ref": "#/components/callbacks/PetEvent"}}
+      }
+    }
+  },
+  "components": {
+    "callbacks": {
+      "PetEvent": {
+        "{$request.body#/url}/events": {
+          "post": {"operationId": "petEvent", "responses": {"204": {"description": "Received"}}}
+        }
+      }
+    }
+  }
+}`, &Echo{}, "callbacks-server", nil)
+	src = string(srcB)
+
+	assert.Equal(t, strings.Count(src, `e.Add("POST", "/events"`), 1)
+	assert.MatchRegex(t, src, `PetEvent\(`)
+
+	typeCheck(t, srcB)
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"go/token"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -27,13 +31,44 @@ import (
 
 // GeneralOptions are options the General target.
 type GeneralOptions struct {
-	GenerateTypeHelpers       bool   `yaml:"generateTypeHelpers" description:"Generate helper functions and methods for types"`
-	GenerateGettersAndSetters bool   `yaml:"generateGettersAndSetters" description:"Generate helper methods for getting and setting properties for maps or structs with unknown names (E.g. additional properties)"`
-	GenerateMarshalMethods    bool   `yaml:"generateMarshalMethods" description:"Generate marshal/unmarshal methods for types that need them"`
-	TypesPackagePath          string `yaml:"typesPackagePath,omitempty" description:"Package path to already generated types (used internally)"`
-	ExpandEnums               bool   `yaml:"expandEnums" description:"Expand enums into const (...) blocks if possible"`
+	GenerateTypeHelpers       bool              `yaml:"generateTypeHelpers" description:"Generate helper functions and methods for types"`
+	GenerateGettersAndSetters bool              `yaml:"generateGettersAndSetters" description:"Generate helper methods for getting and setting properties for maps or structs with unknown names (E.g. additional properties)"`
+	GenerateMarshalMethods    bool              `yaml:"generateMarshalMethods" description:"Generate marshal/unmarshal methods for types that need them"`
+	TypesPackagePath          string            `yaml:"typesPackagePath,omitempty" description:"Package path to already generated types (used internally)"`
+	ExpandEnums               bool              `yaml:"expandEnums" description:"Expand enums into const (...) blocks if possible"`
+	EnumNaming                string            `yaml:"enumNaming" description:"Naming of expanded enum values, one of typePrefix (StatusActive), valueOnly (Active), screaming (ACTIVE), or a Go template with .TypeName, .Value and .Name (the value in camel case), values starting with a digit are prefixed with the type name for valueOnly and screaming"`
+	ErrorEnumPrefix           bool              `yaml:"errorEnumPrefix" description:"Prefix the enum values of schemas with \"error\" in their names with Err instead of the type name, only used with typePrefix naming"`
+	ErrorSchemas              []string          `yaml:"errorSchemas,omitempty" description:"Names of the schemas that implement error in the errors target, if empty, response schemas with \"error\" in their names are used"`
+	PrimitiveParsers          map[string]string `yaml:"primitiveParsers,omitempty" description:"Functions with the signature func(string) (T, error) for parsing custom primitive types from parameters, keyed by the type, both with full package paths (e.g. github.com/google/uuid.UUID: github.com/google/uuid.Parse)"`
+	GenerateValidation        bool              `yaml:"generateValidation" description:"Generate Validate methods for struct types that check the constraints of the schema, such as string formats"`
+	UseGenerics               bool              `yaml:"useGenerics" description:"Generate generic OneOfN union types for anyOf and oneOf schemas with a limited number of children instead of interface{} (requires Go 1.18+)"`
+	GenerateBuilders          bool              `yaml:"generateBuilders" description:"Generate New constructors and fluent WithField setters returning the struct pointer for struct types"`
+	FixedArrays               bool              `yaml:"fixedArrays" description:"Generate fixed-size Go arrays for array schemas where minItems equals maxItems"`
+	SpecFuncName              string            `yaml:"specFuncName" description:"Name of the function that returns the specification in the spec target, it is unexported if it starts with a lowercase letter"`
+	StrongEnums               bool              `yaml:"strongEnums" description:"Generate MarshalJSON and UnmarshalJSON methods for enum types that map between the constants and the wire values explicitly, and reject values that are not in the enum, requires expandEnums"`
+	AllOfNamedFields          bool              `yaml:"allOfNamedFields" description:"Generate named fields for the schemas of allOf instead of embedding them, the fields are merged into a single JSON object by generated MarshalJSON and UnmarshalJSON methods"`
+	RedactSensitive           bool              `yaml:"redactSensitive" description:"Generate String and GoString methods for struct types with sensitive fields (password format or the sensitive extension) that redact their values, so that they are not leaked into logs"`
 }
 
+// Enum naming strategies.
+const (
+	EnumNamingTypePrefix = "typePrefix"
+	EnumNamingValueOnly  = "valueOnly"
+	EnumNamingScreaming  = "screaming"
+)
+
+// EnumNameTemplateValues contains values for enum naming templates.
+type EnumNameTemplateValues struct {
+	TypeName string `description:"Name of the enum type"`
+	Value    string `description:"The enum value as it is in the specification"`
+	Name     string `description:"The enum value in camel case"`
+}
+
+// maxGenericUnionArity is the maximum number of
+// children an anyOf or oneOf schema can have
+// to be generated as a generic union type.
+const maxGenericUnionArity = 8
+
 // MarshalYAML implements YAML Marshaler
 func (g *GeneralOptions) MarshalYAML() (interface{}, error) {
 	return util.MarshalYAMLWithDescriptions(g)
@@ -51,6 +86,15 @@ func (g *General) Generate(ctx context.Context, options interface{}, specificati
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	opts.TypesPackagePath, err = gen.NormalizeImportPath(opts.TypesPackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: typesPackagePath: %w", err)
+	}
+
+	if !token.IsIdentifier(opts.SpecFuncName) {
+		return nil, fmt.Errorf("invalid options: specFuncName: %q is not a valid Go identifier", opts.SpecFuncName)
+	}
+
 	switch target {
 	case "type", "types":
 		return g.GenerateTypes(ctx, specification, opts)
@@ -60,7 +104,13 @@ func (g *General) Generate(ctx context.Context, options interface{}, specificati
 			return nil, fmt.Errorf("specification data not supplied")
 		}
 
-		return g.GenerateSpec(ctx, state.SpecData(), "APISpecification")
+		return g.GenerateSpec(ctx, state.SpecData(), opts.SpecFuncName)
+	case "errors":
+		return g.GenerateErrors(ctx, specification, opts)
+	case "registry":
+		return g.GenerateRegistry(ctx, specification, opts)
+	case "routes":
+		return g.GenerateRoutes(ctx, specification, opts)
 	default:
 		return nil, fmt.Errorf("target %v is not supported", target)
 	}
@@ -73,6 +123,16 @@ func (g *General) DefaultOptions() interface{} {
 		GenerateGettersAndSetters: true,
 		GenerateMarshalMethods:    true,
 		ExpandEnums:               true,
+		EnumNaming:                EnumNamingTypePrefix,
+		ErrorEnumPrefix:           false,
+		GenerateValidation:        false,
+		UseGenerics:               false,
+		FixedArrays:               false,
+		GenerateBuilders:          false,
+		SpecFuncName:              "APISpecification",
+		RedactSensitive:           false,
+		StrongEnums:               false,
+		AllOfNamedFields:          false,
 	}
 }
 
@@ -89,8 +149,19 @@ func (g *General) Description() string {
 // Targets implements Generator
 func (g *General) Targets() map[string]string {
 	return map[string]string{
-		"types": "Go types for the schemas in the specification",
-		"spec":  "The bytes of the parsed specification file",
+		"types":    "Go types for the schemas in the specification",
+		"spec":     "The bytes of the parsed specification file",
+		"errors":   "Error methods for the error response schemas, must be in the same package as the types",
+		"registry": "Lookup of the Go types of the request bodies and responses by operation ID for reflection-based tooling",
+		"routes":   "Constants for the path template and the method of each operation (e.g. FindPetByIDPath and FindPetByIDMethod)",
+	}
+}
+
+// TargetAliases implements TargetAliases
+func (g *General) TargetAliases() map[string][]string {
+	return map[string][]string{
+		"types": {"type"},
+		"spec":  {"specification"},
 	}
 }
 
@@ -111,6 +182,10 @@ This generator generates framework-agnostic Go code.
 
 {{ .OptionsExample }}
 
+### Enum naming template values
+
+{{ .EnumNamingTable }}
+
 # Targets
 
 {{ .TargetsTable }}
@@ -135,7 +210,8 @@ This generator generates framework-agnostic Go code.
 					"go-general": g.DefaultOptions(),
 				},
 			)) + "```\n",
-			"TargetsTable": markdown.TargetsTable(g.Targets()),
+			"TargetsTable":    markdown.TargetsTable(g.Targets()),
+			"EnumNamingTable": markdown.TagsTable(EnumNameTemplateValues{}),
 		},
 	)
 	if err != nil {
@@ -162,15 +238,67 @@ func (g *General) GenerateTypes(ctx context.Context, specification *spec.Spec, o
 		opts = o
 	}
 
+	// Types without a name are named after their structure,
+	// so that neither their names nor their order depend on the order of the schemas.
+	unnamedNames := make(map[*spec.Schema]string)
+	for _, schema := range specification.Schemas {
+		if !schema.Create || schema.Alias || schema.Name != "" {
+			continue
+		}
+
+		sCode, err := g.GenerateType(ctx, schema, opts)
+		if err != nil {
+			return nil, err
+		}
+		unnamedNames[schema] = unnamedTypeName(sCode, schema.Description)
+	}
+
+	typeName := func(schema *spec.Schema) string {
+		if schema.Name == "" {
+			return unnamedNames[schema]
+		}
+		return schema.Name
+	}
+
 	// Sort schemas in alphabetical order
 	sort.Slice(specification.Schemas, func(i, j int) bool {
 		sc1, sc2 := specification.Schemas[i], specification.Schemas[j]
 
-		return sc1.Name < sc2.Name
+		return typeName(sc1) < typeName(sc2)
 	})
 
+	// Types with the same structure are numbered.
+	usedUnnamed := make(map[string]bool, len(unnamedNames))
+	for _, schema := range specification.Schemas {
+		base, ok := unnamedNames[schema]
+		if !ok {
+			continue
+		}
+
+		name := base
+		for i := 2; usedUnnamed[name]; i++ {
+			name = base + "_" + strconv.Itoa(i)
+		}
+		usedUnnamed[name] = true
+		unnamedNames[schema] = name
+	}
+
+	// Names of all the types, so that
+	// the generated functions do not collide with them.
+	typeNames := make(map[string]bool, len(specification.Schemas))
+	schemasByName := make(map[string]*spec.Schema, len(specification.Schemas))
+	for _, schema := range specification.Schemas {
+		typeNames[schema.Name] = true
+		if schema.Create && schema.Name != "" {
+			schemasByName[schema.Name] = schema
+		}
+	}
+
+	// Names of the enum constants by the names of their types.
+	enumConsts := make(map[string]string)
+
 	code := jen.Null()
-	idx := 0
+
 	for _, schema := range specification.Schemas {
 
 		if !schema.Create {
@@ -211,12 +339,16 @@ func (g *General) GenerateTypes(ctx context.Context, specification *spec.Spec, o
 			continue
 		}
 
+		// Generic union types are aliased,
+		// otherwise the methods of the union type would be lost.
+		genericUnion := g.genericUnionArity(schema, opts) > 0
+
 		sCode, err := g.GenerateType(ctx, schema, opts)
 		if err != nil {
 			return nil, err
 		}
 
-		name := schema.Name
+		name := typeName(schema)
 
 		if options.Comments {
 			comms := make([]string, 0, len(schema.Comments)+1)
@@ -253,13 +385,12 @@ func (g *General) GenerateTypes(ctx context.Context, specification *spec.Spec, o
 			}
 		}
 
-		if name == "" {
-			name = "UnnamedType" + strconv.Itoa(idx)
-			idx++
-		}
-
 		code.Type().Id(name)
 
+		if genericUnion {
+			code.Op("=")
+		}
+
 		code.Add(sCode).Line().Line()
 
 		helperCode, err := g.GenerateHelpers(ctx, schema, opts)
@@ -268,46 +399,125 @@ func (g *General) GenerateTypes(ctx context.Context, specification *spec.Spec, o
 		}
 		code.Add(helperCode)
 
+		if len(schema.Tuple) > 0 {
+			code.Add(g.GenerateTupleMarshalMethods(ctx, schema))
+		}
+
+		if opts.GenerateMarshalMethods && schema.Variant == spec.VariantStruct &&
+			schema.Name != "" && schema.AdditionalProps == nil {
+			unionCode, err := g.GenerateUnionFieldsUnmarshal(ctx, schema, opts)
+			if err != nil {
+				return nil, err
+			}
+			code.Add(unionCode)
+		}
+
+		if opts.AllOfNamedFields && schema.Variant == spec.VariantAllOf && schema.Name != "" {
+			code.Add(g.GenerateAllOfMarshalMethods(ctx, schema))
+		} else if schema.Variant == spec.VariantAllOf && g.hasUnionFieldsUnmarshal(schema, schemasByName, opts) {
+			code.Add(g.GenerateAllOfUnmarshal(ctx, schema))
+		}
+
+		if item := g.polymorphicArrayItem(schema, opts); item != nil {
+			arrayCode, err := g.GeneratePolymorphicArrayMethods(ctx, schema, item, opts)
+			if err != nil {
+				return nil, err
+			}
+			code.Add(arrayCode)
+		}
+
+		if opts.GenerateBuilders && schema.Variant == spec.VariantStruct && schema.Name != "" {
+			builderCode, err := g.GenerateBuilders(ctx, schema, typeNames, opts)
+			if err != nil {
+				return nil, err
+			}
+			code.Add(builderCode)
+		}
+
+		if opts.GenerateValidation && schema.Variant == spec.VariantStruct && schema.Name != "" {
+			validateCode, err := g.GenerateValidate(ctx, schema, opts)
+			if err != nil {
+				return nil, err
+			}
+			code.Add(validateCode)
+		}
+
+		if opts.RedactSensitive && schema.Variant == spec.VariantStruct && schema.Name != "" {
+			redactCode, err := g.GenerateRedactedStringers(ctx, schema, opts)
+			if err != nil {
+				return nil, err
+			}
+			code.Add(redactCode)
+		}
+
 		if opts.ExpandEnums && len(schema.Enum) > 0 {
 			enumCode := jen.Null()
 
 			if options.Comments {
 				enumCode.Commentf("// Enum values for %v ", schema.Name).Line()
+			}
 
-				defs := make([]jen.Code, 0, len(schema.Enum))
+			defs := make([]jen.Code, 0, len(schema.Enum))
+			constNames := make([]string, 0, len(schema.Enum))
 
-				for _, e := range schema.Enum {
-					eName := fmt.Sprint(e)
+			for i, e := range schema.Enum {
+				var varName string
+				if len(schema.EnumNames) == len(schema.Enum) {
+					varName = schema.EnumNames[i]
+				}
 
-					if strings.Contains(eName, "_") {
-						eName = strings.Title(
-							strings.ToLower(
-								strings.Replace(fmt.Sprint(e), "_", " ", -1),
-							),
-						)
-					}
+				eName, err := g.enumConstName(ctx, schema, e, varName, opts)
+				if err != nil {
+					return nil, err
+				}
 
-					eName = util.ToGoName(strcase.ToCamel(eName))
+				// Constants that are not prefixed with the type name
+				// can collide with the ones of other enums or with types.
+				if other, ok := enumConsts[eName]; ok {
+					return nil, fmt.Errorf("enum value %v of %v: the constant %v is already defined for %v", e, schema.Name, eName, other)
+				}
+				if typeNames[eName] {
+					return nil, fmt.Errorf("enum value %v of %v: the constant %v collides with a type", e, schema.Name, eName)
+				}
+				enumConsts[eName] = schema.Name
 
-					if strings.Contains(strings.ToLower(schema.Name), "error") {
-						if !strings.HasPrefix(strings.ToLower(eName), "err") {
-							eName = "Err" + eName
-						}
-					} else {
-						eName = schema.Name + eName
-					}
+				defs = append(defs, jen.Id(eName).Id(schema.Name).Op("=").Lit(enumValue(schema, e)))
+				constNames = append(constNames, eName)
+			}
 
-					defs = append(defs, jen.Id(eName).Id(schema.Name).Op("=").Lit(e))
-				}
+			enumCode.Const().Defs(
+				defs...,
+			).Line().Line()
 
-				enumCode.Const().Defs(
-					defs...,
-				).Line().Line()
+			code.Add(enumCode)
+
+			if opts.StrongEnums && isStrongEnumType(schema) {
+				code.Add(g.GenerateEnumMarshalers(ctx, schema, constNames))
+			}
+		}
+
+	}
+
+	if opts.GenerateValidation {
+		code.Add(g.GenerateValidationHelpers(ctx))
+	}
+
+	if opts.UseGenerics {
+		arities := make(map[int]bool)
+		visited := make(map[*spec.Schema]bool)
 
-				code.Add(enumCode)
+		for _, schema := range specification.Schemas {
+			if !schema.Create {
+				continue
 			}
+			g.collectGenericUnionArities(schema, opts, arities, visited)
 		}
 
+		for arity := 2; arity <= maxGenericUnionArity; arity++ {
+			if arities[arity] {
+				code.Add(g.GenerateGenericUnion(ctx, arity))
+			}
+		}
 	}
 
 	return code, nil
@@ -348,6 +558,27 @@ func (g *General) GenerateType(ctx context.Context, schema *spec.Schema, opts *G
 		spec.VariantOneOf,
 		spec.VariantAny:
 
+		if arity := g.genericUnionArity(schema, opts); arity > 0 {
+			typeArgs := make([]jen.Code, 0, arity)
+
+			for _, child := range schema.Children.GetArray() {
+				childC := jen.Null()
+
+				if (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil() {
+					childC.Op("*")
+				}
+
+				c, err := g.GenerateType(ctx, child, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				typeArgs = append(typeArgs, childC.Add(c))
+			}
+
+			return gen.Qual(opts.TypesPackagePath, genericUnionName(arity)).Index(jen.List(typeArgs...)), nil
+		}
+
 		return jen.Interface(), nil
 
 	case spec.VariantAllOf:
@@ -356,12 +587,39 @@ func (g *General) GenerateType(ctx context.Context, schema *spec.Schema, opts *G
 			if child.Name == "" {
 				return nil, fmt.Errorf("Empty schema name in AllOf")
 			}
+
+			// Named fields are only supported for named types,
+			// as the JSON methods cannot be generated otherwise.
+			if opts.AllOfNamedFields && schema.Name != "" {
+				fields = append(fields, jen.Id(allOfFieldName(child)).Id(child.Name))
+				continue
+			}
+
 			fields = append(fields, jen.Id(child.Name))
 		}
 
 		return jen.Struct(fields...), nil
 
 	case spec.VariantArray:
+		if len(schema.Tuple) > 0 {
+			fields := make([]jen.Code, 0, len(schema.Tuple))
+
+			for i, child := range schema.Tuple {
+				item, err := g.GenerateType(ctx, child, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				if (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil() {
+					item = jen.Op("*").Add(item)
+				}
+
+				fields = append(fields, jen.Id(tupleFieldName(i)).Add(item))
+			}
+
+			return jen.Struct(fields...), nil
+		}
+
 		item, err := g.GenerateType(ctx, schema.Children.Schema, opts)
 		if err != nil {
 			return nil, err
@@ -372,6 +630,10 @@ func (g *General) GenerateType(ctx context.Context, schema *spec.Schema, opts *G
 			item = jen.Op("*").Add(item)
 		}
 
+		if length, ok := fixedArrayLength(schema, opts); ok {
+			return jen.Index(jen.Lit(length)).Add(item), nil
+		}
+
 		return jen.Index().Add(item), nil
 
 	case spec.VariantStruct:
@@ -571,8 +833,9 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 
 	shortName := strings.ToLower(string(schema.Name[0]))
 
-	// Generate AnyOf/OneOf helper methods to cast the type.
-	if opts.GenerateTypeHelpers {
+	// Generate AnyOf/OneOf helper methods to cast the type,
+	// generic unions have their own typed accessors instead.
+	if opts.GenerateTypeHelpers && g.genericUnionArity(schema, opts) == 0 {
 		if schema.Name != "" &&
 			(schema.Variant == spec.VariantAnyOf ||
 				schema.Variant == spec.VariantOneOf) {
@@ -592,7 +855,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 					continue
 				}
 
-				childName = util.ToGoName(strcase.ToCamel(childName))
+				childName = common.Initialisms(ctx).ToGoName(strcase.ToCamel(childName))
 
 				cCode, err := g.GenerateType(ctx, c, opts)
 				if err != nil {
@@ -605,7 +868,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 					cCodeNullable = cCode
 				}
 
-				headerCode := jen.Func().Id(util.ToGoName(strcase.ToCamel(schema.Name + "As" + childName))).
+				headerCode := jen.Func().Id(common.Initialisms(ctx).ToGoName(strcase.ToCamel(schema.Name + "As" + childName))).
 					Params(jen.Id(shortName).Id(schema.Name)).Params(cCodeNullable)
 
 				bodyCode := jen.Null()
@@ -637,7 +900,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 
 				// Then we try to JSON marshal the value
 				bodyCode.List(jen.Id("b"), jen.Err()).Op(":=").
-					Add(g.jsonCall(false, "Marshal")).Call(jen.Id(shortName)).Line().Line()
+					Add(g.jsonCall("Marshal")).Call(jen.Id(shortName)).Line().Line()
 
 				// Check for marshal error
 				bodyCode.If(jen.Err().Op("!=").Nil()).Block(
@@ -650,7 +913,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 
 				// Create a strict decoder that
 				// won't allow unknown fields.
-				bodyCode.Id("d").Op(":=").Add(g.jsonCall(false, "NewDecoder")).
+				bodyCode.Id("d").Op(":=").Add(g.jsonCall("NewDecoder")).
 					Call(jen.Qual("bytes", "NewReader").Call(jen.Id("b"))).Line()
 				bodyCode.Id("d").Dot("DisallowUnknownFields").Call().Line().Line()
 
@@ -702,6 +965,11 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 
 			switch schema.Variant {
 			case spec.VariantMap:
+				keyType, err := g.GenerateType(ctx, schema.Children.Array[0], opts)
+				if err != nil {
+					return nil, err
+				}
+
 				setter := jen.Null()
 
 				if options.Comments {
@@ -713,7 +981,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 				setter.Func().
 					Params(jen.Id(shortName).Id(schema.Name)).
 					Id("Set").
-					Params(jen.Id("key").String(), jen.Id("value").Add(additionalType)).
+					Params(jen.Id("key").Add(keyType), jen.Id("value").Add(additionalType)).
 					Block(
 						jen.If(jen.Id(shortName).Op("==").Nil()).Block(
 							jen.Id(shortName).Op("=").Make(jen.Add(schemaType)),
@@ -733,7 +1001,7 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 				getter.Func().
 					Params(jen.Id(shortName).Id(schema.Name)).
 					Id("Get").
-					Params(jen.Id("key").String()).
+					Params(jen.Id("key").Add(keyType)).
 					Params(additionalType).
 					Block(
 						// If the map is nil
@@ -830,92 +1098,1840 @@ func (g *General) GenerateHelpers(ctx context.Context, schema *spec.Schema, opts
 	return code, nil
 }
 
-// GenerateSpec generates code that stores the
-// specifications in base64, and a function to decode them to a map of bytes.
-func (g *General) GenerateSpec(ctx context.Context, spBytes []byte, funcName string) (jen.Code, error) {
+// GenerateRegistry generates a lookup of the Go types
+// of the request bodies and responses of the operations.
+func (g *General) GenerateRegistry(ctx context.Context, specification *spec.Spec, opts *GeneralOptions) (jen.Code, error) {
 	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
 	if !ok {
 		options = common.DefaultOptions()
 	}
 
-	if spBytes == nil {
-		return nil, fmt.Errorf("no specification given")
+	typeOf := func(schema *spec.Schema) (jen.Code, error) {
+		var tp jen.Code
+		if schema.Name != "" {
+			tp = gen.Qual(opts.TypesPackagePath, schema.Name)
+		} else {
+			c, err := g.GenerateType(ctx, schema, opts)
+			if err != nil {
+				return nil, err
+			}
+			tp = c
+		}
+
+		return jen.Qual("reflect", "TypeOf").Call(jen.Parens(jen.Op("*").Add(tp)).Call(jen.Nil())).Dot("Elem").Call(), nil
 	}
 
-	var buf bytes.Buffer
+	operations := jen.Dict{}
 
-	zw := gzip.NewWriter(&buf)
-	_, err := zw.Write(spBytes)
-	if err != nil {
-		return nil, err
+	for _, p := range specification.Paths {
+		for _, o := range p.Operations {
+			id := o.ID
+			if id == "" {
+				id = o.Name
+			}
+
+			values := jen.Dict{}
+
+			for _, param := range o.Parameters {
+				if param.Type != spec.ParameterTypeBody || param.Schema == nil {
+					continue
+				}
+
+				tp, err := typeOf(param.Schema)
+				if err != nil {
+					return nil, err
+				}
+
+				values[jen.Id("Body")] = tp
+				break
+			}
+
+			responses := jen.Dict{}
+			seenCodes := make(map[string]bool)
+
+			for _, res := range o.Responses {
+				if res.Schema == nil || seenCodes[res.Code] {
+					continue
+				}
+				seenCodes[res.Code] = true
+
+				tp, err := typeOf(res.Schema)
+				if err != nil {
+					return nil, err
+				}
+
+				responses[jen.Lit(res.Code)] = tp
+			}
+
+			if len(responses) != 0 {
+				values[jen.Id("Responses")] = jen.Map(jen.String()).Qual("reflect", "Type").Values(responses)
+			}
+
+			operations[jen.Lit(id)] = jen.Values(values)
+		}
 	}
-	err = zw.Close()
-	if err != nil {
-		return nil, err
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// OperationTypes contains the Go types of the bodies of an operation.").Line()
 	}
+	code.Type().Id("OperationTypes").StructFunc(func(g *jen.Group) {
+		if options.Comments {
+			g.Comment("// Body is the type of the request body, or nil if there is none.")
+		}
+		g.Id("Body").Qual("reflect", "Type")
+		g.Line()
+		if options.Comments {
+			g.Comment("// Responses are the types of the response bodies by status code.")
+		}
+		g.Id("Responses").Map(jen.String()).Qual("reflect", "Type")
+	}).Line().Line()
 
-	specB64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+	code.Var().Id("operationTypes").Op("=").Map(jen.String()).Id("OperationTypes").Values(operations).Line().Line()
 
-	c := jen.Null()
+	if options.Comments {
+		code.Comment("// LookupOperationTypes returns the Go types of the bodies of an operation by its ID.").Line()
+	}
+	code.Func().Id("LookupOperationTypes").Params(jen.Id("operationID").String()).Params(jen.Id("OperationTypes"), jen.Bool()).Block(
+		jen.List(jen.Id("types"), jen.Id("ok")).Op(":=").Id("operationTypes").Index(jen.Id("operationID")),
+		jen.Return(jen.Id("types"), jen.Id("ok")),
+	).Line().Line()
 
-	if options.Comments && funcName != "" {
-		c.Commentf("// %v returns the specification file", funcName).Line()
+	if options.Comments {
+		code.Comment("// SchemaForOperation returns the Go type of the request body of an operation by its ID,").Line()
+		code.Comment("// or nil if the operation is unknown or has no request body.").Line()
 	}
+	code.Func().Id("SchemaForOperation").Params(jen.Id("operationID").String()).Qual("reflect", "Type").Block(
+		jen.Return(jen.Id("operationTypes").Index(jen.Id("operationID")).Dot("Body")),
+	)
 
-	c.Func().Id(funcName).Params().Params(jen.Index().Byte()).Block(
-		jen.Var().Id("specB64").Op("=").Lit(specB64),
+	return code, nil
+}
 
-		jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/base64", "StdEncoding").
-			Dot("DecodeString").Call(jen.Id("specB64")),
+// GenerateRoutes generates constants for the path template
+// and the method of each operation, and for the base path of the
+// specification if there is one.
+//
+// The path templates are the ones in the specification without the base path.
+func (g *General) GenerateRoutes(ctx context.Context, specification *spec.Spec, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
 
-		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Panic(jen.Err()),
-		),
+	type route struct {
+		name   string
+		path   string
+		method string
+	}
 
-		jen.Var().Id("buf").Op("=").Qual("bytes", "NewBuffer").Call(jen.Id("b")),
-		jen.Var().Id("outBuf").Qual("bytes", "Buffer"),
+	routes := make([]route, 0)
 
-		jen.List(jen.Id("zr"), jen.Id("err")).Op(":=").Qual("compress/gzip", "NewReader").Call(jen.Id("buf")),
-		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Panic(jen.Err()),
-		),
-		jen.Defer().Id("zr").Op(".").Id("Close").Call(),
+	for _, p := range specification.Paths {
+		for _, o := range p.Operations {
+			routes = append(routes, route{
+				name:   o.Name,
+				path:   p.PathString,
+				method: strings.ToUpper(o.Method),
+			})
+		}
+	}
 
-		jen.List(jen.Id("_"), jen.Id("err")).Op("=").Qual("io", "Copy").Call(jen.Op("&").Id("outBuf"), jen.Id("zr")),
-		jen.If(jen.Err().Op("!=").Nil()).Block(
-			jen.Panic(jen.Err()),
-		),
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].name < routes[j].name
+	})
 
-		jen.Return(jen.Id("outBuf").Op(".").Id("Bytes").Call()),
-	).Line().Line()
+	code := jen.Null()
 
-	return c, nil
-}
+	if specification.BasePath != "" {
+		if options.Comments {
+			code.Comment("// BasePath is the relative server URL of the specification that precedes the paths.").Line()
+		}
+		code.Const().Id("BasePath").Op("=").Lit(specification.BasePath).Line().Line()
+	}
 
-// Calls either encoding/json or the "json" value created by jsoniter
-func (g *General) jsonCall(jsoniter bool, target string) *jen.Statement {
-	if jsoniter {
-		return jen.Id("echo_jsonIter").Op(".").Id(target)
+	if len(routes) == 0 {
+		return code, nil
 	}
-	return jen.Qual("encoding/json", target)
+
+	defs := make([]jen.Code, 0, len(routes)*2)
+
+	for _, r := range routes {
+		if options.Comments {
+			defs = append(defs, jen.Commentf("// %v is the path template and %v is the method of the %v operation.",
+				r.name+"Path",
+				r.name+"Method",
+				r.name,
+			))
+		}
+		defs = append(defs,
+			jen.Id(r.name+"Path").Op("=").Lit(r.path),
+			jen.Id(r.name+"Method").Op("=").Lit(r.method),
+		)
+	}
+
+	code.Const().Defs(defs...).Line()
+
+	return code, nil
 }
 
-func (g *General) GetOpts(ctx context.Context) (*GeneralOptions, error) {
-	generatorOptions, ok := ctx.Value(common.ContextGeneratorOptions).(map[string]interface{})
+// GenerateErrors implements error for the schemas
+// of error responses, so that the decoded response bodies
+// can be returned and inspected as errors.
+func (g *General) GenerateErrors(ctx context.Context, specification *spec.Spec, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
 	if !ok {
-		return nil, fmt.Errorf("no options provided")
+		options = common.DefaultOptions()
 	}
 
-	rawOpts, ok := generatorOptions[g.Name()]
-	if !ok {
-		return nil, fmt.Errorf("no options provided")
+	errorNames := make(map[string]bool)
+
+	if len(opts.ErrorSchemas) > 0 {
+		for _, n := range opts.ErrorSchemas {
+			errorNames[n] = true
+		}
+	} else {
+		for _, p := range specification.Paths {
+			for _, o := range p.Operations {
+				for _, res := range o.Responses {
+					if res.Schema == nil || res.Schema.Name == "" {
+						continue
+					}
+
+					if strings.Contains(strings.ToLower(res.Schema.Name), "error") {
+						errorNames[res.Schema.Name] = true
+					}
+				}
+			}
+		}
 	}
 
-	ctxOpts := g.DefaultOptions().(*GeneralOptions)
+	schemas := make([]*spec.Schema, 0, len(errorNames))
 
-	err := mapstructure.Decode(rawOpts, ctxOpts)
-	if err != nil {
-		return nil, fmt.Errorf("invalid options: %w", err)
+	for _, schema := range specification.Schemas {
+		if schema.Create && errorNames[schema.Name] {
+			schemas = append(schemas, schema)
+		}
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+
+	code := jen.Null()
+
+	for _, schema := range schemas {
+		shortName := strings.ToLower(string(schema.Name[0]))
+
+		var body []jen.Code
+
+		switch {
+		case schema.Variant == spec.VariantPrimitive && schema.PrimitiveType == "string":
+			body = []jen.Code{jen.Return(jen.String().Call(jen.Id(shortName)))}
+
+		case schema.Variant == spec.VariantStruct:
+			// A field named Error (e.g. from an "error" property)
+			// cannot be declared together with the method.
+			if _, ok := schema.Children.GetMap()["Error"]; ok {
+				if options.Comments {
+					code.Commentf("// %v does not implement error, as it has an Error field.", schema.Name).Line().Line()
+				}
+				continue
+			}
+
+			body = g.errorMessageBody(schema, shortName)
+
+		default:
+			continue
+		}
+
+		if options.Comments {
+			code.Commentf("// Error implements error for %v.", schema.Name).Line()
+		}
+
+		code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("Error").Params().Params(jen.String()).
+			Block(body...).Line().Line()
+	}
+
+	return code, nil
+}
+
+// errorMessageBody returns the body of the Error method
+// for a struct schema. The first string field that looks like an error message
+// is returned, otherwise all the fields are formatted.
+func (g *General) errorMessageBody(schema *spec.Schema, shortName string) []jen.Code {
+	messageFields := []string{"message", "msg", "error", "detail", "description", "title"}
+
+	for _, m := range messageFields {
+		for fieldName, child := range schema.Children.GetMap() {
+			if strings.ToLower(fieldName) != m ||
+				child.Variant != spec.VariantPrimitive ||
+				child.PrimitiveType != "string" {
+				continue
+			}
+
+			if (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil() {
+				return []jen.Code{
+					jen.If(jen.Id(shortName).Dot(fieldName).Op("!=").Nil()).Block(
+						jen.Return(jen.Op("*").Id(shortName).Dot(fieldName)),
+					),
+					jen.Return(jen.Lit(schema.Name)),
+				}
+			}
+
+			return []jen.Code{jen.Return(jen.Id(shortName).Dot(fieldName))}
+		}
+	}
+
+	// The type is converted so that fmt doesn't call Error recursively.
+	return []jen.Code{
+		jen.Type().Id("plain").Id(schema.Name),
+		jen.Return(jen.Qual("fmt", "Sprintf").Call(
+			jen.Lit(schema.Name+": %+v"),
+			jen.Id("plain").Call(jen.Id(shortName)),
+		)),
+	}
+}
+
+// GenerateSpec generates code that stores the
+// specifications in base64, and a function to decode them to a map of bytes.
+func (g *General) GenerateSpec(ctx context.Context, spBytes []byte, funcName string) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	if spBytes == nil {
+		return nil, fmt.Errorf("no specification given")
+	}
+
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(spBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	specB64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	c := jen.Null()
+
+	if options.Comments && funcName != "" {
+		c.Commentf("// %v returns the specification file", funcName).Line()
+	}
+
+	c.Func().Id(funcName).Params().Params(jen.Index().Byte()).Block(
+		jen.Var().Id("specB64").Op("=").Lit(specB64),
+
+		jen.List(jen.Id("b"), jen.Err()).Op(":=").Qual("encoding/base64", "StdEncoding").
+			Dot("DecodeString").Call(jen.Id("specB64")),
+
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Panic(jen.Err()),
+		),
+
+		jen.Var().Id("buf").Op("=").Qual("bytes", "NewBuffer").Call(jen.Id("b")),
+		jen.Var().Id("outBuf").Qual("bytes", "Buffer"),
+
+		jen.List(jen.Id("zr"), jen.Id("err")).Op(":=").Qual("compress/gzip", "NewReader").Call(jen.Id("buf")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Panic(jen.Err()),
+		),
+		jen.Defer().Id("zr").Op(".").Id("Close").Call(),
+
+		jen.List(jen.Id("_"), jen.Id("err")).Op("=").Qual("io", "Copy").Call(jen.Op("&").Id("outBuf"), jen.Id("zr")),
+		jen.If(jen.Err().Op("!=").Nil()).Block(
+			jen.Panic(jen.Err()),
+		),
+
+		jen.Return(jen.Id("outBuf").Op(".").Id("Bytes").Call()),
+	).Line().Line()
+
+	return c, nil
+}
+
+// GenerateValidate generates a Validate method for a struct type
+// that checks the fields against the constraints in the schema.
+func (g *General) GenerateValidate(ctx context.Context, schema *spec.Schema, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	fieldNames := make([]string, 0, len(schema.Children.GetMap()))
+	for n := range schema.Children.GetMap() {
+		fieldNames = append(fieldNames, n)
+	}
+	sort.Strings(fieldNames)
+
+	body := make([]jen.Code, 0)
+
+	for _, fieldName := range fieldNames {
+		child := schema.Children.Map[fieldName]
+
+		if schema.AdditionalProps != nil && fieldName == schema.AdditionalPropsName {
+			continue
+		}
+
+		label := child.FieldName
+		if label == "" {
+			label = fieldName
+		}
+
+		isPtr := (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil()
+
+		value := func() *jen.Statement {
+			if isPtr {
+				return jen.Op("*").Id(shortName).Dot(fieldName)
+			}
+			return jen.Id(shortName).Dot(fieldName)
+		}
+
+		checks := g.validateValue(child, value, label, opts)
+		if len(checks) == 0 {
+			continue
+		}
+
+		if isPtr {
+			body = append(body, jen.If(jen.Id(shortName).Dot(fieldName).Op("!=").Nil()).Block(checks...))
+			continue
+		}
+
+		body = append(body, checks...)
+	}
+
+	body = append(body, jen.Return(jen.Nil()))
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// Validate checks whether %v satisfies the constraints of its schema.", schema.Name).Line()
+	}
+
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("Validate").Params().Params(jen.Error()).
+		Block(body...).Line().Line()
+
+	return code, nil
+}
+
+// validateValue returns the statements that check a single value
+// of the schema, and return an error if it is invalid.
+func (g *General) validateValue(schema *spec.Schema, value func() *jen.Statement, label string, opts *GeneralOptions) []jen.Code {
+	checks := make([]jen.Code, 0)
+
+	if _, fixed := fixedArrayLength(schema, opts); schema.Variant == spec.VariantArray && !fixed && len(schema.Tuple) == 0 {
+		if schema.MinItems > 0 {
+			checks = append(checks,
+				jen.If(jen.Len(value()).Op("<").Lit(int(schema.MinItems))).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(
+						jen.Lit(label+": must have at least %v items, got %v"), jen.Lit(int(schema.MinItems)), jen.Len(value()),
+					)),
+				),
+			)
+		}
+
+		if schema.MaxItems != nil {
+			checks = append(checks,
+				jen.If(jen.Len(value()).Op(">").Lit(int(*schema.MaxItems))).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(
+						jen.Lit(label+": must have at most %v items, got %v"), jen.Lit(int(*schema.MaxItems)), jen.Len(value()),
+					)),
+				),
+			)
+		}
+	}
+
+	// Nested types have their own Validate methods.
+	if schema.Name != "" && schema.Variant == spec.VariantStruct {
+		checks = append(checks,
+			jen.If(jen.Err().Op(":=").Add(value()).Dot("Validate").Call(), jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(label+": %w"), jen.Err())),
+			),
+		)
+		return checks
+	}
+
+	if schema.Variant == spec.VariantPrimitive && schema.PrimitiveType == "string" &&
+		validationFormats[schema.Format] {
+		checks = append(checks,
+			jen.If(
+				jen.Err().Op(":=").Id("validateStringFormat").Call(jen.Lit(schema.Format), jen.String().Call(value())),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(label+": %w"), jen.Err())),
+			),
+		)
+	}
+
+	if schema.Variant == spec.VariantPrimitive && schema.MultipleOf != nil {
+		multipleOf := *schema.MultipleOf
+
+		switch {
+		case integerTypes[schema.PrimitiveType] && multipleOf == math.Trunc(multipleOf):
+			checks = append(checks,
+				// Untyped constants work with all the integer types.
+				jen.If(value().Op("%").Lit(int(multipleOf)).Op("!=").Lit(0)).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(
+						jen.Lit(label+": must be a multiple of %v, got %v"), jen.Lit(int(multipleOf)), value(),
+					)),
+				),
+			)
+		case integerTypes[schema.PrimitiveType] || schema.PrimitiveType == "float32" || schema.PrimitiveType == "float64":
+			checks = append(checks,
+				jen.If(jen.Op("!").Id("validateMultipleOf").Call(jen.Float64().Call(value()), jen.Lit(multipleOf))).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(
+						jen.Lit(label+": must be a multiple of %v, got %v"), jen.Lit(multipleOf), value(),
+					)),
+				),
+			)
+		}
+	}
+
+	return checks
+}
+
+// integerTypes are the Go types of integer schemas.
+var integerTypes = map[string]bool{
+	"int":    true,
+	"int8":   true,
+	"int16":  true,
+	"int32":  true,
+	"int64":  true,
+	"uint":   true,
+	"uint8":  true,
+	"uint16": true,
+	"uint32": true,
+	"uint64": true,
+}
+
+// redactedValue is the value of redacted string fields.
+const redactedValue = "[REDACTED]"
+
+// GenerateRedactedStringers generates String and GoString methods
+// for a struct type that print it with the sensitive fields redacted.
+//
+// Nothing is generated if the struct has no sensitive fields,
+// or if a field would collide with the methods.
+func (g *General) GenerateRedactedStringers(ctx context.Context, schema *spec.Schema, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	fields := schema.Children.GetMap()
+	if _, ok := fields["String"]; ok {
+		return jen.Null(), nil
+	}
+	if _, ok := fields["GoString"]; ok {
+		return jen.Null(), nil
+	}
+
+	fieldNames := make([]string, 0, len(fields))
+	for n, child := range fields {
+		if child.Sensitive {
+			fieldNames = append(fieldNames, n)
+		}
+	}
+	sort.Strings(fieldNames)
+
+	if len(fieldNames) == 0 {
+		return jen.Null(), nil
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	// The copy is converted to a type without methods,
+	// otherwise printing it would call the methods again.
+	redact := []jen.Code{
+		jen.Type().Id("redacted").Id(schema.Name),
+		jen.Id("_v").Op(":=").Id("redacted").Call(jen.Id(shortName)),
+	}
+
+	for _, fieldName := range fieldNames {
+		child := fields[fieldName]
+		field := func() *jen.Statement {
+			return jen.Id("_v").Dot(fieldName)
+		}
+
+		isPtr := (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil()
+		isString := child.Variant == spec.VariantPrimitive && child.PrimitiveType == "string" && child.Name == ""
+
+		switch {
+		case isString && isPtr:
+			redact = append(redact, jen.If(field().Op("!=").Nil()).Block(
+				jen.Id("_r").Op(":=").Lit(redactedValue),
+				field().Op("=").Op("&").Id("_r"),
+			))
+		case isString:
+			redact = append(redact, jen.If(field().Op("!=").Lit("")).Block(
+				field().Op("=").Lit(redactedValue),
+			))
+		case isPtr || child.CanBeNil():
+			redact = append(redact, field().Op("=").Nil())
+		default:
+			tp, err := g.GenerateType(ctx, child, opts)
+			if err != nil {
+				return nil, err
+			}
+			redact = append(redact, field().Op("=").Op("*").New(tp))
+		}
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// String implements fmt.Stringer, sensitive fields are redacted.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("String").Params().String().Block(
+		append(redact, jen.Return(jen.Qual("fmt", "Sprintf").Call(jen.Lit("%+v"), jen.Id("_v"))))...,
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// GoString implements fmt.GoStringer, sensitive fields are redacted.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("GoString").Params().String().Block(
+		append(redact[:len(redact):len(redact)],
+			jen.Return(jen.Qual("strings", "Replace").Call(
+				jen.Qual("fmt", "Sprintf").Call(jen.Lit("%#v"), jen.Id("_v")),
+				jen.Lit(".redacted{"), jen.Lit("."+schema.Name+"{"), jen.Lit(1),
+			)),
+		)...,
+	).Line().Line()
+
+	return code, nil
+}
+
+// GenerateBuilders generates a New constructor and fluent
+// With setters for each field of a struct schema.
+//
+// Pointer fields are set from values, and setters
+// that would collide with a field name are skipped.
+func (g *General) GenerateBuilders(ctx context.Context, schema *spec.Schema, typeNames map[string]bool, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	code := jen.Null()
+
+	constructorName := "New" + schema.Name
+	if !typeNames[constructorName] {
+		if options.Comments {
+			code.Commentf("// %v returns a new empty %v.", constructorName, schema.Name).Line()
+		}
+		code.Func().Id(constructorName).Params().Params(jen.Op("*").Id(schema.Name)).Block(
+			jen.Return(jen.Op("&").Id(schema.Name).Values()),
+		).Line().Line()
+	}
+
+	fieldNames := make([]string, 0, len(schema.Children.GetMap()))
+	for n := range schema.Children.GetMap() {
+		fieldNames = append(fieldNames, n)
+	}
+	sort.Strings(fieldNames)
+
+	fields := make(map[string]bool, len(fieldNames))
+	for _, n := range fieldNames {
+		fields[n] = true
+	}
+
+	for _, fieldName := range fieldNames {
+		child := schema.Children.Map[fieldName]
+
+		if schema.AdditionalProps != nil && fieldName == schema.AdditionalPropsName {
+			continue
+		}
+
+		methodName := "With" + fieldName
+		if fields[methodName] {
+			continue
+		}
+
+		tp, err := g.GenerateType(ctx, child, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		value := jen.Id("value")
+		if (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil() {
+			value = jen.Op("&").Id("value")
+		}
+
+		if options.Comments {
+			code.Commentf("// %v sets %v, and returns %v.", methodName, fieldName, schema.Name).Line()
+		}
+		code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id(methodName).
+			Params(jen.Id("value").Add(tp)).Params(jen.Op("*").Id(schema.Name)).Block(
+			jen.Id(shortName).Dot(fieldName).Op("=").Add(value),
+			jen.Return(jen.Id(shortName)),
+		).Line().Line()
+	}
+
+	return code, nil
+}
+
+// fixedArrayLength returns the length of the array schema
+// if it should be generated as a fixed-size array.
+func fixedArrayLength(schema *spec.Schema, opts *GeneralOptions) (int, bool) {
+	if opts == nil || !opts.FixedArrays || schema.Variant != spec.VariantArray || len(schema.Tuple) > 0 ||
+		schema.MaxItems == nil || schema.MinItems != *schema.MaxItems || schema.MinItems == 0 {
+		return 0, false
+	}
+
+	return int(schema.MinItems), true
+}
+
+// validationFormats are the string formats
+// that are checked by the generated validation.
+var validationFormats = map[string]bool{
+	"email":    true,
+	"hostname": true,
+	"ipv4":     true,
+	"ipv6":     true,
+	"uri":      true,
+	"uuid":     true,
+}
+
+// GenerateValidationHelpers generates the functions used by
+// the generated Validate methods.
+func (g *General) GenerateValidationHelpers(ctx context.Context) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	code := jen.Null()
+
+	code.Var().Defs(
+		jen.Id("validateUUIDRegexp").Op("=").Qual("regexp", "MustCompile").
+			Call(jen.Lit(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)),
+		jen.Id("validateHostnameRegexp").Op("=").Qual("regexp", "MustCompile").
+			Call(jen.Lit(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// validateStringFormat checks whether the value is valid in the given format.").Line()
+	}
+
+	code.Add(gen.MustTemplate(`func validateStringFormat(format, value string) error {
+		switch format {
+		case "email":
+			if _, err := {{ .ParseAddress }}(value); err != nil {
+				return {{ .Errorf }}("invalid email %q", value)
+			}
+		case "uri":
+			if _, err := {{ .ParseRequestURI }}(value); err != nil {
+				return {{ .Errorf }}("invalid uri %q", value)
+			}
+		case "uuid":
+			if !validateUUIDRegexp.MatchString(value) {
+				return {{ .Errorf }}("invalid uuid %q", value)
+			}
+		case "hostname":
+			if len(value) > 253 || !validateHostnameRegexp.MatchString(value) {
+				return {{ .Errorf }}("invalid hostname %q", value)
+			}
+		case "ipv4":
+			if ip := {{ .ParseIP }}(value); ip == nil || ip.To4() == nil {
+				return {{ .Errorf }}("invalid ipv4 address %q", value)
+			}
+		case "ipv6":
+			if ip := {{ .ParseIP }}(value); ip == nil || !{{ .Contains }}(value, ":") {
+				return {{ .Errorf }}("invalid ipv6 address %q", value)
+			}
+		}
+
+		return nil
+	}`,
+		gen.Values{
+			"ParseAddress":    jen.Qual("net/mail", "ParseAddress"),
+			"ParseRequestURI": jen.Qual("net/url", "ParseRequestURI"),
+			"ParseIP":         jen.Qual("net", "ParseIP"),
+			"Contains":        jen.Qual("strings", "Contains"),
+			"Errorf":          jen.Qual("fmt", "Errorf"),
+		},
+	)).Line().Line()
+
+	if options.Comments {
+		code.Comment("// validateMultipleOf checks whether the value is a multiple of the divisor,").Line()
+		code.Comment("// allowing for floating point rounding errors.").Line()
+	}
+
+	code.Add(gen.MustTemplate(`func validateMultipleOf(value, divisor float64) bool {
+		quotient := value / divisor
+		return {{ .Abs }}(quotient-{{ .Round }}(quotient)) <= 1e-9*{{ .Max }}(1, {{ .Abs }}(quotient))
+	}`,
+		gen.Values{
+			"Abs":   jen.Qual("math", "Abs"),
+			"Round": jen.Qual("math", "Round"),
+			"Max":   jen.Qual("math", "Max"),
+		},
+	)).Line().Line()
+
+	return code
+}
+
+// enumConstName returns the name of the constant
+// for an enum value based on the naming strategy in the options.
+//
+// The name is based on varName instead of the value if it is not empty,
+// which is needed for meaningful names of e.g. integer enums.
+//
+// It is an error if the name is not a valid Go identifier.
+func (g *General) enumConstName(ctx context.Context, schema *spec.Schema, value interface{}, varName string, opts *GeneralOptions) (string, error) {
+	name, err := g.enumConstNameByStrategy(ctx, schema, value, varName, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if !token.IsIdentifier(name) {
+		return "", fmt.Errorf("enum value %v of %v: %q is not a valid Go identifier, set the names of the values or change the enum naming", value, schema.Name, name)
+	}
+
+	return name, nil
+}
+
+// enumConstNameByStrategy returns the name of the
+// constant for an enum value without validating it.
+//
+// Names that would start with a digit (e.g. for integer enums)
+// are prefixed with the type name for every strategy.
+func (g *General) enumConstNameByStrategy(ctx context.Context, schema *spec.Schema, value interface{}, varName string, opts *GeneralOptions) (string, error) {
+	rawName := fmt.Sprint(enumValue(schema, value))
+
+	nameSource := rawName
+	if varName != "" {
+		nameSource = varName
+	}
+
+	eName := nameSource
+
+	if strings.Contains(eName, "_") {
+		eName = strings.Title(
+			strings.ToLower(
+				strings.Replace(eName, "_", " ", -1),
+			),
+		)
+	}
+
+	eName = common.Initialisms(ctx).ToGoName(strcase.ToCamel(eName))
+
+	switch opts.EnumNaming {
+	case EnumNamingTypePrefix, "":
+		if opts.ErrorEnumPrefix && strings.Contains(strings.ToLower(schema.Name), "error") {
+			if !strings.HasPrefix(strings.ToLower(eName), "err") {
+				eName = "Err" + eName
+			}
+			return eName, nil
+		}
+
+		return schema.Name + eName, nil
+
+	case EnumNamingValueOnly:
+		if startsWithDigit(eName) {
+			return schema.Name + eName, nil
+		}
+		return eName, nil
+
+	case EnumNamingScreaming:
+		if startsWithDigit(nameSource) {
+			return strcase.ToScreamingSnake(schema.Name + "_" + nameSource), nil
+		}
+		return strcase.ToScreamingSnake(nameSource), nil
+
+	default:
+		if !strings.Contains(opts.EnumNaming, "{{") {
+			return "", fmt.Errorf("unknown enum naming %v", opts.EnumNaming)
+		}
+
+		templ, err := template.New("enum").Parse(opts.EnumNaming)
+		if err != nil {
+			return "", fmt.Errorf("invalid enum naming template: %w", err)
+		}
+
+		buf := &bytes.Buffer{}
+
+		err = templ.Execute(buf, EnumNameTemplateValues{
+			TypeName: schema.Name,
+			Value:    rawName,
+			Name:     eName,
+		})
+		if err != nil {
+			return "", fmt.Errorf("invalid enum naming template: %w", err)
+		}
+
+		return buf.String(), nil
+	}
+}
+
+// startsWithDigit checks whether the name starts with a digit.
+func startsWithDigit(name string) bool {
+	return name != "" && name[0] >= '0' && name[0] <= '9'
+}
+
+// GenerateGenericUnion generates a generic OneOfN union type
+// with the given number of type parameters, along with its accessors
+// and JSON marshal methods.
+func (g *General) GenerateGenericUnion(ctx context.Context, arity int) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	name := genericUnionName(arity)
+
+	typeParams := make([]jen.Code, 0, arity)
+	typeArgs := make([]jen.Code, 0, arity)
+	fields := make([]jen.Code, 0, arity)
+
+	for i := 1; i <= arity; i++ {
+		tp := jen.Id("T" + strconv.Itoa(i))
+		if i == arity {
+			tp.Id("any")
+		}
+		typeParams = append(typeParams, tp)
+		typeArgs = append(typeArgs, jen.Id("T"+strconv.Itoa(i)))
+		fields = append(fields, jen.Id("v"+strconv.Itoa(i)).Op("*").Id("T"+strconv.Itoa(i)))
+	}
+
+	// The instantiated type used in receivers and literals.
+	self := func() *jen.Statement {
+		return jen.Id(name).Index(jen.List(typeArgs...))
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v is a union of %v types, at most one of its values is set at a time.", name, arity).Line()
+	}
+	code.Type().Id(name).Index(jen.List(typeParams...)).Struct(fields...).Line().Line()
+
+	indexCases := make([]jen.Code, 0, arity)
+	marshalCases := make([]jen.Code, 0, arity)
+	unmarshalBlocks := make([]jen.Code, 0, arity)
+
+	for i := 1; i <= arity; i++ {
+		n := strconv.Itoa(i)
+		field := "v" + n
+		tp := jen.Id("T" + n)
+
+		if options.Comments {
+			code.Commentf("// V%v returns the value of type T%v, and whether it is set.", n, n).Line()
+		}
+		code.Func().Params(jen.Id("o").Add(self())).Id("V"+n).Params().Params(tp, jen.Bool()).Block(
+			jen.If(jen.Id("o").Dot(field).Op("==").Nil()).Block(
+				jen.Var().Id("zero").Add(tp),
+				jen.Return(jen.Id("zero"), jen.False()),
+			),
+			jen.Return(jen.Op("*").Id("o").Dot(field), jen.True()),
+		).Line().Line()
+
+		if options.Comments {
+			code.Commentf("// SetV%v sets the value of type T%v, and clears any other value.", n, n).Line()
+		}
+		code.Func().Params(jen.Id("o").Op("*").Add(self())).Id("SetV" + n).Params(jen.Id("v").Add(tp)).Block(
+			jen.Op("*").Id("o").Op("=").Add(self()).Values(jen.Dict{jen.Id(field): jen.Op("&").Id("v")}),
+		).Line().Line()
+
+		indexCases = append(indexCases,
+			jen.Case(jen.Id("o").Dot(field).Op("!=").Nil()).Block(jen.Return(jen.Lit(i))),
+		)
+
+		marshalCases = append(marshalCases,
+			jen.Case(jen.Id("o").Dot(field).Op("!=").Nil()).Block(
+				jen.Return(g.jsonCall("Marshal").Call(jen.Id("o").Dot(field))),
+			),
+		)
+
+		unmarshalBlocks = append(unmarshalBlocks, jen.Block(
+			jen.Var().Id("v").Add(tp),
+			jen.Id("d").Op(":=").Add(g.jsonCall("NewDecoder")).
+				Call(jen.Qual("bytes", "NewReader").Call(jen.Id("b"))),
+			jen.Id("d").Dot("DisallowUnknownFields").Call(),
+			jen.If(jen.Id("d").Dot("Decode").Call(jen.Op("&").Id("v")).Op("==").Nil()).Block(
+				jen.Op("*").Id("o").Op("=").Add(self()).Values(jen.Dict{jen.Id(field): jen.Op("&").Id("v")}),
+				jen.Return(jen.Nil()),
+			),
+		).Line())
+	}
+
+	if options.Comments {
+		code.Comment("// Index returns the position of the type of the value that is set,").Line()
+		code.Comment("// starting from 1, or 0 if no value is set.").Line()
+	}
+	code.Func().Params(jen.Id("o").Add(self())).Id("Index").Params().Params(jen.Int()).Block(
+		jen.Switch().Block(indexCases...),
+		jen.Return(jen.Lit(0)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// MarshalJSON implements json.Marshaler.").Line()
+	}
+	code.Func().Params(jen.Id("o").Add(self())).Id("MarshalJSON").Params().Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Switch().Block(marshalCases...),
+		jen.Return(jen.Index().Byte().Call(jen.Lit("null")), jen.Nil()),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler,").Line()
+		code.Comment("// the value is decoded into the first type it strictly matches.").Line()
+	}
+
+	unmarshalBody := []jen.Code{
+		jen.If(jen.Qual("bytes", "Equal").Call(
+			jen.Qual("bytes", "TrimSpace").Call(jen.Id("b")),
+			jen.Index().Byte().Call(jen.Lit("null")),
+		)).Block(
+			jen.Op("*").Id("o").Op("=").Add(self()).Values(),
+			jen.Return(jen.Nil()),
+		).Line(),
+	}
+	unmarshalBody = append(unmarshalBody, unmarshalBlocks...)
+	unmarshalBody = append(unmarshalBody,
+		jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("value does not match any of the types of "+name))),
+	)
+
+	code.Func().Params(jen.Id("o").Op("*").Add(self())).Id("UnmarshalJSON").
+		Params(jen.Id("b").Index().Byte()).Params(jen.Error()).
+		Block(unmarshalBody...).Line().Line()
+
+	return code
+}
+
+// genericUnionArity returns the number of type parameters
+// of the generic union type for the schema,
+// or 0 if it should not be generated as one.
+func (g *General) genericUnionArity(schema *spec.Schema, opts *GeneralOptions) int {
+	if opts == nil || !opts.UseGenerics {
+		return 0
+	}
+
+	if schema.Variant != spec.VariantAnyOf && schema.Variant != spec.VariantOneOf {
+		return 0
+	}
+
+	children := schema.Children.GetArray()
+
+	if len(children) < 2 || len(children) > maxGenericUnionArity {
+		return 0
+	}
+
+	for _, c := range children {
+		if c == nil {
+			return 0
+		}
+	}
+
+	return len(children)
+}
+
+// collectGenericUnionArities collects the arities of
+// all the generic union types required by the schema and its children.
+func (g *General) collectGenericUnionArities(schema *spec.Schema, opts *GeneralOptions, arities map[int]bool, visited map[*spec.Schema]bool) {
+	if schema == nil || visited[schema] {
+		return
+	}
+	visited[schema] = true
+
+	if arity := g.genericUnionArity(schema, opts); arity > 0 {
+		arities[arity] = true
+	}
+
+	// References to other types are collected on their own.
+	if !schema.Create && schema.Name != "" {
+		return
+	}
+
+	g.collectGenericUnionArities(schema.AdditionalProps, opts, arities, visited)
+
+	if schema.Children == nil {
+		return
+	}
+
+	g.collectGenericUnionArities(schema.Children.Schema, opts, arities, visited)
+
+	for _, c := range schema.Children.Array {
+		g.collectGenericUnionArities(c, opts, arities, visited)
+	}
+
+	for _, c := range schema.Children.Map {
+		g.collectGenericUnionArities(c, opts, arities, visited)
+	}
+}
+
+// tupleFieldName returns the name of
+// the field of a tuple item at the index.
+func tupleFieldName(idx int) string {
+	return "V" + strconv.Itoa(idx)
+}
+
+// polymorphicArrayItem returns the item schema of a named array
+// if it is a union of named schemas, and its elements should be
+// decoded into the concrete types, otherwise it returns nil.
+func (g *General) polymorphicArrayItem(schema *spec.Schema, opts *GeneralOptions) *spec.Schema {
+	if schema.Name == "" || schema.Variant != spec.VariantArray || len(schema.Tuple) > 0 || schema.Children == nil {
+		return nil
+	}
+
+	if _, fixed := fixedArrayLength(schema, opts); fixed {
+		return nil
+	}
+
+	item := schema.Children.Schema
+	if item == nil || (item.Variant != spec.VariantOneOf && item.Variant != spec.VariantAnyOf) ||
+		g.genericUnionArity(item, opts) > 0 {
+		return nil
+	}
+
+	children := item.Children.GetArray()
+	if len(children) == 0 {
+		return nil
+	}
+
+	for _, c := range children {
+		if c == nil || c.Name == "" {
+			return nil
+		}
+	}
+
+	return item
+}
+
+// GeneratePolymorphicArrayMethods generates an UnmarshalJSON method
+// for a named array of a union, that decodes each item
+// into the concrete type of the union.
+func (g *General) GeneratePolymorphicArrayMethods(ctx context.Context, schema *spec.Schema, item *spec.Schema, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+	decoderName := "decode" + schema.Name + "Item"
+
+	code := jen.Null()
+
+	decoder, err := g.GenerateUnionDecoder(ctx, item, decoderName, opts)
+	if err != nil {
+		return nil, err
+	}
+	code.Add(decoder)
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler, the items are decoded").Line()
+		code.Comment("// into the concrete types of the union.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id("UnmarshalJSON").
+		Params(jen.Id("data").Index().Byte()).Params(jen.Error()).Block(
+		jen.Var().Id("_items").Index().Qual("encoding/json", "RawMessage"),
+		jen.If(
+			jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("_items")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())).Line(),
+		jen.If(jen.Id("_items").Op("==").Nil()).Block(
+			jen.Op("*").Id(shortName).Op("=").Nil(),
+			jen.Return(jen.Nil()),
+		).Line(),
+		jen.Id("_res").Op(":=").Make(jen.Id(schema.Name), jen.Len(jen.Id("_items"))),
+		jen.For(jen.List(jen.Id("_i"), jen.Id("_item")).Op(":=").Range().Id("_items")).Block(
+			jen.List(jen.Id("_v"), jen.Err()).Op(":=").Id(decoderName).Call(jen.Id("_item")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit("item %v: %w"), jen.Id("_i"), jen.Err())),
+			),
+			jen.Id("_res").Index(jen.Id("_i")).Op("=").Id("_v"),
+		).Line(),
+		jen.Op("*").Id(shortName).Op("=").Id("_res"),
+		jen.Return(jen.Nil()),
+	).Line().Line()
+
+	return code, nil
+}
+
+// discriminatedUnion returns whether the schema is a union
+// with a discriminator that can be decoded into its concrete types.
+func (g *General) discriminatedUnion(schema *spec.Schema, opts *GeneralOptions) bool {
+	if schema == nil || schema.Discriminator == nil ||
+		(schema.Variant != spec.VariantOneOf && schema.Variant != spec.VariantAnyOf) ||
+		g.genericUnionArity(schema, opts) > 0 {
+		return false
+	}
+
+	children := schema.Children.GetArray()
+	if len(children) == 0 {
+		return false
+	}
+
+	for _, c := range children {
+		if c == nil || c.Name == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GenerateUnionFieldsUnmarshal generates an UnmarshalJSON method for
+// a struct with discriminated union fields, or arrays of them, that
+// decodes the fields into the concrete types of the unions.
+//
+// Nothing is generated if the struct has no such fields.
+func (g *General) GenerateUnionFieldsUnmarshal(ctx context.Context, schema *spec.Schema, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	fields := schema.Children.GetMap()
+
+	fieldNames := make([]string, 0, len(fields))
+	for n := range fields {
+		fieldNames = append(fieldNames, n)
+	}
+	sort.Strings(fieldNames)
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	code := jen.Null()
+
+	// The fields that are decoded separately.
+	rawFields := make([]jen.Code, 0)
+	decode := make([]jen.Code, 0)
+
+	for _, fieldName := range fieldNames {
+		child := fields[fieldName]
+
+		union, isArray, ok := g.unionField(child, opts)
+		if !ok {
+			continue
+		}
+
+		jsonTag := child.Tags["json"]
+
+		decoderName := "decode" + schema.Name + fieldName
+
+		decoder, err := g.GenerateUnionDecoder(ctx, union, decoderName, opts)
+		if err != nil {
+			return nil, err
+		}
+		code.Add(decoder)
+
+		rawFields = append(rawFields,
+			jen.Id(fieldName).Qual("encoding/json", "RawMessage").Tag(map[string]string{"json": jsonTag[0]}),
+		)
+
+		field := func() *jen.Statement {
+			return jen.Id(shortName).Dot(fieldName)
+		}
+		raw := func() *jen.Statement {
+			return jen.Id("_v").Dot(fieldName)
+		}
+
+		if !isArray {
+			decode = append(decode,
+				field().Op("=").Nil(),
+				jen.If(raw().Op("!=").Nil()).Block(
+					jen.List(jen.Id("_value"), jen.Err()).Op(":=").Id(decoderName).Call(raw()),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(jsonTag[0]+": %w"), jen.Err())),
+					),
+					field().Op("=").Id("_value"),
+				).Line(),
+			)
+			continue
+		}
+
+		tp, err := g.GenerateType(ctx, child, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		decode = append(decode,
+			field().Op("=").Nil(),
+			jen.If(raw().Op("!=").Nil()).Block(
+				jen.Var().Id("_items").Index().Qual("encoding/json", "RawMessage"),
+				jen.If(
+					jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(raw(), jen.Op("&").Id("_items")),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(jsonTag[0]+": %w"), jen.Err())),
+				),
+				jen.If(jen.Id("_items").Op("!=").Nil()).Block(
+					field().Op("=").Make(tp, jen.Len(jen.Id("_items"))),
+				),
+				jen.For(jen.List(jen.Id("_i"), jen.Id("_item")).Op(":=").Range().Id("_items")).Block(
+					jen.List(jen.Id("_value"), jen.Err()).Op(":=").Id(decoderName).Call(jen.Id("_item")),
+					jen.If(jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(jsonTag[0]+": item %v: %w"), jen.Id("_i"), jen.Err())),
+					),
+					field().Index(jen.Id("_i")).Op("=").Id("_value"),
+				),
+			).Line(),
+		)
+	}
+
+	if len(rawFields) == 0 {
+		return jen.Null(), nil
+	}
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler, the discriminated").Line()
+		code.Comment("// unions are decoded into their concrete types.").Line()
+	}
+
+	// The union fields of the embedded struct are shadowed
+	// by the raw fields, so they are not decoded by it.
+	body := []jen.Code{
+		jen.Type().Id("plain").Id(schema.Name),
+		jen.Var().Id("_v").Struct(append([]jen.Code{jen.Op("*").Id("plain")}, rawFields...)...),
+		jen.Id("_v").Dot("plain").Op("=").Parens(jen.Op("*").Id("plain")).Call(jen.Id(shortName)).Line(),
+		jen.If(
+			jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("_v")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())).Line(),
+	}
+	body = append(body, decode...)
+	body = append(body, jen.Return(jen.Nil()))
+
+	code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id("UnmarshalJSON").
+		Params(jen.Id("data").Index().Byte()).Params(jen.Error()).Block(body...).Line().Line()
+
+	return code, nil
+}
+
+// GenerateUnionDecoder generates a function with the given name
+// that decodes a JSON value of a union of named schemas into
+// a pointer to the concrete type.
+//
+// The concrete type is selected by the discriminator if the union
+// has one, otherwise the first schema that the value strictly
+// decodes into is used.
+func (g *General) GenerateUnionDecoder(ctx context.Context, union *spec.Schema, funcName string, opts *GeneralOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	unionType, err := g.GenerateType(ctx, union, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// decodeInto decodes the data into a new value
+	// of the child type, and returns it.
+	decodeInto := func(child *spec.Schema, strict bool) ([]jen.Code, error) {
+		childType, err := g.GenerateType(ctx, child, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		value := jen.Op("&").Id("v")
+		if child.CanBeNil() {
+			value = jen.Id("v")
+		}
+
+		if strict {
+			return []jen.Code{
+				jen.Var().Id("v").Add(childType),
+				jen.Id("d").Op(":=").Add(g.jsonCall("NewDecoder")).Call(jen.Qual("bytes", "NewReader").Call(jen.Id("data"))),
+				jen.Id("d").Dot("DisallowUnknownFields").Call(),
+				jen.If(jen.Id("d").Dot("Decode").Call(jen.Op("&").Id("v")).Op("==").Nil()).Block(
+					jen.Return(value, jen.Nil()),
+				),
+			}, nil
+		}
+
+		return []jen.Code{
+			jen.Var().Id("v").Add(childType),
+			jen.If(
+				jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("v")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Return(value, jen.Nil()),
+		}, nil
+	}
+
+	body := []jen.Code{
+		jen.If(jen.Qual("bytes", "Equal").Call(
+			jen.Qual("bytes", "TrimSpace").Call(jen.Id("data")),
+			jen.Index().Byte().Call(jen.Lit("null")),
+		)).Block(jen.Return(jen.Nil(), jen.Nil())).Line(),
+	}
+
+	children := union.Children.GetArray()
+
+	if d := union.Discriminator; d != nil {
+		body = append(body,
+			jen.Var().Id("discriminator").Struct(
+				jen.Id("Value").String().Tag(map[string]string{"json": d.PropertyName}),
+			),
+			jen.If(
+				jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("discriminator")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Err())).Line(),
+		)
+
+		cases := make([]jen.Code, 0, len(children))
+
+		for _, child := range children {
+			values := make([]string, 0, 1)
+
+			for value, name := range d.Mapping {
+				if name == child.Name {
+					values = append(values, value)
+				}
+			}
+
+			// The names of the schemas in the specification are
+			// used implicitly unless they are mapped to other schemas.
+			implicit := child.OriginalName
+			if implicit == "" {
+				implicit = child.Name
+			}
+
+			if _, mapped := d.Mapping[implicit]; !mapped {
+				values = append(values, implicit)
+			}
+
+			sort.Strings(values)
+
+			lits := make([]jen.Code, 0, len(values))
+			for _, v := range values {
+				lits = append(lits, jen.Lit(v))
+			}
+
+			decode, err := decodeInto(child, false)
+			if err != nil {
+				return nil, err
+			}
+
+			cases = append(cases, jen.Case(lits...).Block(decode...))
+		}
+
+		body = append(body,
+			jen.Switch(jen.Id("discriminator").Dot("Value")).Block(cases...).Line(),
+			jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+				jen.Lit(fmt.Sprintf("unknown %v %%q", d.PropertyName)), jen.Id("discriminator").Dot("Value"),
+			)),
+		)
+	} else {
+		for _, child := range children {
+			decode, err := decodeInto(child, true)
+			if err != nil {
+				return nil, err
+			}
+
+			body = append(body, jen.Block(decode...).Line())
+		}
+
+		body = append(body,
+			jen.Return(jen.Nil(), jen.Qual("errors", "New").Call(jen.Lit("value does not match any of the schemas"))),
+		)
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v decodes a value of the union into its concrete type.", funcName).Line()
+	}
+	code.Func().Id(funcName).Params(jen.Id("data").Index().Byte()).Params(unionType, jen.Error()).
+		Block(body...).Line().Line()
+
+	return code, nil
+}
+
+// GenerateTupleMarshalMethods generates JSON marshal methods
+// for tuple structs that encode them as JSON arrays.
+//
+// Additional items after the tuple items are ignored.
+func (g *General) GenerateTupleMarshalMethods(ctx context.Context, schema *spec.Schema) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	items := make([]jen.Code, 0, len(schema.Tuple))
+	decode := make([]jen.Code, 0, len(schema.Tuple))
+
+	for i := range schema.Tuple {
+		field := tupleFieldName(i)
+
+		items = append(items, jen.Id(shortName).Dot(field))
+		decode = append(decode,
+			jen.If(jen.Len(jen.Id("items")).Op(">").Lit(i)).Block(
+				jen.If(
+					jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("items").Index(jen.Lit(i)), jen.Op("&").Id(shortName).Dot(field)),
+					jen.Err().Op("!=").Nil(),
+				).Block(
+					jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(fmt.Sprintf("item %v: %%w", i)), jen.Err())),
+				),
+			),
+		)
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// MarshalJSON implements json.Marshaler, the tuple is encoded as an array.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("MarshalJSON").Params().
+		Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Return(g.jsonCall("Marshal").Call(jen.Index().Interface().Values(items...))),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler, the tuple is decoded from an array.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id("UnmarshalJSON").
+		Params(jen.Id("data").Index().Byte()).Params(jen.Error()).Block(
+		append([]jen.Code{
+			jen.Var().Id("items").Index().Qual("encoding/json", "RawMessage"),
+			jen.If(
+				jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("items")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())).Line(),
+		}, append(decode, jen.Return(jen.Nil()))...)...,
+	).Line().Line()
+
+	return code
+}
+
+// GenerateAllOfMarshalMethods generates JSON marshal methods for allOf
+// types with named fields, that merge the fields into a single object.
+//
+// Properties that are defined by multiple schemas are taken from the last one.
+func (g *General) GenerateAllOfMarshalMethods(ctx context.Context, schema *spec.Schema) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	parts := make([]jen.Code, 0, len(schema.Children.Array))
+
+	for _, child := range schema.Children.Array {
+		parts = append(parts, jen.Id(shortName).Dot(allOfFieldName(child)))
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// MarshalJSON implements json.Marshaler, the fields of").Line()
+		code.Comment("// the allOf schemas are merged into a single object.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("MarshalJSON").Params().
+		Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Id("_merged").Op(":=").Make(jen.Map(jen.String()).Qual("encoding/json", "RawMessage")),
+		jen.Line(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("_part")).Op(":=").Range().Index().Interface().Values(parts...)).Block(
+			jen.List(jen.Id("_data"), jen.Err()).Op(":=").Add(g.jsonCall("Marshal")).Call(jen.Id("_part")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Line(),
+			jen.Var().Id("_fields").Map(jen.String()).Qual("encoding/json", "RawMessage"),
+			jen.If(
+				jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("_data"), jen.Op("&").Id("_fields")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Nil(), jen.Err())),
+			jen.Line(),
+			jen.For(jen.List(jen.Id("_k"), jen.Id("_v")).Op(":=").Range().Id("_fields")).Block(
+				jen.Id("_merged").Index(jen.Id("_k")).Op("=").Id("_v"),
+			),
+		),
+		jen.Line(),
+		jen.Return(g.jsonCall("Marshal").Call(jen.Id("_merged"))),
+	).Line().Line()
+
+	code.Add(g.GenerateAllOfUnmarshal(ctx, schema))
+
+	return code
+}
+
+// GenerateAllOfUnmarshal generates an UnmarshalJSON method for allOf types
+// that decodes all the allOf schemas from the same object.
+//
+// It is also needed for allOf types with embedded fields if an embedded
+// type has an UnmarshalJSON method, as the promoted method would only
+// decode the embedded type.
+func (g *General) GenerateAllOfUnmarshal(ctx context.Context, schema *spec.Schema) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(string(schema.Name[0]))
+
+	decode := make([]jen.Code, 0, len(schema.Children.Array)+1)
+
+	for _, child := range schema.Children.Array {
+		field := allOfFieldName(child)
+
+		decode = append(decode,
+			jen.If(
+				jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id(shortName).Dot(field)),
+				jen.Err().Op("!=").Nil(),
+			).Block(
+				jen.Return(jen.Qual("fmt", "Errorf").Call(jen.Lit(field+": %w"), jen.Err())),
+			),
+		)
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler, all the").Line()
+		code.Comment("// allOf schemas are decoded from the same object.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id("UnmarshalJSON").
+		Params(jen.Id("data").Index().Byte()).Params(jen.Error()).Block(
+		append(decode, jen.Line(), jen.Return(jen.Nil()))...,
+	).Line().Line()
+
+	return code
+}
+
+// hasUnionFieldsUnmarshal returns whether an UnmarshalJSON method
+// is generated for the schema by GenerateUnionFieldsUnmarshal,
+// or by GenerateAllOfUnmarshal for the schemas embedding such a schema.
+//
+// The schemas of allOf are looked up by their names in schemas,
+// as their children might not be the same as the generated types.
+func (g *General) hasUnionFieldsUnmarshal(schema *spec.Schema, schemas map[string]*spec.Schema, opts *GeneralOptions) bool {
+	if named, ok := schemas[schema.Name]; ok && schema.Name != "" {
+		schema = named
+	}
+
+	if !opts.GenerateMarshalMethods || schema.Name == "" {
+		return false
+	}
+
+	switch schema.Variant {
+	case spec.VariantStruct:
+		if schema.AdditionalProps != nil {
+			return false
+		}
+
+		for _, child := range schema.Children.GetMap() {
+			if _, _, ok := g.unionField(child, opts); ok {
+				return true
+			}
+		}
+	case spec.VariantAllOf:
+		if opts.AllOfNamedFields {
+			return true
+		}
+
+		for _, child := range schema.Children.GetArray() {
+			if child.Name != schema.Name && g.hasUnionFieldsUnmarshal(child, schemas, opts) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// unionField returns the discriminated union of a struct field that is
+// decoded by the method of GenerateUnionFieldsUnmarshal, and whether
+// the field is an array of the union.
+func (g *General) unionField(child *spec.Schema, opts *GeneralOptions) (*spec.Schema, bool, bool) {
+	jsonTag := child.Tags["json"]
+	if len(jsonTag) == 0 || jsonTag[0] == "-" || jsonTag[0] == "" {
+		return nil, false, false
+	}
+
+	union := child
+	isArray := child.Variant == spec.VariantArray && child.Name == "" && len(child.Tuple) == 0 && child.Children != nil
+	if isArray {
+		if _, fixed := fixedArrayLength(child, opts); fixed {
+			return nil, false, false
+		}
+		union = child.Children.Schema
+	}
+
+	if !g.discriminatedUnion(union, opts) {
+		return nil, false, false
+	}
+
+	return union, isArray, true
+}
+
+// allOfFieldName returns the name of the field of
+// a schema of allOf with AllOfNamedFields.
+func allOfFieldName(child *spec.Schema) string {
+	name := child.Name
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	return name
+}
+
+// enumValue returns an enum value of the schema to be used as a literal,
+// the numbers of integer enums are decoded as floats, but they are
+// generated as integers (e.g. 10 instead of 10.0).
+func enumValue(schema *spec.Schema, value interface{}) interface{} {
+	f, ok := value.(float64)
+	if !ok || f != math.Trunc(f) {
+		return value
+	}
+
+	switch schema.PrimitiveType {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64":
+		return int(f)
+	default:
+		return value
+	}
+}
+
+// isStrongEnumType checks whether marshal methods
+// can be generated for an enum type with StrongEnums.
+func isStrongEnumType(schema *spec.Schema) bool {
+	if schema.Name == "" || schema.Variant != spec.VariantPrimitive {
+		return false
+	}
+
+	switch schema.PrimitiveType {
+	case "string", "bool",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateEnumMarshalers generates JSON marshal methods for an enum type
+// that map between the constants of the enum and their wire values,
+// values that are not in the enum are rejected in both directions.
+//
+// The names of the constants are in the order of the enum values.
+func (g *General) GenerateEnumMarshalers(ctx context.Context, schema *spec.Schema, constNames []string) jen.Code {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	shortName := strings.ToLower(schema.Name[:1])
+
+	marshalCases := make([]jen.Code, 0, len(schema.Enum)+1)
+	unmarshalCases := make([]jen.Code, 0, len(schema.Enum)+1)
+
+	for i, e := range schema.Enum {
+		e = enumValue(schema, e)
+
+		// Numeric values are converted, so that they
+		// are not encoded as floats.
+		wireValue := jen.Lit(e)
+		if schema.PrimitiveType != "string" {
+			wireValue = jen.Id(schema.PrimitiveType).Call(jen.Lit(e))
+		}
+
+		marshalCases = append(marshalCases, jen.Case(jen.Id(constNames[i])).Block(
+			jen.Return(g.jsonCall("Marshal").Call(wireValue)),
+		))
+		unmarshalCases = append(unmarshalCases, jen.Case(jen.Lit(e)).Block(
+			jen.Op("*").Id(shortName).Op("=").Id(constNames[i]),
+		))
+	}
+
+	marshalCases = append(marshalCases, jen.Default().Block(
+		jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(
+			jen.Lit(fmt.Sprintf("invalid %v value: %%v", schema.Name)),
+			jen.Id(schema.PrimitiveType).Call(jen.Id(shortName)),
+		)),
+	))
+	unmarshalCases = append(unmarshalCases, jen.Default().Block(
+		jen.Return(jen.Qual("fmt", "Errorf").Call(
+			jen.Lit(fmt.Sprintf("invalid %v value: %%v", schema.Name)),
+			jen.Id("value"),
+		)),
+	))
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// MarshalJSON implements json.Marshaler, only the values of the enum are allowed.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Id(schema.Name)).Id("MarshalJSON").Params().
+		Params(jen.Index().Byte(), jen.Error()).Block(
+		jen.Switch(jen.Id(shortName)).Block(marshalCases...),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// UnmarshalJSON implements json.Unmarshaler, only the values of the enum are allowed.").Line()
+	}
+	code.Func().Params(jen.Id(shortName).Op("*").Id(schema.Name)).Id("UnmarshalJSON").
+		Params(jen.Id("data").Index().Byte()).Params(jen.Error()).Block(
+		jen.Var().Id("value").Id(schema.PrimitiveType),
+		jen.If(
+			jen.Err().Op(":=").Add(g.jsonCall("Unmarshal")).Call(jen.Id("data"), jen.Op("&").Id("value")),
+			jen.Err().Op("!=").Nil(),
+		).Block(jen.Return(jen.Err())).Line(),
+		jen.Switch(jen.Id("value")).Block(unmarshalCases...),
+		jen.Line(),
+		jen.Return(jen.Nil()),
+	).Line().Line()
+
+	return code
+}
+
+// genericUnionName returns the name of the
+// generic union type with the given arity.
+func genericUnionName(arity int) string {
+	return "OneOf" + strconv.Itoa(arity)
+}
+
+// unnamedTypeName returns a name for a type without a schema name
+// that is derived from the generated type and the description of the schema.
+func unnamedTypeName(typeCode jen.Code, description string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%#v\n%v", jen.Type().Id("_").Add(typeCode), description)))
+
+	return "UnnamedType" + strings.ToUpper(hex.EncodeToString(sum[:4]))
+}
+
+// jsonCall calls a function of encoding/json.
+//
+// The generated types must not depend on any framework,
+// so framework-specific JSON implementations are never used here.
+func (g *General) jsonCall(target string) *jen.Statement {
+	return jen.Qual("encoding/json", target)
+}
+
+func (g *General) GetOpts(ctx context.Context) (*GeneralOptions, error) {
+	generatorOptions, ok := ctx.Value(common.ContextGeneratorOptions).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("no options provided")
+	}
+
+	rawOpts, ok := generatorOptions[g.Name()]
+	if !ok {
+		return nil, fmt.Errorf("no options provided")
+	}
+
+	ctxOpts := g.DefaultOptions().(*GeneralOptions)
+
+	err := mapstructure.Decode(rawOpts, ctxOpts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	// Other generators refer to the function in the spec target.
+	if !token.IsIdentifier(ctxOpts.SpecFuncName) {
+		return nil, fmt.Errorf("invalid options of %v: specFuncName: %q is not a valid Go identifier", g.Name(), ctxOpts.SpecFuncName)
 	}
 
 	return ctxOpts, nil
@@ -0,0 +1,850 @@
+package golang
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dave/jennifer/jen"
+	"github.com/tamasfe/repose/pkg/common"
+	"github.com/tamasfe/repose/pkg/generator"
+	reposeparser "github.com/tamasfe/repose/pkg/parser"
+	"github.com/tamasfe/repose/pkg/spec"
+	"github.com/tamasfe/repose/pkg/transformer"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+const typesTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "born": {"type": "string", "format": "date-time"},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "extra": {"type": "object", "additionalProperties": {"type": "integer"}}
+        }
+      },
+      "Owner": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Pet"},
+          {"type": "object", "properties": {"address": {"type": "string"}}}
+        ]
+      },
+      "Animal": {
+        "oneOf": [
+          {"$ref": "#/components/schemas/Pet"},
+          {"type": "string"}
+        ]
+      }
+    }
+  }
+}`
+
+// generateTestCode parses and transforms the specification,
+// and generates the given target of the generator.
+func generateTestCode(t *testing.T, data string, g generator.Generator, target string, options map[string]interface{}) (interface{}, error) {
+	return generateTransformedTestCode(t, data, nil, g, target, options)
+}
+
+// generateTransformedTestCode is the same as generateTestCode,
+// but with options for the transformer.
+func generateTransformedTestCode(t *testing.T, data string, transformerOptions map[string]interface{}, g generator.Generator, target string, options map[string]interface{}) (interface{}, error) {
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+	ctx = context.WithValue(ctx, common.ContextGeneratorOptions, map[string]interface{}{
+		(&General{}).Name(): nil,
+		g.Name():            options,
+	})
+
+	sp, err := (&reposeparser.OpenAPI3{}).Parse(ctx, nil, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = (&transformer.Default{}).Transform(ctx, transformerOptions, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g.Generate(ctx, options, sp, target)
+}
+
+// generateTestFile generates the given target of the generator
+// for the specification, and returns the source of the file.
+func generateTestFile(t *testing.T, data string, g generator.Generator, target string, options map[string]interface{}) []byte {
+	return generateTransformedTestFile(t, data, nil, g, target, options)
+}
+
+// generateTransformedTestFile is the same as generateTestFile,
+// but with options for the transformer.
+func generateTransformedTestFile(t *testing.T, data string, transformerOptions map[string]interface{}, g generator.Generator, target string, options map[string]interface{}) []byte {
+	out, err := generateTransformedTestCode(t, data, transformerOptions, g, target, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := jen.NewFile("types")
+	f.Add(out.(jen.Code))
+
+	buf := &bytes.Buffer{}
+
+	err = f.Render(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// typeCheck checks that the generated files of
+// the same package compile, and fails the test otherwise.
+func typeCheck(t *testing.T, srcs ...[]byte) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(srcs))
+
+	for i, src := range srcs {
+		file, err := parser.ParseFile(fset, fmt.Sprintf("generated%v.go", i), src, 0)
+		if err != nil {
+			t.Fatalf("generated code is invalid: %v\n%s", err, src)
+		}
+		files = append(files, file)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	_, err := conf.Check("types", fset, files, nil)
+	if err != nil {
+		t.Fatalf("generated code does not compile: %v\n%s", err, bytes.Join(srcs, []byte("\n")))
+	}
+}
+
+func TestTypesStandardLibraryOnly(t *testing.T) {
+	src := generateTestFile(t, typesTestSpec, &General{}, "types", nil)
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "types.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+
+		// Standard library packages have no dots in their first path element.
+		assert.Equal(t, strings.Contains(strings.Split(path, "/")[0], "."), false)
+	}
+
+	typeCheck(t, src)
+}
+
+func TestWireNameTags(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "User": {
+        "type": "object",
+        "required": ["user-id"],
+        "properties": {
+          "user-id": {"type": "string"},
+          "display_name": {"type": "string"},
+          "group": {"$ref": "#/components/schemas/Group"}
+        }
+      },
+      "Group": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, data, &General{}, "types", nil))
+
+	assert.MatchRegex(t, src, "UserID\\s+string\\s+`json:\"user-id,omitempty\"`")
+	assert.MatchRegex(t, src, "DisplayName\\s+\\*string\\s+`json:\"display_name,omitempty\"`")
+	assert.MatchRegex(t, src, "Group\\s+\\*Group\\s+`json:\"group,omitempty\"`")
+
+	// The Go names of the fields are available to the tag templates as well.
+	src = string(generateTransformedTestFile(t, data, map[string]interface{}{
+		"tags": map[string][]string{
+			"db": {"{{ .GoName }}"},
+		},
+	}, &General{}, "types", nil))
+
+	assert.MatchRegex(t, src, "UserID\\s+string\\s+`db:\"UserID\" json:\"user-id,omitempty\"`")
+	assert.MatchRegex(t, src, "DisplayName\\s+\\*string\\s+`db:\"DisplayName\" json:\"display_name,omitempty\"`")
+}
+
+func TestOperationRegistry(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+        "responses": {
+          "201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+          "204": {"description": "nothing"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`, &General{}, "registry", nil))
+
+	assert.MatchRegex(t, src, `"addPet":\s+\{`)
+	assert.MatchRegex(t, src, `Body:\s+reflect\.TypeOf\(\(\*Pet\)\(nil\)\)\.Elem\(\)`)
+	assert.MatchRegex(t, src, `"201":\s+reflect\.TypeOf\(\(\*Pet\)\(nil\)\)\.Elem\(\)`)
+	assert.Equal(t, strings.Contains(src, `"204"`), false)
+	assert.Equal(t, strings.Contains(src, "func SchemaForOperation(operationID string) reflect.Type"), true)
+}
+
+func TestMultipleOfValidation(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Price": {
+        "type": "object",
+        "required": ["cents", "amount", "count"],
+        "properties": {
+          "cents": {"type": "integer", "format": "int64", "multipleOf": 5},
+          "count": {"type": "integer", "format": "int32", "multipleOf": 3},
+          "amount": {"type": "number", "multipleOf": 0.01},
+          "step": {"type": "integer", "multipleOf": 2.5}
+        }
+      }
+    }
+  }
+}`, &General{}, "types", map[string]interface{}{"generateValidation": true})
+
+	assert.MatchRegex(t, string(src), `if p\.Cents%5 != 0 \{`)
+	assert.MatchRegex(t, string(src), `if p\.Count%3 != 0 \{`)
+	assert.MatchRegex(t, string(src), `if !validateMultipleOf\(float64\(p\.Amount\), 0\.01\) \{`)
+	assert.MatchRegex(t, string(src), `if !validateMultipleOf\(float64\(\*p\.Step\), 2\.5\) \{`)
+
+	typeCheck(t, src)
+}
+
+func TestPolymorphicArray(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Cat": {"type": "object", "properties": {"petType": {"type": "string"}, "meows": {"type": "boolean"}}},
+      "Dog": {"type": "object", "properties": {"petType": {"type": "string"}, "barks": {"type": "boolean"}}},
+      "bird": {"type": "object", "properties": {"petType": {"type": "string"}, "flies": {"type": "boolean"}}},
+      "Animal": {
+        "oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}, {"$ref": "#/components/schemas/bird"}],
+        "discriminator": {"propertyName": "petType", "mapping": {"cat": "#/components/schemas/Cat"}}
+      },
+      "Animals": {"type": "array", "items": {"$ref": "#/components/schemas/Animal"}},
+      "Pets": {
+        "type": "array",
+        "items": {"oneOf": [{"$ref": "#/components/schemas/Cat"}, {"$ref": "#/components/schemas/Dog"}]}
+      }
+    }
+  }
+}`, &General{}, "types", nil)
+
+	assert.MatchRegex(t, string(src), `func \(a \*Animals\) UnmarshalJSON\(data \[\]byte\) error \{`)
+	assert.MatchRegex(t, string(src), `case "cat":`)
+	assert.MatchRegex(t, string(src), `case "Dog":`)
+	// The implicit values are the names in the specification.
+	assert.MatchRegex(t, string(src), `case "bird":`)
+	assert.MatchRegex(t, string(src), `json:"petType"`)
+	assert.MatchRegex(t, string(src), `func \(p \*Pets\) UnmarshalJSON\(data \[\]byte\) error \{`)
+	assert.MatchRegex(t, string(src), `DisallowUnknownFields\(\)`)
+
+	typeCheck(t, src)
+}
+
+func TestRedactSensitive(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Credentials": {
+        "type": "object",
+        "required": ["username", "password"],
+        "properties": {
+          "username": {"type": "string"},
+          "password": {"type": "string", "format": "password"},
+          "token": {"type": "string", "x-repose": {"sensitive": true}},
+          "pin": {"type": "integer", "x-repose": {"sensitive": true}}
+        }
+      },
+      "Public": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Vault": {"type": "object", "properties": {"secret": {"type": "string", "format": "password"}}}
+    }
+  }
+}`, &General{}, "types", map[string]interface{}{"redactSensitive": true})
+
+	assert.MatchRegex(t, string(src), `func \(c Credentials\) String\(\) string \{`)
+	assert.MatchRegex(t, string(src), `func \(c Credentials\) GoString\(\) string \{`)
+	assert.MatchRegex(t, string(src), `v\.Password = "\[REDACTED\]"`)
+	assert.MatchRegex(t, string(src), `v\.Pin = nil`)
+	assert.Equal(t, strings.Contains(string(src), "func (p Public) String() string"), false)
+	assert.Equal(t, strings.Contains(string(src), "v.Username ="), false)
+	assert.MatchRegex(t, string(src), `func \(v Vault\) String\(\) string \{\s+type redacted Vault\s+_v := redacted\(v\)`)
+
+	typeCheck(t, src)
+}
+
+func TestDiscriminatedUnionFields(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Terrier": {"type": "object", "properties": {"kind": {"type": "string"}, "size": {"type": "string"}}},
+      "Poodle": {"type": "object", "properties": {"kind": {"type": "string"}, "curly": {"type": "boolean"}}},
+      "Breed": {
+        "oneOf": [{"$ref": "#/components/schemas/Terrier"}, {"$ref": "#/components/schemas/Poodle"}],
+        "discriminator": {"propertyName": "kind"}
+      },
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "breed": {"$ref": "#/components/schemas/Breed"},
+          "ancestors": {"type": "array", "items": {"$ref": "#/components/schemas/Breed"}},
+          "mix": {
+            "oneOf": [{"$ref": "#/components/schemas/Terrier"}, {"$ref": "#/components/schemas/Poodle"}],
+            "discriminator": {"propertyName": "kind", "mapping": {"terrier": "#/components/schemas/Terrier"}}
+          }
+        }
+      }
+    }
+  }
+}`, &General{}, "types", nil)
+
+	assert.MatchRegex(t, string(src), `func \(p \*Pet\) UnmarshalJSON\(data \[\]byte\) error \{`)
+	assert.MatchRegex(t, string(src), `Breed\s+json\.RawMessage\s+`+"`"+`json:"breed`)
+	assert.MatchRegex(t, string(src), `func decodePetBreed\(data \[\]byte\) \(Breed, error\)`)
+	assert.MatchRegex(t, string(src), `func decodePetAncestors\(data \[\]byte\) \(Breed, error\)`)
+	assert.MatchRegex(t, string(src), `case "terrier":`)
+	assert.Equal(t, strings.Contains(string(src), "decodePetName"), false)
+
+	typeCheck(t, src)
+}
+
+func TestDiscriminatedUnionFieldsAllOf(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Terrier": {"type": "object", "properties": {"kind": {"type": "string"}, "size": {"type": "string"}}},
+      "Poodle": {"type": "object", "properties": {"kind": {"type": "string"}, "curly": {"type": "boolean"}}},
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "breed": {
+            "oneOf": [{"$ref": "#/components/schemas/Terrier"}, {"$ref": "#/components/schemas/Poodle"}],
+            "discriminator": {"propertyName": "kind"}
+          }
+        }
+      },
+      "Owner": {"type": "object", "properties": {"owner": {"type": "string"}}},
+      "OwnedPet": {"allOf": [{"$ref": "#/components/schemas/Pet"}, {"$ref": "#/components/schemas/Owner"}]},
+      "Named": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "NamedOwnedPet": {"allOf": [{"$ref": "#/components/schemas/OwnedPet"}, {"$ref": "#/components/schemas/Named"}]},
+      "OwnedTerrier": {"allOf": [{"$ref": "#/components/schemas/Terrier"}, {"$ref": "#/components/schemas/Owner"}]}
+    }
+  }
+}`, &General{}, "types", nil)
+
+	// The UnmarshalJSON of Pet would be promoted,
+	// and only decode the embedded Pet.
+	assert.MatchRegex(t, string(src), `func \(o \*OwnedPet\) UnmarshalJSON\(data \[\]byte\) error \{`)
+	assert.MatchRegex(t, string(src), `json\.Unmarshal\(data, &o\.Pet\)`)
+	assert.MatchRegex(t, string(src), `json\.Unmarshal\(data, &o\.Owner\)`)
+	assert.MatchRegex(t, string(src), `func \(n \*NamedOwnedPet\) UnmarshalJSON\(data \[\]byte\) error \{`)
+	assert.Equal(t, strings.Contains(string(src), "func (o *OwnedTerrier) UnmarshalJSON"), false)
+
+	typeCheck(t, src)
+}
+
+func TestEnumNaming(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Status": {"type": "string", "enum": ["in_progress", "done"]},
+      "Level": {"type": "integer", "enum": [10, 20]}
+    }
+  }
+}`
+
+	cases := []struct {
+		naming string
+		consts []string
+	}{
+		{EnumNamingTypePrefix, []string{`StatusInProgress\s+Status = "in_progress"`, `Level10\s+Level = 10\n`}},
+		{EnumNamingValueOnly, []string{`InProgress\s+Status = "in_progress"`, `Level20\s+Level = 20\n`}},
+		{EnumNamingScreaming, []string{`IN_PROGRESS\s+Status = "in_progress"`, `LEVEL_10\s+Level = 10\n`}},
+	}
+
+	for _, c := range cases {
+		src := generateTestFile(t, data, &General{}, "types", map[string]interface{}{
+			"enumNaming": c.naming,
+		})
+
+		for _, re := range c.consts {
+			assert.MatchRegex(t, string(src), re)
+		}
+
+		typeCheck(t, src)
+	}
+
+	// Template output is not changed, so it must be valid.
+	_, err := generateTestCode(t, data, &General{}, "types", map[string]interface{}{
+		"enumNaming": "{{ .Value }}{{ .TypeName }}",
+	})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), `"10Level" is not a valid Go identifier`), true)
+
+	src := generateTestFile(t, data, &General{}, "types", map[string]interface{}{
+		"enumNaming": "{{ .TypeName }}_{{ .Value }}",
+	})
+	assert.MatchRegex(t, string(src), `Status_in_progress\s+Status = "in_progress"`)
+	typeCheck(t, src)
+
+	// Names without the type name can collide.
+	_, err = generateTestCode(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Status": {"type": "string", "enum": ["active", "done"]},
+      "TaskStatus": {"type": "string", "enum": ["open", "done"]}
+    }
+  }
+}`, &General{}, "types", map[string]interface{}{
+		"enumNaming": EnumNamingValueOnly,
+	})
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), "the constant Done is already defined for Status"), true)
+}
+
+func TestEnumKeyedMap(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Status": {"type": "string", "enum": ["active", "inactive"]},
+      "StatusCounts": {
+        "type": "object",
+        "propertyNames": {"$ref": "#/components/schemas/Status"},
+        "additionalProperties": {"type": "integer"}
+      },
+      "Report": {
+        "type": "object",
+        "properties": {
+          "totals": {
+            "type": "object",
+            "propertyNames": {"$ref": "#/components/schemas/Status"},
+            "additionalProperties": {"type": "number"}
+          }
+        }
+      }
+    }
+  }
+}`, &General{}, "types", nil)
+
+	assert.MatchRegex(t, string(src), `type StatusCounts map\[Status\]int`)
+	assert.MatchRegex(t, string(src), `Totals\s+map\[Status\]float64`)
+	assert.MatchRegex(t, string(src), `StatusActive\s+Status = "active"`)
+
+	typeCheck(t, src)
+}
+
+func TestUnnamedTypeNames(t *testing.T) {
+	unnamed := func() []*spec.Schema {
+		return []*spec.Schema{
+			spec.NewSchema().Struct(map[string]*spec.Schema{
+				"Name": spec.NewSchema().Primitive("string"),
+			}).ShouldCreate(true),
+			spec.NewSchema().Struct(map[string]*spec.Schema{
+				"Count": spec.NewSchema().Primitive("int"),
+			}).ShouldCreate(true),
+			spec.NewSchema().Struct(map[string]*spec.Schema{
+				"Name": spec.NewSchema().Primitive("string"),
+			}).ShouldCreate(true),
+		}
+	}
+
+	generate := func(schemas []*spec.Schema) string {
+		g := &General{}
+
+		code, err := g.GenerateTypes(context.Background(), &spec.Spec{Schemas: schemas}, g.DefaultOptions().(*GeneralOptions))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		f := jen.NewFile("types")
+		f.Add(code)
+
+		return f.GoString()
+	}
+
+	schemas := unnamed()
+	src := generate(schemas)
+
+	// The names do not depend on the order of the schemas.
+	reversed := unnamed()
+	reversed[0], reversed[2] = reversed[2], reversed[0]
+	reversed[0], reversed[1] = reversed[1], reversed[0]
+	assert.Equal(t, generate(reversed), src)
+
+	assert.Equal(t, strings.Count(src, "type UnnamedType"), 3)
+	assert.MatchRegex(t, src, `type UnnamedType[0-9A-F]{8} struct`)
+	assert.MatchRegex(t, src, `type UnnamedType[0-9A-F]{8}_2 struct`)
+}
+
+func TestIntegerEnumVarNames(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Priority": {
+        "type": "integer",
+        "enum": [1, 2, 3],
+        "x-enum-varnames": ["low", "medium", "HIGH_PRIORITY"]
+      },
+      "Level": {
+        "type": "integer",
+        "enum": [10, 20],
+        "x-enumNames": ["Debug", "Info"]
+      }
+    }
+  }
+}`, &General{}, "types", nil))
+
+	assert.MatchRegex(t, src, `PriorityLow\s+Priority = 1`)
+	assert.MatchRegex(t, src, `PriorityMedium\s+Priority = 2`)
+	assert.MatchRegex(t, src, `PriorityHighPriority\s+Priority = 3`)
+	assert.MatchRegex(t, src, `LevelDebug\s+Level = 10`)
+	assert.MatchRegex(t, src, `LevelInfo\s+Level = 20`)
+	assert.Equal(t, strings.Contains(src, "Priority1"), false)
+}
+
+func TestRoutes(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &General{}, "routes", nil))
+
+	assert.MatchRegex(t, src, `BasePath = "/api/v1"`)
+	assert.MatchRegex(t, src, `DeletePetPath\s+= "/pets/\{id\}"`)
+	assert.MatchRegex(t, src, `DeletePetMethod\s+= "DELETE"`)
+}
+
+func TestAllOfWithProperties(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Base": {"type": "object", "properties": {"id": {"type": "string"}}},
+      "Dog": {
+        "allOf": [{"$ref": "#/components/schemas/Base"}],
+        "required": ["breed"],
+        "properties": {
+          "breed": {"type": "string"},
+          "age": {"type": "integer"}
+        }
+      }
+    }
+  }
+}`, &General{}, "types", nil))
+
+	assert.MatchRegex(t, src, `type Dog struct \{\s+Base\s+DogFragment1\s+\}`)
+	assert.MatchRegex(t, src, `type DogFragment1 struct \{[^}]*Age\s+\*int\s[^}]*Breed\s+string`)
+}
+
+func TestValidateTags(t *testing.T) {
+	src := string(generateTransformedTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["age", "name", "count", "toys"],
+        "properties": {
+          "age": {"type": "integer", "minimum": 1, "maximum": 100},
+          "count": {"type": "integer"},
+          "toys": {"type": "array", "items": {"type": "string"}, "minItems": 1},
+          "name": {"type": "string", "minLength": 1, "maxLength": 64, "pattern": "^[a-z]+$"},
+          "weight": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+          "kind": {"type": "string", "enum": ["cat", "dog"]},
+          "tags": {"type": "array", "items": {"type": "string"}, "maxItems": 5}
+        }
+      }
+    }
+  }
+}`, map[string]interface{}{"validateTags": true}, &General{}, "types", nil))
+
+	// "required" would reject the zero values of fields that cannot be nil.
+	assert.MatchRegex(t, src, "Age\\s+int\\s+`json:\"age,omitempty\" validate:\"min=1,max=100\"`")
+	assert.MatchRegex(t, src, "Name\\s+string\\s+`json:\"name,omitempty\" validate:\"min=1,max=64\"`")
+	assert.MatchRegex(t, src, "Count\\s+int\\s+`json:\"count,omitempty\"`")
+	assert.MatchRegex(t, src, "Toys\\s+\\[\\]string\\s+`json:\"toys,omitempty\" validate:\"required,min=1\"`")
+	assert.MatchRegex(t, src, "Weight\\s+\\*float64\\s+`json:\"weight,omitempty\" validate:\"omitempty,gt=0\"`")
+	assert.MatchRegex(t, src, "validate:\"omitempty,oneof=cat dog\"")
+	assert.MatchRegex(t, src, "Tags\\s+\\[\\]string\\s+`json:\"tags,omitempty\" validate:\"omitempty,max=5\"`")
+}
+
+func TestStrongEnums(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Status": {"type": "string", "enum": ["in-progress", "done"]},
+      "Level": {
+        "type": "integer",
+        "enum": [10, 20],
+        "x-enumNames": ["Debug", "Info"]
+      }
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, data, &General{}, "types", nil))
+	assert.Equal(t, strings.Contains(src, "MarshalJSON"), false)
+
+	src = string(generateTestFile(t, data, &General{}, "types", map[string]interface{}{
+		"strongEnums": true,
+	}))
+
+	assert.Equal(t, strings.Contains(src, "func (s Status) MarshalJSON() ([]byte, error)"), true)
+	assert.Equal(t, strings.Contains(src, "func (s *Status) UnmarshalJSON(data []byte) error"), true)
+	assert.MatchRegex(t, src, `case StatusInProgress:\s+return json\.Marshal\("in-progress"\)`)
+	assert.MatchRegex(t, src, `case "in-progress":\s+\*s = StatusInProgress`)
+	assert.MatchRegex(t, src, `case LevelDebug:\s+return json\.Marshal\(int\(10\)\)`)
+	assert.MatchRegex(t, src, `case 20:\s+\*l = LevelInfo`)
+	assert.MatchRegex(t, src, `LevelDebug\s+Level = 10\n`)
+	assert.Equal(t, strings.Contains(src, `fmt.Errorf("invalid Level value: %v", value)`), true)
+
+	typeCheck(t, []byte(src))
+}
+
+func TestFreeFormObject(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "metadata": {"type": "object", "description": "Arbitrary data"}
+        }
+      }
+    }
+  }
+}`, &General{}, "types", nil))
+
+	assert.MatchRegex(t, src, `Metadata\s+map\[string\]interface\{\}`)
+	assert.Equal(t, strings.Contains(src, "struct{}"), false)
+
+	// Objects with constraints on the properties are not free-form.
+	src = string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "properties": {
+          "owner": {"type": "object", "required": ["name"]},
+          "tags": {"type": "object", "minProperties": 1},
+          "labels": {"type": "object", "maxProperties": 10}
+        }
+      }
+    }
+  }
+}`, &General{}, "types", nil))
+
+	assert.Equal(t, strings.Contains(src, "map[string]interface{}"), false)
+}
+
+func TestAllOfNamedFields(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Base": {"type": "object", "properties": {"id": {"type": "string"}}},
+      "Named": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Pet": {"allOf": [{"$ref": "#/components/schemas/Base"}, {"$ref": "#/components/schemas/Named"}]}
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, data, &General{}, "types", nil))
+	assert.MatchRegex(t, src, `type Pet struct \{\s+Base\s+Named\s+\}`)
+	assert.Equal(t, strings.Contains(src, "func (p Pet) MarshalJSON() ([]byte, error)"), false)
+
+	src = string(generateTestFile(t, data, &General{}, "types", map[string]interface{}{
+		"allOfNamedFields": true,
+	}))
+	assert.MatchRegex(t, src, `type Pet struct \{\s+Base\s+Base\s+Named\s+Named\s+\}`)
+	assert.Equal(t, strings.Contains(src, "func (p Pet) MarshalJSON() ([]byte, error)"), true)
+	assert.Equal(t, strings.Contains(src, "range []interface{}{p.Base, p.Named}"), true)
+	assert.Equal(t, strings.Contains(src, "func (p *Pet) UnmarshalJSON(data []byte) error"), true)
+	assert.Equal(t, strings.Contains(src, "json.Unmarshal(data, &p.Named)"), true)
+}
+
+func TestErrors(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "400": {
+            "description": "Bad request",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+          },
+          "500": {
+            "description": "Internal error",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ServerError"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Error": {"type": "object", "properties": {"message": {"type": "string"}}},
+      "ServerError": {"type": "object", "properties": {"error": {"type": "string"}}}
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, data, &General{}, "types", nil)
+	errorsSrc := generateTestFile(t, data, &General{}, "errors", nil)
+	src := string(errorsSrc)
+
+	assert.Equal(t, strings.Contains(src, "func (e Error) Error() string"), true)
+
+	// The method would conflict with the field.
+	assert.Equal(t, strings.Contains(src, "func (s ServerError) Error() string"), false)
+
+	typeCheck(t, typesSrc, errorsSrc)
+}
+
+func TestGenerateBuilders(t *testing.T) {
+	src := generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "age": {"type": "integer"},
+          "owner": {"$ref": "#/components/schemas/Owner"},
+          "tags": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "Owner": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "NewOwner": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`, &General{}, "types", map[string]interface{}{
+		"generateBuilders": true,
+	})
+
+	assert.Equal(t, strings.Contains(string(src), "func NewPet() *Pet {"), true)
+	// The constructor would collide with the NewOwner type.
+	assert.Equal(t, strings.Contains(string(src), "func NewOwner() *Owner"), false)
+
+	// The builders are chained, and optional values are set by reference.
+	typeCheck(t, src, []byte(`package types
+
+func buildPet() *Pet {
+	owner := &Owner{}
+	owner.WithName("Alice")
+
+	return NewPet().
+		WithName("Rex").
+		WithAge(3).
+		WithOwner(*owner).
+		WithTags([]string{"good"})
+}
+
+func checkPet(p *Pet) bool {
+	return p.Name == "Rex" && *p.Age == 3 && *p.Owner.Name == "Alice" && p.Tags[0] == "good"
+}
+`))
+}
+
+func TestTargetAliases(t *testing.T) {
+	for _, g := range []generator.Generator{&General{}, &Echo{}, &StdLib{}} {
+		aliases := g.(generator.TargetAliases).TargetAliases()
+
+		for target, names := range aliases {
+			_, ok := g.Targets()[target]
+			assert.Equal(t, ok, true)
+
+			// Every alias is accepted by the generator.
+			for _, name := range names {
+				_, err := generateTestCode(t, typesTestSpec, g, name, nil)
+				if err != nil {
+					assert.Equal(t, strings.Contains(err.Error(), "is not supported"), false)
+				}
+			}
+		}
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"go/token"
+	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/dave/jennifer/jen"
+	"github.com/iancoleman/strcase"
 	"github.com/mitchellh/mapstructure"
 	"github.com/tamasfe/repose/internal/markdown"
 	"github.com/tamasfe/repose/pkg/common"
@@ -21,7 +26,12 @@ import (
 type StdLib struct{}
 
 type StdLibOptions struct {
-	TypesPackagePath string `yaml:"typesPackagePath" description:"Path to the generated types package, if left empty it is assumed that it is in the same package"`
+	TypesPackagePath       string `yaml:"typesPackagePath" description:"Full import path of the generated types package (e.g. github.com/user/module/types), if left empty it is assumed that it is in the same package"`
+	IgnoreBasePath         bool   `yaml:"ignoreBasePath" description:"Do not prepend the relative server URL of the specification (e.g. /api/v1) to the request paths, for when the server URL passed to the client already contains it"`
+	UniformClient          bool   `yaml:"uniformClient" description:"Generate request and response types for each operation, and a Do method on the full client that sends any of them, so that middleware can be written for all operations"`
+	UniformRequestPostfix  string `yaml:"uniformRequestPostfix" description:"Postfix of the operation request types of the uniform client, configure it to avoid collisions with actual types"`
+	UniformResponsePostfix string `yaml:"uniformResponsePostfix" description:"Postfix of the operation response types of the uniform client, configure it to avoid collisions with actual types"`
+	UniformTypePrefix      string `yaml:"uniformTypePrefix" description:"Prefix of the Request, Response, Doer and DoerFunc types of the uniform client, configure it to avoid collisions with actual types"`
 }
 
 // Name implements Target
@@ -90,15 +100,30 @@ This generator generates code that only relies on the standard library.
 // Targets implements Target
 func (s *StdLib) Targets() map[string]string {
 	return map[string]string{
-		"client":    "Generate Go HTTP Requests",
-		"callbacks": "Generate Go HTTP Requests for callbacks",
+		"client":      "Generate Go HTTP Requests",
+		"callbacks":   "Generate Go HTTP Requests for callbacks",
+		"full-client": "A client that sends the requests and decodes the responses, requires the client target in the same package",
+	}
+}
+
+// TargetAliases implements TargetAliases
+func (s *StdLib) TargetAliases() map[string][]string {
+	return map[string][]string{
+		"client":      {"c", "clients"},
+		"callbacks":   {"cb", "callback"},
+		"full-client": {"fc"},
 	}
 }
 
 // DefaultOptions implements Target
 func (s *StdLib) DefaultOptions() interface{} {
 	return &StdLibOptions{
-		TypesPackagePath: "",
+		TypesPackagePath:       "",
+		IgnoreBasePath:         false,
+		UniformClient:          false,
+		UniformRequestPostfix:  "Request",
+		UniformResponsePostfix: "Response",
+		UniformTypePrefix:      "Client",
 	}
 }
 
@@ -111,11 +136,22 @@ func (s *StdLib) Generate(ctx context.Context, options interface{}, specificatio
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	opts.TypesPackagePath, err = gen.NormalizeImportPath(opts.TypesPackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: typesPackagePath: %w", err)
+	}
+
+	if !token.IsIdentifier(opts.UniformTypePrefix + "Doer") {
+		return nil, fmt.Errorf("invalid options: uniformTypePrefix: %q is not a valid prefix of Go identifiers", opts.UniformTypePrefix)
+	}
+
 	switch target {
 	case "c", "client", "clients":
 		return s.GenerateClient(ctx, specification, opts)
 	case "cb", "callback", "callbacks":
 		return s.GenerateCallbacks(ctx, specification, opts)
+	case "full-client", "fc":
+		return s.GenerateFullClient(ctx, specification, opts)
 	default:
 		return nil, fmt.Errorf("Target %v is not supported", target)
 	}
@@ -130,14 +166,49 @@ func (s *StdLib) GenerateClient(ctx context.Context, specification *spec.Spec, o
 
 	code := jen.Null()
 
+	basePath := specification.BasePath
+	if opts.IgnoreBasePath {
+		basePath = ""
+	}
+
+	g := &General{}
+	generalOpts, err := g.GetOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generalOpts.TypesPackagePath = opts.TypesPackagePath
+
 	for _, p := range specification.Paths {
 
 		clientStructName := "client" + p.Name
+		clientInterfaceName := p.Name + "ClientInterface"
+
+		methods := make([]jen.Code, 0, len(p.Operations))
+
+		for _, o := range p.Operations {
+			opParams, err := s.operationParams(ctx, o, generalOpts, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			methods = append(methods, jen.Id(o.Name).Params(opParams...).Params(
+				jen.Op("*").Qual("net/http", "Request"), jen.Error(),
+			))
+		}
+
+		if options.Comments {
+			code.Commentf("// %v describes the client requests for \"%v\",", clientInterfaceName, p.PathString).Line()
+			code.Comment("// it can be used to replace the client in tests.").Line()
+		}
+		code.Type().Id(clientInterfaceName).Interface(methods...).Line().Line()
 
 		code.Type().Id(clientStructName).Struct(
 			jen.Id("server").String(),
 		).Line().Line()
 
+		code.Add(gen.AssertImplements(jen.Id(clientInterfaceName), jen.Id(clientStructName).Values())).Line().Line()
+
 		if options.Comments {
 			code.Commentf("// %v provides client requests for \"%v\".",
 				p.Name+"Client",
@@ -163,7 +234,7 @@ func (s *StdLib) GenerateClient(ctx context.Context, specification *spec.Spec, o
 					o.Name,
 				).Line()
 			}
-			req, err := s.GenerateRequest(ctx, fName, jen.Id("c").Op(".").Id("server"), p.PathString, o, opts)
+			req, err := s.GenerateRequest(ctx, fName, jen.Id("c").Op(".").Id("server"), basePath+p.PathString, o, opts)
 			if err != nil {
 				return nil, err
 			}
@@ -255,8 +326,14 @@ func (s *StdLib) GenerateRequest(ctx context.Context, funcName jen.Code, url jen
 		params = append(params, jen.Id(urlName).String())
 	}
 
+	// The server URL might end with a slash,
+	// which is trimmed to avoid double slashes.
 	if path != "" {
-		urlCode.Add(jen.Id(urlName).Op("+=").Lit(path)).Line()
+		urlCode.Add(
+			jen.Id(urlName).Op("=").
+				Qual("strings", "TrimRight").Call(jen.Id(urlName), jen.Lit("/")).
+				Op("+").Lit("/" + strings.TrimLeft(path, "/")),
+		).Line()
 	}
 
 	g := &General{}
@@ -267,18 +344,21 @@ func (s *StdLib) GenerateRequest(ctx context.Context, funcName jen.Code, url jen
 
 	generalOpts.TypesPackagePath = opts.TypesPackagePath
 
+	opParams, err := s.operationParams(ctx, op, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+	params = append(params, opParams...)
+
 	for _, p := range op.Parameters {
+		if isMultipartBody(p) {
+			marshalValues.Add(s.generateMultipartBody(p)).Line().Line()
 
-		argCode := jen.Id(p.Name)
+			additionalStatements.Id("_req").Dot("Header").Dot("Set").Call(
+				jen.Lit("Content-Type"), jen.Id("_mw").Dot("FormDataContentType").Call(),
+			).Line()
 
-		if p.Schema.Name != "" {
-			argCode.Add(gen.Qual(opts.TypesPackagePath, p.Schema.Name))
-		} else {
-			tp, err := g.GenerateType(ctx, p.Schema, generalOpts)
-			if err != nil {
-				return nil, err
-			}
-			argCode.Add(tp)
+			continue
 		}
 
 		var encoder string
@@ -338,6 +418,21 @@ func (s *StdLib) GenerateRequest(ctx context.Context, funcName jen.Code, url jen
 				newBuf = jen.Qual("bytes", "NewBuffer")
 			}
 
+			// With a body for each content type,
+			// only the one that is given is sent.
+			if len(contentTypeBodies(op)) > 1 {
+				marshalValues.If(jen.Id(p.Name).Op("!=").Nil()).Block(
+					marshalCode,
+					jen.Id("_bodyData").Op("=").Add(newBuf).Call(jen.Id(dataName)),
+				).Line().Line()
+
+				additionalStatements.If(jen.Id(p.Name).Op("!=").Nil()).Block(
+					jen.Id("_req").Dot("Header").Dot("Set").Call(jen.Lit("Content-Type"), jen.Lit(p.ContentType)),
+				).Line()
+
+				break
+			}
+
 			marshalValues.Add(marshalCode).Line().
 				Id("_bodyData").Op("=").Add(newBuf).Call(jen.Id(dataName)).
 				Line().Line()
@@ -362,12 +457,18 @@ func (s *StdLib) GenerateRequest(ctx context.Context, funcName jen.Code, url jen
 			marshalValues.Add(marshalCode).Line()
 			urlCode.Id(urlName).Op("=").Qual("strings", "Replace").Call(jen.Id(urlName), jen.Lit("{"+p.Name+"}"), jen.String().Call(jen.Id(dataName)), jen.Lit(1)).Line()
 		case spec.ParameterTypeQuery:
+			// Exploded arrays are sent as repeated keys.
+			if encoder == "" && p.Schema.Variant == spec.VariantArray && p.Serialization.Explode {
+				additionalStatements.For(jen.List(jen.Id("_"), jen.Id("_p")).Op(":=").Range().Id(p.Name)).Block(
+					jen.Id("_q").Dot("Add").Call(jen.Lit(p.Name), jen.Qual("fmt", "Sprint").Call(jen.Id("_p"))),
+				).Line()
+				break
+			}
+
 			marshalValues.Add(marshalCode).Line()
 			additionalStatements.Id("_q").Op(".").Id("Set").Call(jen.Lit(p.Name), jen.String().Call(jen.Id(dataName))).Line()
 
 		}
-
-		params = append(params, argCode)
 	}
 
 	templOpts.MarshalValues = marshalValues
@@ -380,3 +481,1589 @@ func (s *StdLib) GenerateRequest(ctx context.Context, funcName jen.Code, url jen
 
 	return gen.Template(templates.HTTPRequest, templOpts)
 }
+
+// operationParams returns the arguments of the generated
+// request function of the operation, without the URL.
+func (s *StdLib) operationParams(ctx context.Context, op *spec.Operation, generalOpts *GeneralOptions, opts *StdLibOptions) ([]jen.Code, error) {
+	params := make([]jen.Code, 0, len(op.Parameters))
+
+	for _, p := range op.Parameters {
+		reqParams, err := s.requestParams(ctx, p, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rp := range reqParams {
+			params = append(params, jen.Id(rp.Name).Add(rp.Type))
+		}
+	}
+
+	return params, nil
+}
+
+// requestParam is an argument of a generated request function.
+type requestParam struct {
+	Name string
+	Type jen.Code
+}
+
+// requestParams returns the arguments of the request functions for a parameter,
+// multipart bodies are passed by their fields with files as io.Reader.
+func (s *StdLib) requestParams(ctx context.Context, p *spec.Parameter, generalOpts *GeneralOptions, opts *StdLibOptions) ([]requestParam, error) {
+	if !isMultipartBody(p) {
+		tp, err := s.schemaType(ctx, p.Schema, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return []requestParam{{Name: p.Name, Type: tp}}, nil
+	}
+
+	fields := multipartFields(p)
+	reqParams := make([]requestParam, 0, len(fields))
+
+	for _, f := range fields {
+		var tp jen.Code
+
+		switch {
+		case f.File:
+			tp = jen.Qual("io", "Reader")
+		case f.Files:
+			tp = jen.Index().Qual("io", "Reader")
+		default:
+			fieldType, err := s.schemaType(ctx, f.Schema, generalOpts, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			if f.Ptr {
+				fieldType = jen.Op("*").Add(fieldType)
+			}
+
+			tp = fieldType
+		}
+
+		reqParams = append(reqParams, requestParam{Name: f.ArgName, Type: tp})
+	}
+
+	return reqParams, nil
+}
+
+// multipartField is a field of a multipart
+// body that is passed as a separate argument.
+type multipartField struct {
+	ArgName  string
+	FormName string
+	Schema   *spec.Schema
+
+	// File and Files mark binary fields
+	// that are passed as readers.
+	File  bool
+	Files bool
+
+	Ptr bool
+
+	// ContentType is the content type of the part
+	// from the encoding of the body, if any.
+	ContentType string
+}
+
+// isMultipartBody checks whether the parameter is a multipart/form-data body.
+func isMultipartBody(p *spec.Parameter) bool {
+	return p.Type == spec.ParameterTypeBody &&
+		strings.HasPrefix(p.ContentType, "multipart/form-data") &&
+		p.Schema != nil &&
+		p.Schema.Variant == spec.VariantStruct
+}
+
+// multipartFields returns the fields of a multipart body ordered by their names.
+func multipartFields(p *spec.Parameter) []multipartField {
+	fieldNames := make([]string, 0, len(p.Schema.Children.Map))
+	for name := range p.Schema.Children.Map {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	fields := make([]multipartField, 0, len(fieldNames))
+
+	for _, name := range fieldNames {
+		child := p.Schema.Children.Map[name]
+
+		formName := child.FieldName
+		if formName == "" {
+			formName = name
+		}
+
+		f := multipartField{
+			ArgName:  p.Name + name,
+			FormName: formName,
+			Schema:   child,
+			Ptr:      (child.Nullable || child.ShouldBePtr()) && !child.CanBeNil(),
+		}
+
+		// Only a single content type can be sent,
+		// the first one is used if there are more.
+		if enc, ok := p.Encoding[formName]; ok && enc != nil {
+			f.ContentType = strings.TrimSpace(strings.Split(enc.ContentType, ",")[0])
+		}
+
+		switch {
+		case child.Format == "binary":
+			f.File = true
+		case child.Variant == spec.VariantArray && child.HasChildren() &&
+			child.Children.Schema != nil && child.Children.Schema.Format == "binary":
+			f.Files = true
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// generateMultipartBody generates code that writes the
+// fields of a multipart body into the request body.
+//
+// The body is buffered instead of streamed, so that nothing is left
+// running if the request is not sent, and the request can be sent again
+// on redirects, as http.NewRequest sets GetBody for buffers.
+func (s *StdLib) generateMultipartBody(p *spec.Parameter) jen.Code {
+	writes := jen.Null()
+
+	returnErr := func(c jen.Code) jen.Code {
+		return jen.If(jen.Err().Op(":=").Add(c), jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err()))
+	}
+
+	// Parts with a content type from the encoding are created with
+	// their own headers, otherwise the defaults of the multipart writer are used.
+	createPart := func(f multipartField, fileName string) *jen.Statement {
+		disposition := fmt.Sprintf("form-data; name=%q", f.FormName)
+		if fileName != "" {
+			disposition += fmt.Sprintf("; filename=%q", fileName)
+		}
+
+		return jen.Id("_mw").Dot("CreatePart").Call(jen.Qual("net/textproto", "MIMEHeader").Values(jen.Dict{
+			jen.Lit("Content-Disposition"): jen.Index().String().Values(jen.Lit(disposition)),
+			jen.Lit("Content-Type"):        jen.Index().String().Values(jen.Lit(f.ContentType)),
+		}))
+	}
+
+	writeFile := func(f multipartField, reader string) jen.Code {
+		create := jen.Id("_mw").Dot("CreateFormFile").Call(jen.Lit(f.FormName), jen.Lit(f.FormName))
+		if f.ContentType != "" {
+			create = createPart(f, f.FormName)
+		}
+
+		return jen.If(jen.Id(reader).Op("!=").Nil()).Block(
+			jen.List(jen.Id("fw"), jen.Err()).Op(":=").Add(create),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.If(
+				jen.List(jen.Id("_"), jen.Err()).Op(":=").Qual("io", "Copy").Call(jen.Id("fw"), jen.Id(reader)),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+		)
+	}
+
+	writeValue := func(f multipartField, value jen.Code, schema *spec.Schema) jen.Code {
+		// Primitive parts are written as they are, unless
+		// the encoding of the part requires JSON.
+		if schema.Variant == spec.VariantPrimitive && !util.IsJSONMediaType(f.ContentType) {
+			if f.ContentType == "" {
+				return returnErr(jen.Id("_mw").Dot("WriteField").Call(
+					jen.Lit(f.FormName), jen.Qual("fmt", "Sprint").Call(value),
+				))
+			}
+
+			return jen.Block(
+				jen.List(jen.Id("pw"), jen.Err()).Op(":=").Add(createPart(f, "")),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				jen.If(
+					jen.List(jen.Id("_"), jen.Err()).Op(":=").Qual("fmt", "Fprint").Call(jen.Id("pw"), value),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err())),
+			)
+		}
+
+		// Other values are sent as JSON, unless
+		// the encoding of the part requires XML.
+		marshal := (&General{}).jsonCall("Marshal")
+		if isXMLContentType(f.ContentType) {
+			marshal = jen.Qual("encoding/xml", "Marshal")
+		}
+
+		if f.ContentType == "" {
+			return jen.Block(
+				jen.List(jen.Id("b"), jen.Err()).Op(":=").Add(marshal).Call(value),
+				jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+				returnErr(jen.Id("_mw").Dot("WriteField").Call(jen.Lit(f.FormName), jen.String().Call(jen.Id("b")))),
+			)
+		}
+
+		return jen.Block(
+			jen.List(jen.Id("b"), jen.Err()).Op(":=").Add(marshal).Call(value),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.List(jen.Id("pw"), jen.Err()).Op(":=").Add(createPart(f, "")),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())),
+			jen.If(
+				jen.List(jen.Id("_"), jen.Err()).Op(":=").Id("pw").Dot("Write").Call(jen.Id("b")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())),
+		)
+	}
+
+	for _, f := range multipartFields(p) {
+		switch {
+		case f.File:
+			writes.Add(writeFile(f, f.ArgName))
+		case f.Files:
+			writes.Add(jen.For(jen.List(jen.Id("_"), jen.Id("r")).Op(":=").Range().Id(f.ArgName)).Block(
+				writeFile(f, "r"),
+			))
+		case f.Ptr:
+			writes.Add(jen.If(jen.Id(f.ArgName).Op("!=").Nil()).Block(
+				writeValue(f, jen.Op("*").Id(f.ArgName), f.Schema),
+			))
+		case f.Schema.Variant == spec.VariantArray && f.Schema.HasChildren() &&
+			f.Schema.Children.Schema != nil && f.Schema.Children.Schema.Variant == spec.VariantPrimitive:
+			writes.Add(jen.For(jen.List(jen.Id("_"), jen.Id("v")).Op(":=").Range().Id(f.ArgName)).Block(
+				writeValue(f, jen.Id("v"), f.Schema.Children.Schema),
+			))
+		default:
+			writes.Add(writeValue(f, jen.Id(f.ArgName), f.Schema))
+		}
+
+		writes.Line()
+	}
+
+	writes.Add(jen.Return(jen.Id("_mw").Dot("Close").Call()))
+
+	return gen.MustTemplate(`_buf := &{{ .Buffer }}{}
+	_mw := {{ .NewWriter }}(_buf)
+
+	if _err := func() error {
+		{{ .Writes }}
+	}(); _err != nil {
+		return nil, _err
+	}
+
+	_bodyData = _buf`,
+		gen.Values{
+			"Buffer":    jen.Qual("bytes", "Buffer"),
+			"NewWriter": jen.Qual("mime/multipart", "NewWriter"),
+			"Writes":    writes,
+		},
+	)
+}
+
+// schemaType returns the type of a schema
+// used in parameters and responses.
+func (s *StdLib) schemaType(ctx context.Context, schema *spec.Schema, generalOpts *GeneralOptions, opts *StdLibOptions) (jen.Code, error) {
+	if schema.Name != "" {
+		return gen.Qual(opts.TypesPackagePath, schema.Name), nil
+	}
+
+	g := &General{}
+
+	return g.GenerateType(ctx, schema, generalOpts)
+}
+
+// GenerateFullClient generates a client that executes the requests
+// created by the client target, and decodes the responses.
+func (s *StdLib) GenerateFullClient(ctx context.Context, specification *spec.Spec, opts *StdLibOptions) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	g := &General{}
+	generalOpts, err := g.GetOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	generalOpts.TypesPackagePath = opts.TypesPackagePath
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// Client sends requests to the API, and decodes the responses.").Line()
+	}
+	code.Type().Id("Client").StructFunc(func(g *jen.Group) {
+		if options.Comments {
+			g.Comment("// Server is the base URL of the API.")
+		}
+		g.Id("Server").String()
+		g.Line()
+		if options.Comments {
+			g.Comment("// HTTPClient sends the requests, http.DefaultClient is used if it is nil.")
+		}
+		g.Id("HTTPClient").Op("*").Qual("net/http", "Client")
+		g.Line()
+		if options.Comments {
+			g.Comment("// Transport replaces the transport of HTTPClient if it is not nil,")
+			g.Comment("// it can be used to add authentication, logging")
+			g.Comment("// or to intercept the requests in tests.")
+		}
+		g.Id("Transport").Qual("net/http", "RoundTripper")
+	}).Line().Line()
+
+	if options.Comments {
+		code.Comment("// ClientOption configures a client created by NewClient.").Line()
+	}
+	code.Type().Id("ClientOption").Func().Params(jen.Op("*").Id("Client")).Line().Line()
+
+	if options.Comments {
+		code.Comment("// WithHTTPClient sets the HTTP client that sends the requests.").Line()
+	}
+	code.Func().Id("WithHTTPClient").Params(jen.Id("httpClient").Op("*").Qual("net/http", "Client")).Id("ClientOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("c").Op("*").Id("Client")).Block(
+			jen.Id("c").Dot("HTTPClient").Op("=").Id("httpClient"),
+		)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// WithTransport sets the transport that sends the requests,").Line()
+		code.Comment("// the other settings of the HTTP client are kept.").Line()
+	}
+	code.Func().Id("WithTransport").Params(jen.Id("transport").Qual("net/http", "RoundTripper")).Id("ClientOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("c").Op("*").Id("Client")).Block(
+			jen.Id("c").Dot("Transport").Op("=").Id("transport"),
+		)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// NewClient returns a new client for the given server.").Line()
+	}
+	code.Func().Id("NewClient").Params(
+		jen.Id("server").String(),
+		jen.Id("opts").Op("...").Id("ClientOption"),
+	).Params(jen.Op("*").Id("Client")).Block(
+		jen.Id("c").Op(":=").Op("&").Id("Client").Values(jen.Dict{
+			jen.Id("Server"): jen.Id("server"),
+		}),
+		jen.Line(),
+		jen.For(jen.List(jen.Id("_"), jen.Id("o")).Op(":=").Range().Id("opts")).Block(
+			jen.Id("o").Call(jen.Id("c")),
+		),
+		jen.Line(),
+		jen.Return(jen.Id("c")),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// ClientError is returned by the client for unexpected response status codes.").Line()
+	}
+	code.Type().Id("ClientError").StructFunc(func(g *jen.Group) {
+		if options.Comments {
+			g.Comment("// StatusCode is the status code of the response.")
+		}
+		g.Id("StatusCode").Int()
+		g.Line()
+		if options.Comments {
+			g.Comment("// Body is the raw body of the response.")
+		}
+		g.Id("Body").Index().Byte()
+		g.Line()
+		if options.Comments {
+			g.Comment("// Decoded is the decoded body of the response if it is")
+			g.Comment("// described by the specification, otherwise it is nil.")
+		}
+		g.Id("Decoded").Interface()
+	}).Line().Line()
+
+	if options.Comments {
+		code.Comment("// Error implements error.").Line()
+	}
+	code.Func().Params(jen.Id("e").Op("*").Id("ClientError")).Id("Error").Params().Params(jen.String()).Block(
+		jen.Return(jen.Qual("fmt", "Sprintf").Call(
+			jen.Lit("unexpected response status %v: %s"),
+			jen.Id("e").Dot("StatusCode"),
+			jen.Id("e").Dot("Body"),
+		)),
+	).Line().Line()
+
+	code.Add(gen.MustTemplate(`func (c *Client) do(req *{{ .Request }}) (*{{ .Response }}, []byte, error) {
+		httpClient := c.HTTPClient
+		if httpClient == nil {
+			httpClient = {{ .DefaultClient }}
+		}
+
+		if c.Transport != nil {
+			// Copy the client so that its timeout,
+			// cookie jar and redirect policy are kept.
+			withTransport := *httpClient
+			withTransport.Transport = c.Transport
+			httpClient = &withTransport
+		}
+
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer res.Body.Close()
+
+		body, err := {{ .ReadAll }}(res.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return res, body, nil
+	}`,
+		gen.Values{
+			"Request":       jen.Qual("net/http", "Request"),
+			"Response":      jen.Qual("net/http", "Response"),
+			"DefaultClient": jen.Qual("net/http", "DefaultClient"),
+			"ReadAll":       jen.Qual("io/ioutil", "ReadAll"),
+		},
+	)).Line().Line()
+
+	if options.Comments {
+		code.Comment("// decodeBody decodes the body of a response based on its Content-Type header,").Line()
+		code.Comment("// the documented content type is used if the header is missing.").Line()
+	}
+	code.Add(gen.MustTemplate(`func decodeBody(res *{{ .Response }}, body []byte, v interface{}, documentedType string) error {
+		contentType := res.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = documentedType
+		}
+
+		mediaType, _, err := {{ .ParseMediaType }}(contentType)
+		if err != nil {
+			mediaType = documentedType
+		}
+
+		if mediaType == "application/xml" || mediaType == "text/xml" || {{ .HasSuffix }}(mediaType, "+xml") {
+			return {{ .XMLUnmarshal }}(body, v)
+		}
+
+		return {{ .JSONUnmarshal }}(body, v)
+	}`,
+		gen.Values{
+			"Response":       jen.Qual("net/http", "Response"),
+			"ParseMediaType": jen.Qual("mime", "ParseMediaType"),
+			"HasSuffix":      jen.Qual("strings", "HasSuffix"),
+			"XMLUnmarshal":   jen.Qual("encoding/xml", "Unmarshal"),
+			"JSONUnmarshal":  jen.Qual("encoding/json", "Unmarshal"),
+		},
+	)).Line().Line()
+
+	code.Add(s.generateCallOptions(options)).Line().Line()
+
+	for _, p := range specification.Paths {
+		for _, o := range p.Operations {
+			opCode, err := s.generateFullClientOperation(ctx, p, o, generalOpts, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			code.Add(opCode).Line().Line()
+
+			if o.Pagination != nil {
+				generatePagination := s.generatePaginationHelper
+				if o.Pagination.CursorParam != "" {
+					generatePagination = s.generateCursorPaginationHelper
+				}
+
+				pageCode, err := generatePagination(ctx, o, generalOpts, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				code.Add(pageCode)
+			}
+		}
+	}
+
+	if opts.UniformClient {
+		uniformCode, err := s.generateUniformClient(ctx, specification, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		code.Add(uniformCode)
+	}
+
+	return code, nil
+}
+
+// operationResultType returns the type of the decoded body
+// of the successful response of an operation, or nil if there is none.
+func (s *StdLib) operationResultType(
+	ctx context.Context,
+	o *spec.Operation,
+	generalOpts *GeneralOptions,
+	opts *StdLibOptions,
+) (jen.Code, error) {
+	success := s.successResponse(o)
+	if success == nil {
+		return nil, nil
+	}
+
+	tp, err := s.schemaType(ctx, success.Schema, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if success.IsPtr() {
+		tp = jen.Op("*").Add(tp)
+	}
+
+	return tp, nil
+}
+
+// generateUniformClient generates request and response types for
+// each operation, and a Do method on the client that sends any of them.
+func (s *StdLib) generateUniformClient(
+	ctx context.Context,
+	specification *spec.Spec,
+	generalOpts *GeneralOptions,
+	opts *StdLibOptions,
+) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	requestName := opts.UniformTypePrefix + "Request"
+	responseName := opts.UniformTypePrefix + "Response"
+	doerName := opts.UniformTypePrefix + "Doer"
+	doerFuncName := opts.UniformTypePrefix + "DoerFunc"
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v is implemented by the request types of all operations.", requestName).Line()
+	}
+	code.Type().Id(requestName).Interface(
+		jen.Id("OperationName").Params().String(),
+	).Line().Line()
+
+	if options.Comments {
+		code.Commentf("// %v is implemented by the response types of all operations.", responseName).Line()
+	}
+	code.Type().Id(responseName).Interface(
+		jen.Id("OperationName").Params().String(),
+	).Line().Line()
+
+	if options.Comments {
+		code.Commentf("// %v sends a request of any operation, and returns its response.", doerName).Line()
+		code.Comment("// It is implemented by Client, and can be wrapped to add middleware.").Line()
+	}
+	code.Type().Id(doerName).Interface(
+		jen.Id("Do").Params(
+			jen.Id("ctx").Qual("context", "Context"),
+			jen.Id("req").Id(requestName),
+		).Params(jen.Id(responseName), jen.Error()),
+	).Line().Line()
+
+	if options.Comments {
+		code.Commentf("// %v is a function that implements %v.", doerFuncName, doerName).Line()
+	}
+	code.Type().Id(doerFuncName).Func().Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("req").Id(requestName),
+	).Params(jen.Id(responseName), jen.Error()).Line().Line()
+
+	if options.Comments {
+		code.Commentf("// Do implements %v.", doerName).Line()
+	}
+	code.Func().Params(jen.Id("f").Id(doerFuncName)).Id("Do").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("req").Id(requestName),
+	).Params(jen.Id(responseName), jen.Error()).Block(
+		jen.Return(jen.Id("f").Call(jen.Id("ctx"), jen.Id("req"))),
+	).Line().Line()
+
+	code.Add(gen.AssertImplements(jen.Id(doerName), jen.Op("&").Id("Client").Values())).Line().Line()
+
+	cases := make([]jen.Code, 0)
+
+	// The request is only bound in the type switch
+	// if any of the operations have parameters.
+	bindRequest := false
+
+	for _, p := range specification.Paths {
+		for _, o := range p.Operations {
+			reqName := o.Name + opts.UniformRequestPostfix
+			resName := o.Name + opts.UniformResponsePostfix
+
+			fields := make([]jen.Code, 0, len(o.Parameters))
+			args := []jen.Code{jen.Id("ctx")}
+
+			for _, param := range o.Parameters {
+				reqParams, err := s.requestParams(ctx, param, generalOpts, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, rp := range reqParams {
+					fieldName := common.Initialisms(ctx).ToGoName(strcase.ToCamel(rp.Name))
+
+					fields = append(fields, jen.Id(fieldName).Add(rp.Type))
+					args = append(args, jen.Id("r").Dot(fieldName))
+					bindRequest = true
+				}
+			}
+
+			resultType, err := s.operationResultType(ctx, o, generalOpts, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			headersRes := s.headersResponse(o)
+
+			if options.Comments {
+				code.Commentf("// %v contains the parameters of the %v operation.", reqName, o.Name).Line()
+			}
+			code.Type().Id(reqName).Struct(fields...).Line().Line()
+
+			if options.Comments {
+				code.Commentf("// %v is the response of the %v operation.", resName, o.Name).Line()
+			}
+			code.Type().Id(resName).StructFunc(func(g *jen.Group) {
+				if resultType != nil {
+					if options.Comments {
+						g.Comment("// Result is the decoded body of the successful response.")
+					}
+					g.Id("Result").Add(resultType)
+				}
+				if headersRes != nil {
+					if options.Comments {
+						g.Comment("// Headers are the headers of the successful response.")
+					}
+					g.Id("Headers").Id(o.Name + "ResponseHeaders")
+				}
+			}).Line().Line()
+
+			for _, name := range []string{reqName, resName} {
+				if options.Comments {
+					code.Commentf("// OperationName returns the name of the operation of %v.", name).Line()
+				}
+				code.Func().Params(jen.Id(name)).Id("OperationName").Params().String().Block(
+					jen.Return(jen.Lit(o.Name)),
+				).Line().Line()
+			}
+
+			call := jen.Id("c").Dot(o.Name).Call(args...)
+
+			if headersRes != nil {
+				results := make([]jen.Code, 0, 3)
+				values := jen.Dict{jen.Id("Headers"): jen.Id("headers")}
+				if resultType != nil {
+					results = append(results, jen.Id("result"))
+					values[jen.Id("Result")] = jen.Id("result")
+				}
+				results = append(results, jen.Id("headers"), jen.Err())
+
+				cases = append(cases, jen.Case(jen.Id(reqName)).Block(
+					jen.List(results...).Op(":=").Id("c").Dot(o.Name+"WithHeaders").Call(args...),
+					jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+					jen.Return(jen.Id(resName).Values(values), jen.Nil()),
+				))
+			} else if resultType != nil {
+				cases = append(cases, jen.Case(jen.Id(reqName)).Block(
+					jen.List(jen.Id("result"), jen.Err()).Op(":=").Add(call),
+					jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Nil(), jen.Err())),
+					jen.Return(jen.Id(resName).Values(jen.Dict{
+						jen.Id("Result"): jen.Id("result"),
+					}), jen.Nil()),
+				))
+			} else {
+				cases = append(cases, jen.Case(jen.Id(reqName)).Block(
+					jen.If(jen.Err().Op(":=").Add(call), jen.Err().Op("!=").Nil()).Block(
+						jen.Return(jen.Nil(), jen.Err()),
+					),
+					jen.Return(jen.Id(resName).Values(), jen.Nil()),
+				))
+			}
+		}
+	}
+
+	cases = append(cases, jen.Default().Block(
+		jen.Return(jen.Nil(), jen.Qual("fmt", "Errorf").Call(jen.Lit("unknown request type %T"), jen.Id("req"))),
+	))
+
+	typeSwitch := jen.Id("req").Assert(jen.Type())
+	if bindRequest {
+		typeSwitch = jen.Id("r").Op(":=").Add(typeSwitch)
+	}
+
+	if options.Comments {
+		code.Comment("// Do sends the request of an operation with the matching method of the client,").Line()
+		code.Comment("// and returns the response of the operation.").Line()
+	}
+	code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id("Do").Params(
+		jen.Id("ctx").Qual("context", "Context"),
+		jen.Id("req").Id(requestName),
+	).Params(jen.Id(responseName), jen.Error()).Block(
+		jen.Switch(typeSwitch).Block(cases...),
+	).Line().Line()
+
+	return code, nil
+}
+
+// generateFullClientOperation generates the client method for an operation.
+func (s *StdLib) generateFullClientOperation(
+	ctx context.Context,
+	p *spec.Path,
+	o *spec.Operation,
+	generalOpts *GeneralOptions,
+	opts *StdLibOptions,
+) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	params := []jen.Code{jen.Id("ctx").Qual("context", "Context")}
+	args := make([]jen.Code, 0, len(o.Parameters))
+
+	for _, param := range o.Parameters {
+		reqParams, err := s.requestParams(ctx, param, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rp := range reqParams {
+			params = append(params, jen.Id(rp.Name).Add(rp.Type))
+			args = append(args, jen.Id(rp.Name))
+		}
+	}
+
+	params = append(params, jen.Id("callOpts").Op("...").Id("CallOption"))
+
+	success := s.successResponse(o)
+
+	resultType, err := s.operationResultType(ctx, o, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	headersRes := s.headersResponse(o)
+
+	returnErr := func(err jen.Code) jen.Code {
+		results := make([]jen.Code, 0, 3)
+		if resultType != nil {
+			results = append(results, jen.Id("_result"))
+		}
+		if headersRes != nil {
+			results = append(results, jen.Id("_headers"))
+		}
+		return jen.Return(append(results, err)...)
+	}
+
+	code := jen.Null()
+
+	body := make([]jen.Code, 0)
+
+	if resultType != nil {
+		body = append(body, jen.Var().Id("_result").Add(resultType).Line())
+	}
+
+	var parseHeaders []jen.Code
+
+	if headersRes != nil {
+		headersCode, parse, err := s.generateResponseHeaders(ctx, o, headersRes, generalOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		code.Add(headersCode).Line().Line()
+		parseHeaders = parse
+
+		body = append(body, jen.Var().Id("_headers").Id(o.Name+"ResponseHeaders").Line())
+	}
+
+	body = append(body,
+		jen.List(jen.Id("_req"), jen.Err()).Op(":=").Id(p.Name+"Client").Call(jen.Id("c").Dot("Server")).
+			Dot(o.Name).Call(args...),
+		jen.If(jen.Err().Op("!=").Nil()).Block(returnErr(jen.Err())).Line(),
+
+		s.generateAcceptHeader(o),
+
+		jen.Var().Id("_opts").Id("callOptions"),
+		jen.For(jen.List(jen.Id("_"), jen.Id("_o")).Op(":=").Range().Id("callOpts")).Block(
+			jen.Id("_o").Call(jen.Op("&").Id("_opts")),
+		),
+		jen.If(jen.Id("_opts").Dot("accept").Op("!=").Lit("")).Block(
+			jen.Id("_req").Dot("Header").Dot("Set").Call(jen.Lit("Accept"), jen.Id("_opts").Dot("accept")),
+		),
+		s.generateReencodeBody(o, returnErr),
+		jen.Line(),
+
+		jen.List(jen.Id("_res"), jen.Id("_body"), jen.Err()).Op(":=").Id("c").Dot("do").
+			Call(jen.Id("_req").Dot("WithContext").Call(jen.Id("ctx"))),
+		jen.If(jen.Err().Op("!=").Nil()).Block(returnErr(jen.Err())).Line(),
+
+		jen.If(
+			jen.Id("_res").Dot("StatusCode").Op("<").Lit(200).Op("||").
+				Id("_res").Dot("StatusCode").Op(">").Lit(299),
+		).Block(
+			jen.Id("_clientErr").Op(":=").Op("&").Id("ClientError").Values(jen.Dict{
+				jen.Id("StatusCode"): jen.Id("_res").Dot("StatusCode"),
+				jen.Id("Body"):       jen.Id("_body"),
+			}),
+			s.generateDecodeErrors(ctx, o, generalOpts, opts),
+			returnErr(jen.Id("_clientErr")),
+		).Line(),
+	)
+
+	body = append(body, parseHeaders...)
+
+	if resultType != nil {
+		body = append(body,
+			jen.If(
+				jen.Err().Op(":=").Id("decodeBody").Call(
+					jen.Id("_res"), jen.Id("_body"), jen.Op("&").Id("_result"), jen.Lit(success.ContentType),
+				),
+				jen.Err().Op("!=").Nil(),
+			).Block(returnErr(jen.Err())).Line(),
+		)
+	}
+
+	body = append(body, returnErr(jen.Nil()))
+
+	resultTypes := make([]jen.Code, 0, 3)
+	if resultType != nil {
+		resultTypes = append(resultTypes, resultType)
+	}
+	if headersRes != nil {
+		resultTypes = append(resultTypes, jen.Id(o.Name+"ResponseHeaders"))
+	}
+
+	results := jen.Error()
+	if len(resultTypes) > 0 {
+		results = jen.Params(append(resultTypes, jen.Error())...)
+	}
+
+	methodName := o.Name
+	if headersRes != nil {
+		methodName += "WithHeaders"
+	}
+
+	if options.Comments {
+		if headersRes != nil {
+			code.Commentf("// %v sends a request for the %v operation,", methodName, o.Name).Line()
+			code.Comment("// and also returns the headers of a successful response.").Line()
+		} else {
+			code.Commentf("// %v sends a request for the %v operation.", methodName, o.Name).Line()
+		}
+		if resultType != nil {
+			code.Comment("// The body of a successful response is decoded and returned,").Line()
+			code.Comment("// otherwise a *ClientError is returned.").Line()
+		} else {
+			code.Comment("// A *ClientError is returned if the response is not successful.").Line()
+		}
+	}
+
+	code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(methodName).Params(params...).Add(results).Block(body...)
+
+	if headersRes == nil {
+		return code, nil
+	}
+
+	// The method without the headers is kept for the pagination
+	// helpers, and for the clients that do not need the headers.
+	code.Line().Line()
+
+	callArgs := append([]jen.Code{jen.Id("ctx")}, args...)
+	call := jen.Id("c").Dot(methodName).Call(append(callArgs, jen.Id("callOpts").Op("..."))...)
+
+	if options.Comments {
+		code.Commentf("// %v sends a request for the %v operation.", o.Name, o.Name).Line()
+		code.Commentf("// It is the same as %v without the headers.", methodName).Line()
+	}
+
+	if resultType != nil {
+		code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(o.Name).Params(params...).Params(resultType, jen.Error()).Block(
+			jen.List(jen.Id("_result"), jen.Id("_"), jen.Err()).Op(":=").Add(call),
+			jen.Return(jen.Id("_result"), jen.Err()),
+		)
+	} else {
+		code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(o.Name).Params(params...).Error().Block(
+			jen.List(jen.Id("_"), jen.Err()).Op(":=").Add(call),
+			jen.Return(jen.Err()),
+		)
+	}
+
+	return code, nil
+}
+
+// generateCallOptions generates the options that can be
+// passed to the methods of the full client for each call.
+func (s *StdLib) generateCallOptions(options *common.Options) jen.Code {
+	code := jen.Null()
+
+	if options.Comments {
+		code.Comment("// CallOption changes how a request is sent by the methods of the client.").Line()
+	}
+	code.Type().Id("CallOption").Func().Params(jen.Op("*").Id("callOptions")).Line().Line()
+
+	code.Type().Id("callOptions").Struct(
+		jen.Id("contentType").String(),
+		jen.Id("accept").String(),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// WithContentType sets the content type of the request body,").Line()
+		code.Comment("// and the body is encoded accordingly, JSON and XML are supported.").Line()
+		code.Comment("// It has no effect on operations without a JSON or XML body.").Line()
+	}
+	code.Func().Id("WithContentType").Params(jen.Id("contentType").String()).Id("CallOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("callOptions")).Block(
+			jen.Id("o").Dot("contentType").Op("=").Id("contentType"),
+		)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// WithAccept sets the Accept header of the request,").Line()
+		code.Comment("// replacing the content types documented for the responses.").Line()
+	}
+	code.Func().Id("WithAccept").Params(jen.Id("accept").String()).Id("CallOption").Block(
+		jen.Return(jen.Func().Params(jen.Id("o").Op("*").Id("callOptions")).Block(
+			jen.Id("o").Dot("accept").Op("=").Id("accept"),
+		)),
+	).Line().Line()
+
+	if options.Comments {
+		code.Comment("// encodeBody encodes a request body with the encoder of the content type.").Line()
+	}
+	code.Add(gen.MustTemplate(`func encodeBody(contentType string, v interface{}) ([]byte, error) {
+		mediaType, _, err := {{ .ParseMediaType }}(contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		if mediaType == "application/xml" || mediaType == "text/xml" || {{ .HasSuffix }}(mediaType, "+xml") {
+			return {{ .XMLMarshal }}(v)
+		}
+
+		if mediaType == "application/json" || {{ .HasSuffix }}(mediaType, "+json") {
+			return {{ .JSONMarshal }}(v)
+		}
+
+		return nil, {{ .Errorf }}("unsupported content type %v", contentType)
+	}`,
+		gen.Values{
+			"ParseMediaType": jen.Qual("mime", "ParseMediaType"),
+			"HasSuffix":      jen.Qual("strings", "HasSuffix"),
+			"XMLMarshal":     jen.Qual("encoding/xml", "Marshal"),
+			"JSONMarshal":    (&General{}).jsonCall("Marshal"),
+			"Errorf":         jen.Qual("fmt", "Errorf"),
+		},
+	))
+
+	return code
+}
+
+// generateReencodeBody generates the statements that encode the body
+// of the request named "req" again if a content type is given in the
+// call options named "_opts".
+//
+// Only JSON and XML bodies are supported, with a body for each content type
+// the one for the given content type is used, and it is an error if there is none.
+func (s *StdLib) generateReencodeBody(o *spec.Operation, returnErr func(err jen.Code) jen.Code) jen.Code {
+	all := contentTypeBodies(o)
+
+	bodies := make([]*spec.Parameter, 0, len(all))
+	for _, body := range all {
+		if !isMultipartBody(body) && isDecodableContentType(body.ContentType) {
+			bodies = append(bodies, body)
+		}
+	}
+
+	if len(bodies) == 0 {
+		return jen.Null()
+	}
+
+	encode := func(value jen.Code) []jen.Code {
+		return []jen.Code{
+			jen.List(jen.Id("_data"), jen.Err()).Op(":=").Id("encodeBody").Call(jen.Id("_opts").Dot("contentType"), value),
+			jen.If(jen.Err().Op("!=").Nil()).Block(returnErr(jen.Err())),
+			jen.Id("_req").Dot("Body").Op("=").Qual("io/ioutil", "NopCloser").Call(jen.Qual("bytes", "NewReader").Call(jen.Id("_data"))),
+			jen.Id("_req").Dot("GetBody").Op("=").Func().Params().Params(jen.Qual("io", "ReadCloser"), jen.Error()).Block(
+				jen.Return(jen.Qual("io/ioutil", "NopCloser").Call(jen.Qual("bytes", "NewReader").Call(jen.Id("_data"))), jen.Nil()),
+			),
+			jen.Id("_req").Dot("ContentLength").Op("=").Int64().Call(jen.Len(jen.Id("_data"))),
+			jen.Id("_req").Dot("Header").Dot("Set").Call(jen.Lit("Content-Type"), jen.Id("_opts").Dot("contentType")),
+		}
+	}
+
+	cond := jen.Id("_opts").Dot("contentType").Op("!=").Lit("")
+
+	if len(all) == 1 {
+		body := bodies[0]
+
+		if body.IsPtr() {
+			cond.Op("&&").Id(body.Name).Op("!=").Nil()
+		}
+
+		return jen.If(cond).Block(encode(jen.Id(body.Name))...)
+	}
+
+	// The bodies of the other content types are nil.
+	cases := make([]jen.Code, 0, len(bodies)+1)
+	for _, body := range bodies {
+		cases = append(cases, jen.Case(
+			jen.Qual("strings", "EqualFold").Call(jen.Id("_mediaType"), jen.Lit(mediaType(body.ContentType))).
+				Op("&&").Id(body.Name).Op("!=").Nil(),
+		).Block(
+			jen.Id("_value").Op("=").Id(body.Name),
+		))
+	}
+	cases = append(cases, jen.Default().Block(
+		returnErr(jen.Qual("fmt", "Errorf").Call(jen.Lit("no request body for the content type %v"), jen.Id("_opts").Dot("contentType"))),
+	))
+
+	block := []jen.Code{
+		jen.Var().Id("_value").Interface(),
+		jen.Switch(
+			jen.Id("_mediaType").Op(":=").Qual("strings", "TrimSpace").Call(
+				jen.Qual("strings", "Split").Call(jen.Id("_opts").Dot("contentType"), jen.Lit(";")).Index(jen.Lit(0)),
+			),
+			jen.Empty(),
+		).Block(cases...),
+	}
+
+	return jen.If(cond).Block(append(block, encode(jen.Id("_value"))...)...)
+}
+
+// headersResponse returns the successful response of an operation
+// with headers that are returned by the full client, or nil if there is none.
+//
+// It is the response with the decoded body if there is one.
+func (s *StdLib) headersResponse(o *spec.Operation) *spec.Response {
+	if success := s.successResponse(o); success != nil {
+		if len(success.Headers) == 0 {
+			return nil
+		}
+		return success
+	}
+
+	var headersRes *spec.Response
+
+	for _, res := range o.Responses {
+		if !strings.HasPrefix(res.Code, "2") || len(res.Headers) == 0 {
+			continue
+		}
+
+		if headersRes == nil || res.Code < headersRes.Code {
+			headersRes = res
+		}
+	}
+
+	return headersRes
+}
+
+// generateResponseHeaders generates the type of the headers of a response,
+// and the statements that parse them from a *http.Response named "res"
+// into a variable named "headers".
+//
+// Numeric, boolean and time headers are parsed into their types,
+// and the rest of the headers are kept as strings. Values that
+// cannot be parsed are ignored, so they do not fail successful requests.
+func (s *StdLib) generateResponseHeaders(
+	ctx context.Context,
+	o *spec.Operation,
+	res *spec.Response,
+	generalOpts *GeneralOptions,
+) (jen.Code, []jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	typeName := o.Name + "ResponseHeaders"
+
+	fields := make([]jen.Code, 0, len(res.Headers))
+	parse := make([]jen.Code, 0, len(res.Headers))
+
+	for _, h := range res.Headers {
+		fieldName := common.Initialisms(ctx).ToGoName(strcase.ToCamel(h.Name))
+
+		var tp jen.Code
+		parsed, parseErr := parseHeaderValue(h.Name, h.Schema, jen.Id("_v"))
+		if parsed == nil {
+			tp = jen.String()
+			parsed = jen.Id("_v")
+		} else {
+			var err error
+			tp, err = (&General{}).GenerateType(ctx, h.Schema, generalOpts)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		isPtr := !h.Required
+
+		fieldType := jen.Null()
+		if isPtr {
+			fieldType.Op("*")
+		}
+		fieldType.Add(tp)
+
+		if options.Comments {
+			comment := fmt.Sprintf("%v is the value of the %v header.", fieldName, h.Name)
+			if h.Description != "" {
+				comment += " " + strings.TrimSpace(h.Description)
+			}
+			fields = append(fields, gen.Comments(comment))
+		}
+		fields = append(fields, jen.Id(fieldName).Add(fieldType))
+
+		assign := func(value jen.Code) []jen.Code {
+			if isPtr {
+				return []jen.Code{
+					jen.Id("_value").Op(":=").Add(value),
+					jen.Id("_headers").Dot(fieldName).Op("=").Op("&").Id("_value"),
+				}
+			}
+			return []jen.Code{jen.Id("_headers").Dot(fieldName).Op("=").Add(value)}
+		}
+
+		var set []jen.Code
+		if !parseErr {
+			set = assign(parsed)
+		} else {
+			set = []jen.Code{jen.If(
+				jen.List(jen.Id("_parsed"), jen.Err()).Op(":=").Add(parsed),
+				jen.Err().Op("==").Nil(),
+			).Block(assign(convertParsedHeader(h.Schema, jen.Id("_parsed")))...)}
+		}
+
+		parse = append(parse, jen.If(
+			jen.Id("_v").Op(":=").Id("_res").Dot("Header").Dot("Get").Call(jen.Lit(h.Name)),
+			jen.Id("_v").Op("!=").Lit(""),
+		).Block(set...))
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %v contains the headers of a successful response of the %v operation.", typeName, o.Name).Line()
+	}
+	code.Type().Id(typeName).Struct(fields...)
+
+	return code, append(parse, jen.Line()), nil
+}
+
+// httpDateHeaders are the headers that contain
+// HTTP-dates (RFC 7231) instead of RFC 3339 timestamps.
+var httpDateHeaders = map[string]bool{
+	"Date":                true,
+	"Expires":             true,
+	"If-Modified-Since":   true,
+	"If-Unmodified-Since": true,
+	"Last-Modified":       true,
+}
+
+// parseHeaderValue returns the expression that parses a header
+// string value for the schema, and whether it also returns an error.
+//
+// It returns nil if the value is kept as a string.
+func parseHeaderValue(name string, schema *spec.Schema, value jen.Code) (jen.Code, bool) {
+	if schema == nil || schema.Variant != spec.VariantPrimitive || schema.Name != "" {
+		return nil, false
+	}
+
+	switch schema.PrimitiveType {
+	case "string":
+		return value, false
+	case "bool":
+		return jen.Qual("strconv", "ParseBool").Call(value), true
+	case "int", "int8", "int16", "int32", "int64":
+		return jen.Qual("strconv", "ParseInt").Call(value, jen.Lit(10), jen.Lit(bitSize(schema.PrimitiveType))), true
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return jen.Qual("strconv", "ParseUint").Call(value, jen.Lit(10), jen.Lit(bitSize(schema.PrimitiveType))), true
+	case "float32", "float64":
+		return jen.Qual("strconv", "ParseFloat").Call(value, jen.Lit(bitSize(schema.PrimitiveType))), true
+	case "time.Time":
+		switch {
+		case httpDateHeaders[http.CanonicalHeaderKey(name)] || schema.Format == "http-date":
+			return jen.Qual("net/http", "ParseTime").Call(value), true
+		case schema.Format == "date":
+			return jen.Qual("time", "Parse").Call(jen.Lit("2006-01-02"), value), true
+		default:
+			return jen.Qual("time", "Parse").Call(jen.Qual("time", "RFC3339"), value), true
+		}
+	default:
+		return nil, false
+	}
+}
+
+// convertParsedHeader converts the value parsed
+// by parseHeaderValue to the type of the schema.
+func convertParsedHeader(schema *spec.Schema, parsed jen.Code) jen.Code {
+	switch schema.PrimitiveType {
+	case "int64", "uint64", "float64", "bool", "time.Time":
+		return parsed
+	default:
+		return jen.Id(schema.PrimitiveType).Call(parsed)
+	}
+}
+
+// bitSize returns the bit size of a numeric
+// type for strconv, 0 for int and uint.
+func bitSize(primitiveType string) int {
+	size, _ := strconv.Atoi(strings.TrimLeft(primitiveType, "intuflo"))
+	return size
+}
+
+// generatePaginationHelper generates a method for a paginated operation
+// that fetches the pages until there are no more items, and passes the items
+// to a function one by one.
+func (s *StdLib) generatePaginationHelper(
+	ctx context.Context,
+	o *spec.Operation,
+	generalOpts *GeneralOptions,
+	opts *StdLibOptions,
+) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	success := s.successResponse(o)
+	if success == nil || success.Schema.Variant != spec.VariantArray || !success.Schema.HasChildren() {
+		return jen.Null(), nil
+	}
+
+	var limit, offset *spec.Parameter
+
+	for _, p := range o.Parameters {
+		if p.Type != spec.ParameterTypeQuery {
+			continue
+		}
+
+		switch p.Name {
+		case o.Pagination.LimitParam:
+			limit = p
+		case o.Pagination.OffsetParam:
+			offset = p
+		}
+	}
+
+	if limit == nil || offset == nil || !isNumericSchema(limit.Schema) || !isNumericSchema(offset.Schema) {
+		return jen.Null(), nil
+	}
+
+	item := success.Schema.Children.Schema
+
+	itemType, err := s.schemaType(ctx, item, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if (item.Nullable || item.ShouldBePtr()) && !item.CanBeNil() {
+		itemType = jen.Op("*").Add(itemType)
+	}
+
+	offsetType, err := s.schemaType(ctx, offset.Schema, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []jen.Code{jen.Id("ctx").Qual("context", "Context")}
+	args := []jen.Code{jen.Id("ctx")}
+
+	for _, p := range o.Parameters {
+		reqParams, err := s.requestParams(ctx, p, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rp := range reqParams {
+			args = append(args, jen.Id(rp.Name))
+
+			if p == offset {
+				continue
+			}
+
+			params = append(params, jen.Id(rp.Name).Add(rp.Type))
+		}
+	}
+
+	params = append(params, jen.Id("yield").Func().Params(itemType).Error())
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %vEach sends requests for the %v operation", o.Name, o.Name).Line()
+		code.Commentf("// with increasing %v until there are no more items,", offset.Name).Line()
+		code.Comment("// and calls yield for each item in the responses.").Line()
+		code.Comment("// ").Line()
+		code.Comment("// Iteration stops at the first error returned by a request or yield.").Line()
+	}
+
+	code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(o.Name+"Each").Params(params...).Error().Block(
+		jen.Var().Id(offset.Name).Add(offsetType).Line(),
+		jen.For().Block(
+			jen.List(jen.Id("pageItems"), jen.Err()).Op(":=").Id("c").Dot(o.Name).Call(args...),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())).Line(),
+
+			jen.For(jen.List(jen.Id("_"), jen.Id("pageItem")).Op(":=").Range().Id("pageItems")).Block(
+				jen.If(
+					jen.Err().Op(":=").Id("yield").Call(jen.Id("pageItem")),
+					jen.Err().Op("!=").Nil(),
+				).Block(jen.Return(jen.Err())),
+			).Line(),
+
+			jen.If(
+				jen.Len(jen.Id("pageItems")).Op("==").Lit(0).Op("||").
+					Parens(jen.Id(limit.Name).Op(">").Lit(0).Op("&&").
+						Len(jen.Id("pageItems")).Op("<").Int().Call(jen.Id(limit.Name))),
+			).Block(jen.Return(jen.Nil())).Line(),
+
+			jen.Id(offset.Name).Op("+=").Add(offsetType).Call(jen.Len(jen.Id("pageItems"))),
+		),
+	).Line().Line()
+
+	return code, nil
+}
+
+// generateCursorPaginationHelper generates a method for an operation with
+// cursor-based pagination that fetches the pages by passing the cursor of the
+// next page from each response to the next request, and passes the responses
+// to a function one by one.
+func (s *StdLib) generateCursorPaginationHelper(
+	ctx context.Context,
+	o *spec.Operation,
+	generalOpts *GeneralOptions,
+	opts *StdLibOptions,
+) (jen.Code, error) {
+	options, ok := ctx.Value(common.ContextCommonOptions).(*common.Options)
+	if !ok {
+		options = common.DefaultOptions()
+	}
+
+	success := s.successResponse(o)
+	if success == nil || success.Schema.Variant != spec.VariantStruct {
+		return jen.Null(), nil
+	}
+
+	var cursor *spec.Parameter
+
+	for _, p := range o.Parameters {
+		if p.Type == spec.ParameterTypeQuery && p.Name == o.Pagination.CursorParam {
+			cursor = p
+			break
+		}
+	}
+
+	if cursor == nil || !isStringSchema(cursor.Schema) {
+		return jen.Null(), nil
+	}
+
+	var nextName string
+	var next *spec.Schema
+
+	for name, child := range success.Schema.Children.Map {
+		if child.FieldName == o.Pagination.NextCursorField {
+			nextName = name
+			next = child
+			break
+		}
+	}
+
+	if next == nil || !isStringSchema(next) {
+		return jen.Null(), nil
+	}
+
+	resultType, err := s.operationResultType(ctx, o, generalOpts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	params := []jen.Code{jen.Id("ctx").Qual("context", "Context")}
+	args := []jen.Code{jen.Id("ctx")}
+
+	for _, p := range o.Parameters {
+		reqParams, err := s.requestParams(ctx, p, generalOpts, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rp := range reqParams {
+			args = append(args, jen.Id(rp.Name))
+
+			if p == cursor {
+				continue
+			}
+
+			params = append(params, jen.Id(rp.Name).Add(rp.Type))
+		}
+	}
+
+	params = append(params, jen.Id("yield").Func().Params(resultType).Error())
+
+	// The cursor of the next page might be optional.
+	nextCursor := jen.Id("pageResult").Dot(nextName)
+	lastPage := jen.Id("pageResult").Dot(nextName).Op("==").Lit("")
+
+	if (next.Nullable || next.ShouldBePtr()) && !next.CanBeNil() {
+		nextCursor = jen.Op("*").Id("pageResult").Dot(nextName)
+		lastPage = jen.Id("pageResult").Dot(nextName).Op("==").Nil().Op("||").
+			Op("*").Id("pageResult").Dot(nextName).Op("==").Lit("")
+	}
+
+	if success.IsPtr() {
+		lastPage = jen.Id("pageResult").Op("==").Nil().Op("||").Add(lastPage)
+	}
+
+	code := jen.Null()
+
+	if options.Comments {
+		code.Commentf("// %vPages sends requests for the %v operation", o.Name, o.Name).Line()
+		code.Commentf("// with the %v of the previous response as %v until it is empty,", nextName, cursor.Name).Line()
+		code.Comment("// and calls yield for each response.").Line()
+		code.Comment("// ").Line()
+		code.Comment("// Iteration stops at the first error returned by a request or yield.").Line()
+	}
+
+	code.Func().Params(jen.Id("c").Op("*").Id("Client")).Id(o.Name+"Pages").Params(params...).Error().Block(
+		jen.Var().Id(cursor.Name).String().Line(),
+		jen.For().Block(
+			jen.List(jen.Id("pageResult"), jen.Err()).Op(":=").Id("c").Dot(o.Name).Call(args...),
+			jen.If(jen.Err().Op("!=").Nil()).Block(jen.Return(jen.Err())).Line(),
+
+			jen.If(
+				jen.Err().Op(":=").Id("yield").Call(jen.Id("pageResult")),
+				jen.Err().Op("!=").Nil(),
+			).Block(jen.Return(jen.Err())).Line(),
+
+			jen.If(lastPage).Block(jen.Return(jen.Nil())).Line(),
+
+			jen.Id(cursor.Name).Op("=").Add(nextCursor),
+		),
+	).Line().Line()
+
+	return code, nil
+}
+
+// isStringSchema checks whether the schema is a plain string.
+func isStringSchema(schema *spec.Schema) bool {
+	return schema != nil && schema.Variant == spec.VariantPrimitive &&
+		schema.Name == "" && schema.PrimitiveType == "string"
+}
+
+// isNumericSchema checks whether the schema is a number.
+func isNumericSchema(schema *spec.Schema) bool {
+	if schema == nil || schema.Variant != spec.VariantPrimitive || schema.Name != "" {
+		return false
+	}
+
+	return strings.HasPrefix(schema.PrimitiveType, "int") ||
+		strings.HasPrefix(schema.PrimitiveType, "uint") ||
+		strings.HasPrefix(schema.PrimitiveType, "float")
+}
+
+// generateDecodeErrors generates code that decodes
+// the body of a non-successful response into clientErr.Decoded
+// if the response status is described in the specification.
+func (s *StdLib) generateDecodeErrors(ctx context.Context, o *spec.Operation, generalOpts *GeneralOptions, opts *StdLibOptions) jen.Code {
+	var exact, ranges, defaults []jen.Code
+
+	seen := make(map[string]bool)
+
+	for _, res := range o.Responses {
+		if res.Schema == nil || !isDecodableContentType(res.ContentType) || seen[res.Code] {
+			continue
+		}
+
+		code := strings.ToUpper(res.Code)
+
+		if strings.HasPrefix(code, "2") {
+			continue
+		}
+
+		tp, err := s.schemaType(ctx, res.Schema, generalOpts, opts)
+		if err != nil {
+			continue
+		}
+
+		seen[res.Code] = true
+
+		decode := []jen.Code{
+			jen.Var().Id("_decoded").Add(tp),
+			jen.If(
+				jen.Id("decodeBody").Call(
+					jen.Id("_res"), jen.Id("_body"), jen.Op("&").Id("_decoded"), jen.Lit(res.ContentType),
+				).Op("==").Nil(),
+			).Block(
+				jen.Id("_clientErr").Dot("Decoded").Op("=").Id("_decoded"),
+			),
+		}
+
+		switch {
+		case code == "DEFAULT":
+			defaults = append(defaults, jen.Default().Block(decode...))
+		case strings.HasSuffix(code, "XX"):
+			ranges = append(ranges, jen.Case(
+				jen.Id("_res").Dot("StatusCode").Op("/").Lit(100).Op("==").Lit(int(code[0]-'0')),
+			).Block(decode...))
+		default:
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				continue
+			}
+			exact = append(exact, jen.Case(jen.Id("_res").Dot("StatusCode").Op("==").Lit(status)).Block(decode...))
+		}
+	}
+
+	cases := append(append(exact, ranges...), defaults...)
+
+	if len(cases) == 0 {
+		return jen.Null()
+	}
+
+	return jen.Switch().Block(cases...)
+}
+
+// successResponse returns the first successful response
+// of the operation with a body that can be decoded.
+func (s *StdLib) successResponse(o *spec.Operation) *spec.Response {
+	var success *spec.Response
+
+	for _, res := range o.Responses {
+		if !strings.HasPrefix(res.Code, "2") || res.Schema == nil || isEmptyStatus(res.Code) ||
+			!isDecodableContentType(res.ContentType) {
+			continue
+		}
+
+		if success == nil || res.Code < success.Code {
+			success = res
+		}
+	}
+
+	return success
+}
+
+// isDecodableContentType checks whether the full client
+// can decode a body with the content type.
+func isDecodableContentType(contentType string) bool {
+	return util.IsJSONMediaType(contentType) || isXMLContentType(contentType)
+}
+
+// generateAcceptHeader generates code that sets the Accept header
+// of the request named "_req" to the response content types of the operation.
+func (s *StdLib) generateAcceptHeader(o *spec.Operation) jen.Code {
+	contentTypes := make([]string, 0, len(o.Responses))
+	seen := make(map[string]bool)
+
+	for _, res := range o.Responses {
+		if res.ContentType == "" || seen[res.ContentType] {
+			continue
+		}
+
+		seen[res.ContentType] = true
+		contentTypes = append(contentTypes, res.ContentType)
+	}
+
+	if len(contentTypes) == 0 {
+		return jen.Null()
+	}
+
+	sort.Strings(contentTypes)
+
+	return jen.Id("_req").Dot("Header").Dot("Set").
+		Call(jen.Lit("Accept"), jen.Lit(strings.Join(contentTypes, ", "))).Line()
+}
@@ -0,0 +1,588 @@
+package golang
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func TestMultipartRequest(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}/photos": {
+      "post": {
+        "operationId": "uploadPhotos",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "requestBody": {
+          "content": {
+            "multipart/form-data": {
+              "schema": {
+                "type": "object",
+                "required": ["photo"],
+                "properties": {
+                  "photo": {"type": "string", "format": "binary"},
+                  "thumbnails": {"type": "array", "items": {"type": "string", "format": "binary"}},
+                  "caption": {"type": "string"}
+                }
+              }
+            }
+          }
+        },
+        "responses": {"204": {"description": "Uploaded"}}
+      }
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, data, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, data, &StdLib{}, "client", nil)
+	src := string(clientSrc)
+
+	assert.MatchRegex(t, src, `bodyPhoto io\.Reader`)
+	assert.MatchRegex(t, src, `bodyThumbnails \[\]io\.Reader`)
+	assert.MatchRegex(t, src, `bodyCaption \*string`)
+	assert.Equal(t, strings.Contains(src, `_mw.CreateFormFile("photo", "photo")`), true)
+	assert.Equal(t, strings.Contains(src, `_mw.WriteField("caption", fmt.Sprint(*bodyCaption))`), true)
+	assert.Equal(t, strings.Contains(src, `_req.Header.Set("Content-Type", _mw.FormDataContentType())`), true)
+
+	// The body is buffered, so that http.NewRequest sets GetBody.
+	assert.Equal(t, strings.Contains(src, `_mw := multipart.NewWriter(_buf)`), true)
+	assert.Equal(t, strings.Contains(src, `_bodyData = _buf`), true)
+	assert.Equal(t, strings.Contains(src, "io.Pipe"), false)
+
+	typeCheck(t, typesSrc, clientSrc)
+}
+
+func TestClientRelativeServerBasePath(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &StdLib{}, "client", nil))
+	assert.Equal(t, strings.Contains(src, `"/api/v1/pets/{id}"`), true)
+
+	src = string(generateTestFile(t, basePathTestSpec, &StdLib{}, "client", map[string]interface{}{
+		"ignoreBasePath": true,
+	}))
+	assert.Equal(t, strings.Contains(src, "/api/v1"), false)
+}
+
+func TestClientTrailingSlashServer(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &StdLib{}, "client", nil))
+	assert.Equal(t, strings.Contains(src, "url += "), false)
+	assert.MatchRegex(t, src, `url = strings\.TrimRight\(url, "/"\) \+ "/api/v1/pets/\{id\}"`)
+
+	src = string(generateTestFile(t, basePathTestSpec, &StdLib{}, "client", map[string]interface{}{
+		"ignoreBasePath": true,
+	}))
+	assert.MatchRegex(t, src, `url = strings\.TrimRight\(url, "/"\) \+ "/pets/\{id\}"`)
+	assert.Equal(t, strings.Contains(src, `"//`), false)
+}
+
+func TestClientInterface(t *testing.T) {
+	src := string(generateTestFile(t, basePathTestSpec, &StdLib{}, "client", nil))
+
+	assert.MatchRegex(t, src, `type (\w+)ClientInterface interface \{\s+DeletePet\(id string\) \(\*http\.Request, error\)\s+\}`)
+	assert.MatchRegex(t, src, `var _ (\w+)ClientInterface = client(\w+)\{\}`)
+}
+
+func TestClientExplodedQueryArrays(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "string"}}},
+          {"name": "ids", "in": "query", "explode": false, "schema": {"type": "array", "items": {"type": "integer"}}}
+        ],
+        "responses": {"204": {"description": "ok"}}
+      }
+    }
+  }
+}`, &StdLib{}, "client", nil))
+
+	assert.MatchRegex(t, src, `for _, _p := range tags \{\s+_q\.Add\("tags", fmt\.Sprint\(_p\)\)\s+\}`)
+	assert.MatchRegex(t, src, `_q\.Set\("ids", string\(idsData\)\)`)
+	assert.Equal(t, strings.Contains(src, "tagsData"), false)
+}
+
+func TestMultipartEncoding(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/photos": {
+      "post": {
+        "operationId": "uploadPhoto",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "multipart/form-data": {
+              "schema": {
+                "type": "object",
+                "properties": {
+                  "photo": {"type": "string", "format": "binary"},
+                  "meta": {"type": "object", "properties": {"title": {"type": "string"}}},
+                  "caption": {"type": "string"}
+                }
+              },
+              "encoding": {
+                "photo": {"contentType": "image/png, image/jpeg"},
+                "meta": {"contentType": "application/json"},
+                "caption": {"contentType": "application/json"}
+              }
+            }
+          }
+        },
+        "responses": {"204": {"description": "Uploaded"}}
+      }
+    }
+  }
+}`
+
+	src := string(generateTestFile(t, spec, &StdLib{}, "client", nil))
+	assert.MatchRegex(t, src, `"Content-Disposition":\s+\[\]string\{"form-data; name=\\"photo\\"; filename=\\"photo\\""\}`)
+	assert.MatchRegex(t, src, `"Content-Type":\s+\[\]string\{"image/png"\}`)
+	assert.MatchRegex(t, src, `"Content-Type":\s+\[\]string\{"application/json"\}`)
+	assert.Equal(t, strings.Contains(src, `_mw.CreateFormFile(`), false)
+
+	// Primitive parts are JSON-encoded as well.
+	assert.Equal(t, strings.Contains(src, `json.Marshal(*bodyCaption)`), true)
+	assert.Equal(t, strings.Contains(src, `fmt.Fprint(`), false)
+
+	src = string(generateTestFile(t, spec, &Echo{}, "server", nil))
+	assert.Equal(t, strings.Contains(src, `json.Unmarshal([]byte(_part), &body.Meta)`), true)
+	assert.Equal(t, strings.Contains(src, `json.Unmarshal([]byte(_part), &body.Caption)`), true)
+}
+
+func TestFullClientResponseHeaders(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "headers", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "headers": {
+              "X-RateLimit-Remaining": {"required": true, "schema": {"type": "integer", "format": "int32"}},
+              "X-RateLimit-Reset": {"schema": {"type": "string", "format": "date-time"}},
+              "Last-Modified": {"schema": {"type": "string", "format": "date-time"}}
+            },
+            "content": {"application/json": {"schema": {"type": "array", "items": {"type": "string"}}}}
+          }
+        }
+      }
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, data, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, data, &StdLib{}, "client", nil)
+	fullClientSrc := generateTestFile(t, data, &StdLib{}, "full-client", nil)
+	src := string(fullClientSrc)
+
+	assert.MatchRegex(t, src, `XRateLimitRemaining\s+int32\n`)
+	assert.MatchRegex(t, src, `XRateLimitReset\s+\*time\.Time\n`)
+	assert.MatchRegex(t, src, `func \(c \*Client\) ListPetsWithHeaders\(ctx context\.Context\) \([^,]+, ListPetsResponseHeaders, error\)`)
+	assert.MatchRegex(t, src, `func \(c \*Client\) ListPets\(ctx context\.Context\) \([^,]+, error\)`)
+	assert.Equal(t, strings.Contains(src, `strconv.ParseInt(_v, 10, 32)`), true)
+	assert.Equal(t, strings.Contains(src, `_headers.XRateLimitRemaining = int32(_parsed)`), true)
+	assert.Equal(t, strings.Contains(src, `time.Parse(time.RFC3339, _v)`), true)
+	assert.Equal(t, strings.Contains(src, `http.ParseTime(_v)`), true)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc)
+}
+
+func TestFullClientContentType(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+        },
+        "responses": {"204": {"description": "Added"}}
+      },
+      "put": {
+        "operationId": "updatePet",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/Pet"}},
+            "application/xml": {"schema": {"$ref": "#/components/schemas/PetXML"}}
+          }
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "PetXML": {"type": "object", "properties": {"title": {"type": "string"}}}
+    }
+  }
+}`
+
+	// The bodies of updatePet are kept for each content type.
+	transformerOpts := map[string]interface{}{
+		"contentTypeBodies": true,
+	}
+
+	typesSrc := generateTransformedTestFile(t, data, transformerOpts, &General{}, "types", nil)
+	clientSrc := generateTransformedTestFile(t, data, transformerOpts, &StdLib{}, "client", nil)
+	fullClientSrc := generateTransformedTestFile(t, data, transformerOpts, &StdLib{}, "full-client", nil)
+	src := string(fullClientSrc)
+
+	assert.Equal(t, strings.Contains(src, "func WithContentType(contentType string) CallOption"), true)
+	assert.Equal(t, strings.Contains(src, "func WithAccept(accept string) CallOption"), true)
+	assert.MatchRegex(t, src, `func \(c \*Client\) AddPet\(ctx context\.Context, body \*?Pet, callOpts \.\.\.CallOption\) error`)
+	assert.Equal(t, strings.Contains(src, `_data, err := encodeBody(_opts.contentType, body)`), true)
+	assert.Equal(t, strings.Contains(src, `req.Header.Set("Content-Type", _opts.contentType)`), true)
+
+	// With a body for each content type, the body of the given one is encoded.
+	assert.MatchRegex(t, src, `func \(c \*Client\) UpdatePet\(ctx context\.Context, bodyJSON \*Pet, bodyXML \*PetXML, callOpts \.\.\.CallOption\) error`)
+	assert.Equal(t, strings.Contains(src, `_data, err := encodeBody(_opts.contentType, _value)`), true)
+	assert.Equal(t, strings.Contains(src, `"no request body for the content type %v"`), true)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc)
+}
+
+func TestBodyContentTypePriority(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "put": {
+        "operationId": "updatePet",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/Pet"}},
+            "application/xml": {"schema": {"$ref": "#/components/schemas/PetXML"}}
+          }
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "PetXML": {"type": "object", "properties": {"title": {"type": "string"}}}
+    }
+  }
+}`
+
+	// Only the JSON body is kept by default.
+	typesSrc := generateTestFile(t, data, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, data, &StdLib{}, "client", nil)
+	src := string(clientSrc)
+
+	assert.Equal(t, strings.Contains(src, `_req.Header.Add("Content-Type", "application/json")`), true)
+	assert.Equal(t, strings.Contains(src, "application/xml"), false)
+	assert.Equal(t, strings.Contains(src, "PetXML"), false)
+
+	typeCheck(t, typesSrc, clientSrc)
+
+	src = string(generateTransformedTestFile(t, data, map[string]interface{}{
+		"bodyContentTypes": []string{"application/xml", "application/json"},
+	}, &StdLib{}, "client", nil))
+
+	assert.Equal(t, strings.Contains(src, `_req.Header.Add("Content-Type", "application/xml")`), true)
+	assert.Equal(t, strings.Contains(src, "application/json"), false)
+	assert.Equal(t, strings.Contains(src, "PetXML"), true)
+}
+
+func TestExcludePathsAndOperations(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"204": {"description": "Pets"}}}
+    },
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Pet"}}
+      },
+      "delete": {
+        "operationId": "deletePet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    },
+    "/admin/users/{id}": {
+      "delete": {
+        "operationId": "deleteUser",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`
+
+	src := string(generateTransformedTestFile(t, data, map[string]interface{}{
+		"excludePaths":      []string{"/admin/**"},
+		"excludeOperations": []string{"deletePet"},
+	}, &StdLib{}, "client", nil))
+
+	assert.Equal(t, strings.Contains(src, "ListPets"), true)
+	assert.Equal(t, strings.Contains(src, "GetPet"), true)
+	assert.Equal(t, strings.Contains(src, "DeletePet"), false)
+	assert.Equal(t, strings.Contains(src, "DeleteUser"), false)
+	assert.Equal(t, strings.Contains(src, "/admin/users/"), false)
+}
+
+func TestFullClientTransport(t *testing.T) {
+	src := string(generateTestFile(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "delete": {
+        "operationId": "deletePets",
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`, &StdLib{}, "full-client", nil))
+
+	assert.MatchRegex(t, src, `HTTPClient\s+\*http\.Client`)
+	assert.MatchRegex(t, src, `Transport\s+http\.RoundTripper`)
+	assert.Equal(t, strings.Contains(src, "func NewClient(server string, opts ...ClientOption) *Client"), true)
+	assert.Equal(t, strings.Contains(src, "func WithHTTPClient(httpClient *http.Client) ClientOption"), true)
+	assert.Equal(t, strings.Contains(src, "func WithTransport(transport http.RoundTripper) ClientOption"), true)
+	// The settings of the HTTP client are kept with a custom transport.
+	assert.Equal(t, strings.Contains(src, "withTransport := *httpClient"), true)
+}
+
+const fullClientTestSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "parameters": [{"name": "res", "in": "query", "schema": {"type": "string"}}],
+        "requestBody": {
+          "required": true,
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}
+        },
+        "responses": {
+          "200": {"description": "Pet", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+          "default": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Error": {"type": "object", "properties": {"message": {"type": "string"}}}
+    }
+  }
+}`
+
+func TestFullClientCompiles(t *testing.T) {
+	typesSrc := generateTestFile(t, fullClientTestSpec, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, fullClientTestSpec, &StdLib{}, "client", nil)
+	fullClientSrc := generateTestFile(t, fullClientTestSpec, &StdLib{}, "full-client", nil)
+
+	// The parameters must not collide with the locals of the client.
+	assert.Equal(t, strings.Contains(string(fullClientSrc), "_res, _body, err := c.do(_req.WithContext(ctx))"), true)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc)
+}
+
+func TestFullClientPagination(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "kind", "in": "query", "schema": {"type": "string"}},
+          {"name": "limit", "in": "query", "schema": {"type": "integer", "format": "int32"}},
+          {"name": "offset", "in": "query", "schema": {"type": "integer", "format": "int32"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}}
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, spec, &StdLib{}, "client", nil)
+	fullClientSrc := generateTestFile(t, spec, &StdLib{}, "full-client", nil)
+	src := string(fullClientSrc)
+
+	// The offset is managed by the helper, the other parameters are passed through.
+	assert.MatchRegex(t, src, `func \(c \*Client\) ListPetsEach\(ctx context\.Context, kind string, limit int32, yield func\(\*Pet\) error\) error`)
+	assert.Equal(t, strings.Contains(src, "c.ListPets(ctx, kind, limit, offset)"), true)
+	// A short page is the last one.
+	assert.Equal(t, strings.Contains(src, "len(pageItems) == 0 || (limit > 0 && len(pageItems) < int(limit))"), true)
+	assert.Equal(t, strings.Contains(src, "offset += int32(len(pageItems))"), true)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc, []byte(`package types
+
+import "context"
+
+func countPets(c *Client) (int, error) {
+	count := 0
+
+	err := c.ListPetsEach(context.Background(), "dog", 10, func(pet *Pet) error {
+		count++
+		return nil
+	})
+
+	return count, err
+}
+`))
+}
+
+func TestFullClientCursorPagination(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "kind", "in": "query", "schema": {"type": "string"}},
+          {"name": "cursor", "in": "query", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/PetPage"}}}
+          }
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "PetPage": {
+        "type": "object",
+        "properties": {
+          "items": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}},
+          "next_cursor": {"type": "string"}
+        }
+      }
+    }
+  }
+}`
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, spec, &StdLib{}, "client", nil)
+	fullClientSrc := generateTestFile(t, spec, &StdLib{}, "full-client", nil)
+	src := string(fullClientSrc)
+
+	// The cursor is managed by the helper, the other parameters are passed through.
+	assert.MatchRegex(t, src, `func \(c \*Client\) ListPetsPages\(ctx context\.Context, kind string, yield func\(\*PetPage\) error\) error`)
+	assert.Equal(t, strings.Contains(src, "c.ListPets(ctx, kind, cursor)"), true)
+	// The optional next cursor ends the iteration if it is missing or empty.
+	assert.Equal(t, strings.Contains(src, `pageResult == nil || pageResult.NextCursor == nil || *pageResult.NextCursor == ""`), true)
+	assert.Equal(t, strings.Contains(src, "cursor = *pageResult.NextCursor"), true)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc, []byte(`package types
+
+import "context"
+
+func allPets(c *Client) ([]*Pet, error) {
+	var pets []*Pet
+
+	err := c.ListPetsPages(context.Background(), "dog", func(page *PetPage) error {
+		pets = append(pets, page.Items...)
+		return nil
+	})
+
+	return pets, err
+}
+`))
+}
+
+func TestUniformClient(t *testing.T) {
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/requests/{id}": {
+      "get": {
+        "operationId": "getRequest",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {
+          "200": {"description": "Request", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Request"}}}}
+        }
+      },
+      "delete": {
+        "operationId": "deleteRequest",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Request": {"type": "object", "properties": {"id": {"type": "string"}}},
+      "Response": {"type": "object", "properties": {"id": {"type": "string"}}},
+      "Doer": {"type": "string"}
+    }
+  }
+}`
+
+	opts := map[string]interface{}{"uniformClient": true}
+
+	typesSrc := generateTestFile(t, spec, &General{}, "types", nil)
+	clientSrc := generateTestFile(t, spec, &StdLib{}, "client", opts)
+	fullClientSrc := generateTestFile(t, spec, &StdLib{}, "full-client", opts)
+	src := string(fullClientSrc)
+
+	assert.Equal(t, strings.Contains(src, "func (c *Client) Do(ctx context.Context, req ClientRequest) (ClientResponse, error)"), true)
+	assert.MatchRegex(t, src, `type ClientDoerFunc func`)
+	assert.MatchRegex(t, src, `case GetRequestRequest:`)
+	assert.MatchRegex(t, src, `case DeleteRequestRequest:`)
+
+	typeCheck(t, typesSrc, clientSrc, fullClientSrc)
+
+	opts["uniformTypePrefix"] = "Pets"
+
+	src = string(generateTestFile(t, spec, &StdLib{}, "full-client", opts))
+	assert.Equal(t, strings.Contains(src, "func (c *Client) Do(ctx context.Context, req PetsRequest) (PetsResponse, error)"), true)
+	assert.MatchRegex(t, src, `type PetsDoer interface`)
+
+	opts["uniformTypePrefix"] = "1"
+
+	_, err := generateTestCode(t, spec, &StdLib{}, "full-client", opts)
+	assert.NotEqual(t, err, nil)
+}
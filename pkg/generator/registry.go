@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   []Generator
+)
+
+// Register makes a generator available to the CLI.
+//
+// External packages can call it from their init function, so that
+// a custom main only has to import them for their side effects.
+// It panics if a generator with the same name was already registered.
+func Register(g Generator) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if g == nil {
+		panic("generator: Register generator is nil")
+	}
+
+	for _, r := range registry {
+		if r.Name() == g.Name() {
+			panic(fmt.Sprintf("generator: Register called twice for generator %v", g.Name()))
+		}
+	}
+
+	registry = append(registry, g)
+}
+
+// Registered returns the registered generators in the order of registration.
+func Registered() []Generator {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	return append([]Generator(nil), registry...)
+}
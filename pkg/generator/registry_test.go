@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tamasfe/repose/pkg/spec"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+type testGenerator struct {
+	name string
+}
+
+func (g testGenerator) Name() string                { return g.name }
+func (g testGenerator) Description() string         { return "Test generator" }
+func (g testGenerator) Targets() map[string]string  { return map[string]string{"test": "Test target"} }
+func (g testGenerator) DefaultOptions() interface{} { return nil }
+
+func (g testGenerator) Generate(ctx context.Context, options interface{}, sp *spec.Spec, target string) (interface{}, error) {
+	return nil, nil
+}
+
+func TestRegister(t *testing.T) {
+	Register(testGenerator{name: "test-register"})
+
+	registered := Registered()
+	assert.Equal(t, registered[len(registered)-1].Name(), "test-register")
+
+	// The returned slice is a copy.
+	registered[len(registered)-1] = nil
+	assert.NotEqual(t, Registered()[len(registered)-1], nil)
+
+	defer func() {
+		assert.NotEqual(t, recover(), nil)
+	}()
+
+	Register(testGenerator{name: "test-register"})
+}
@@ -6,12 +6,20 @@ import (
 	jsonstd "encoding/json"
 	"errors"
 	"fmt"
+	"go/token"
+	gotypes "go/types"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/tamasfe/repose/internal/markdown"
 	"github.com/tamasfe/repose/pkg/common"
@@ -26,17 +34,36 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/tamasfe/repose/pkg/errs"
 	"github.com/tamasfe/repose/pkg/spec"
+	"gopkg.in/yaml.v3"
 )
 
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
 // OpenAPI3Options are options for the OpenAPI 3 parser.
 type OpenAPI3Options struct {
-	ExtensionName            string `yaml:"extensionName,omitempty" description:"The name of the extension field"`
-	ResolveReferencesAt      string `yaml:"resolveReferencesAt,omitempty" description:"Resolve references at the given URL"`
-	ResolveReferencesIn      string `yaml:"resolveReferencesIn,omitempty" description:"Resolve references in a local folder"`
-	AdditionalPropertiesName string `yaml:"additionalPropertiesName" description:"Name of the additionalProperties field in structs that have them"`
-	StripExtension           bool   `yaml:"stripExtension" description:"Strip the repose extension from the specification, the spec extension is used for code generation, and in most cases it's useless after that. Removing it for public APIs is also generally a good idea, where the specification will be visible"`
+	ExtensionName            string            `yaml:"extensionName,omitempty" description:"The name of the extension field"`
+	ResolveReferencesAt      string            `yaml:"resolveReferencesAt,omitempty" description:"Resolve references at the given URL"`
+	ResolveReferencesIn      string            `yaml:"resolveReferencesIn,omitempty" description:"Resolve references in a local folder"`
+	ResolveTimeout           int               `yaml:"resolveTimeout" description:"Time limit of resolving references in seconds, 0 means no limit"`
+	ResolveMaxDepth          int               `yaml:"resolveMaxDepth" description:"Maximum nesting of referenced documents when resolving references, 0 means no limit"`
+	AdditionalPropertiesName string            `yaml:"additionalPropertiesName" description:"Name of the additionalProperties field in structs that have them"`
+	StringFormatMap          map[string]string `yaml:"stringFormatMap,omitempty" description:"Go types for string formats (e.g. uuid: github.com/google/uuid.UUID), formats that are not listed are handled by default"`
+	StripExtension           bool              `yaml:"stripExtension" description:"Strip the repose extension from the specification, the spec extension is used for code generation, and in most cases it's useless after that. Removing it for public APIs is also generally a good idea, where the specification will be visible"`
+	BodyNameFromSchema       bool              `yaml:"bodyNameFromSchema" description:"Name request body parameters after their schemas (e.g. newPet for NewPet) instead of body if the schema is a reference"`
+	UseTitleAsName           bool              `yaml:"useTitleAsName" description:"Name the component schemas after their titles instead of their keys if the titles are valid Go identifiers"`
+
+	// schemaNames are the names of the component
+	// schemas by their keys if they are not the keys.
+	schemaNames map[string]string
+}
+
+// schemaName returns the name of the component schema with the given key.
+func (o *OpenAPI3Options) schemaName(key string) string {
+	if name, ok := o.schemaNames[key]; ok {
+		return name
+	}
+
+	return key
 }
 
 // MarshalYAML implements YAML Marshaler
@@ -55,6 +82,23 @@ func (o *OpenAPI3PathExtension) MarshalYAML() (interface{}, error) {
 	return util.MarshalYAMLWithDescriptions(o)
 }
 
+// OpenAPI3OperationExtension is for specifications that support extensions.
+// With it, a specification can alter the properties of code generation of the operation.
+type OpenAPI3OperationExtension struct {
+	Paginate        *bool   `yaml:"paginate,omitempty" json:"paginate,omitempty" description:"Whether the operation is paginated with limit and offset query parameters or with a cursor, by default operations with array responses and both parameters, or with the cursor parameter and a response with the next cursor are paginated"`
+	LimitParam      *string `yaml:"limitParam,omitempty" json:"limitParam,omitempty" description:"Name of the query parameter for the maximum number of items (limit by default)"`
+	OffsetParam     *string `yaml:"offsetParam,omitempty" json:"offsetParam,omitempty" description:"Name of the query parameter for the index of the first item (offset by default)"`
+	CursorParam     *string `yaml:"cursorParam,omitempty" json:"cursorParam,omitempty" description:"Name of the query parameter for the cursor of the page (cursor by default)"`
+	NextCursorField *string `yaml:"nextCursorField,omitempty" json:"nextCursorField,omitempty" description:"Name of the string property of the response with the cursor of the next page that is empty on the last page (next_cursor or nextCursor by default)"`
+	BodyName        *string `yaml:"bodyName,omitempty" json:"bodyName,omitempty" description:"Name of the request body parameter, it is renamed if another parameter has the same name"`
+	MaxBodyBytes    *int64  `yaml:"maxBodyBytes,omitempty" json:"maxBodyBytes,omitempty" description:"Maximum size of the request body in bytes, overrides the limit in the generator options"`
+}
+
+// MarshalYAML implements YAML Marshaler
+func (o *OpenAPI3OperationExtension) MarshalYAML() (interface{}, error) {
+	return util.MarshalYAMLWithDescriptions(o)
+}
+
 // OpenAPI3ResponseExtension is for specifications that support extensions.
 // With it, a specification can alter the properties of code generation of the path.
 type OpenAPI3ResponseExtension struct {
@@ -69,10 +113,12 @@ func (o *OpenAPI3ResponseExtension) MarshalYAML() (interface{}, error) {
 // OpenAPI3SchemaExtension is for specifications that support extensions.
 // A specification can alter the properties of code generation of the schema with it.
 type OpenAPI3SchemaExtension struct {
-	Type     *string             `yaml:"type,omitempty" json:"type,omitempty" description:"The Go type of the schema"`
-	Create   *bool               `yaml:"create,omitempty" json:"create,omitempty" description:"Whether the type should be created"`
-	CanBeNil *bool               `yaml:"canBeNil,omitempty" json:"canBeNil,omitempty" description:"Whether the type can be nil, and should not have a pointer to it (e.g. slices, maps, or interfaces), it is only needed when a custom Go type is set, but create is set to false, so only the type name is known to Repose"`
-	Tags     map[string][]string `yaml:"tags,omitempty" json:"tags,omitempty" description:"Additional tags for the field"`
+	Type      *string             `yaml:"type,omitempty" json:"type,omitempty" description:"The Go type of the schema"`
+	Create    *bool               `yaml:"create,omitempty" json:"create,omitempty" description:"Whether the type should be created"`
+	CanBeNil  *bool               `yaml:"canBeNil,omitempty" json:"canBeNil,omitempty" description:"Whether the type can be nil, and should not have a pointer to it (e.g. slices, maps, or interfaces), it is only needed when a custom Go type is set, but create is set to false, so only the type name is known to Repose"`
+	Tags      map[string][]string `yaml:"tags,omitempty" json:"tags,omitempty" description:"Additional tags for the field"`
+	KeyType   *string             `yaml:"keyType,omitempty" json:"keyType,omitempty" description:"The Go type of the keys of the map generated for additionalProperties, it must be a string type, it overrides the type from propertyNames"`
+	Sensitive *bool               `yaml:"sensitive,omitempty" json:"sensitive,omitempty" description:"Whether the value is sensitive, and should be redacted when the type is printed, by default values with the password format are sensitive"`
 }
 
 // MarshalYAML implements YAML Marshaler
@@ -118,6 +164,9 @@ however resolving external resources are supported by kin-openapi.
 The parser supports several [extensions](https://swagger.io/docs/specification/openapi-extensions/)
 that can be used in the specification to enhance code generation.
 
+The common "x-enum-varnames" and "x-enumNames" extensions of enum schemas are also supported,
+they contain the names of the enum values in the same order as the values.
+
 ## Path
 
 Extension for Open API 3 [paths](https://swagger.io/docs/specification/paths-and-operations/).
@@ -130,6 +179,18 @@ Extension for Open API 3 [paths](https://swagger.io/docs/specification/paths-and
 
 {{ .PathExtensionExample }}
 
+## Operation
+
+Extension for Open API 3 [operations](https://swagger.io/docs/specification/paths-and-operations/).
+
+### Fields
+
+{{ .OperationExtensionTable }}
+
+### Example
+
+{{ .OperationExtensionExample }}
+
 ## Response
 
 Extension for Open API 3 [responses](https://swagger.io/docs/specification/describing-responses/).
@@ -186,6 +247,17 @@ Extension for Open API 3 [schemas](https://swagger.io/docs/specification/data-mo
 						},
 					},
 				})) + "```\n",
+			"OperationExtensionTable": markdown.ExtensionsTable(OpenAPI3OperationExtension{}),
+			"OperationExtensionExample": "```yaml\n" + string(
+				util.MustMarshalYAML(map[string]interface{}{
+					"get": map[string]interface{}{
+						"x-repose": &OpenAPI3OperationExtension{
+							Paginate:    types.BoolPtr(true),
+							LimitParam:  types.StringPtr("pageSize"),
+							OffsetParam: types.StringPtr("start"),
+						},
+					},
+				})) + "```\n",
 			"ResponseExtensionTable": markdown.ExtensionsTable(OpenAPI3ResponseExtension{}),
 			"ResponseExtensionExample": "```yaml\n" + string(
 				util.MustMarshalYAML(map[string]interface{}{
@@ -235,8 +307,20 @@ func (o *OpenAPI3) DefaultOptions() interface{} {
 		ExtensionName:            "x-repose",
 		ResolveReferencesAt:      "",
 		ResolveReferencesIn:      "",
+		ResolveTimeout:           30,
+		ResolveMaxDepth:          32,
 		AdditionalPropertiesName: "AdditionalProperties",
-		StripExtension:           true,
+		StringFormatMap: map[string]string{
+			"email":    "string",
+			"hostname": "string",
+			"ipv4":     "string",
+			"ipv6":     "string",
+			"uri":      "string",
+			"uuid":     "string",
+		},
+		StripExtension:     true,
+		BodyNameFromSchema: false,
+		UseTitleAsName:     false,
 	}
 }
 
@@ -256,6 +340,9 @@ func (o *OpenAPI3) ExtensionExamples() map[string]interface{} {
 		"response": &OpenAPI3ResponseExtension{
 			Name: &[]string{"SomeResponse"}[0],
 		},
+		"operation": &OpenAPI3OperationExtension{
+			Paginate: &[]bool{true}[0],
+		},
 	}
 }
 
@@ -268,6 +355,11 @@ func (o *OpenAPI3) Parse(ctx context.Context, rawOpts interface{}, data []byte)
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	return o.parse(ctx, opts, data)
+}
+
+// parse parses the specification data with the decoded options.
+func (o *OpenAPI3) parse(ctx context.Context, opts *OpenAPI3Options, data []byte) (*spec.Spec, error) {
 	state, ok := ctx.Value("state").(*common.State)
 	if ok {
 		state.SetSpecData(data)
@@ -290,46 +382,19 @@ func (o *OpenAPI3) Parse(ctx context.Context, rawOpts interface{}, data []byte)
 			return nil, err
 		}
 
-		err = loader.ResolveRefsIn(swagger, refURL)
+		err = resolveReferences(loader, swagger, refURL, opts)
 		if err != nil {
-			// It's not a fatal error, we can continue
-			fmt.Printf("failed to resolve references at %v", opts.ResolveReferencesAt)
+			return nil, err
 		}
 	}
 
 	// The loader doesn't support resolving references locally
 	// so we create a http server and serve the folder.
 	if opts.ResolveReferencesIn != "" {
-		listener, err := net.Listen("tcp", ":0")
-		if err != nil {
-			panic(err)
-		}
-		defer listener.Close()
-
-		addr := listener.Addr().String()
-
-		srv := &http.Server{}
-
-		http.Handle("/", http.FileServer(http.Dir(opts.ResolveReferencesIn)))
-
-		go func() {
-			// returns ErrServerClosed on graceful close
-			if err := srv.Serve(listener); err != http.ErrServerClosed {
-				fmt.Println(err)
-			}
-		}()
-		defer srv.Close()
-
-		localURL, err := url.Parse("http://" + addr)
+		err = resolveLocalReferences(loader, swagger, opts)
 		if err != nil {
 			return nil, err
 		}
-
-		err = loader.ResolveRefsIn(swagger, localURL)
-		if err != nil {
-			// It's not a fatal error, we can continue
-			fmt.Printf("failed to resolve references in folder %v", opts.ResolveReferencesIn)
-		}
 	}
 
 	// Parse all the schemas
@@ -338,6 +403,8 @@ func (o *OpenAPI3) Parse(ctx context.Context, rawOpts interface{}, data []byte)
 		return nil, err
 	}
 
+	sp.BasePath = o.ParseBasePath(swagger)
+
 	// Then parse all thep aths
 	err = o.ParsePaths(ctx, sp, swagger, opts)
 	if err != nil {
@@ -360,13 +427,245 @@ func (o *OpenAPI3) ParseResources(ctx context.Context, options interface{}, path
 		return nil, fmt.Errorf("no paths supplied")
 	}
 
-	// TODO resolve refs in multiple files
-	b, err := ioutil.ReadFile(paths[0])
+	opts := o.DefaultOptions().(*OpenAPI3Options)
+
+	err := mapstructure.Decode(options, opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	root := paths[0]
+
+	// The rest of the files can only be referenced
+	// by the root document, so they are served from its folder.
+	if len(paths) > 1 {
+		root, err = rootResource(paths)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.ResolveReferencesIn == "" && opts.ResolveReferencesAt == "" {
+			opts.ResolveReferencesIn = filepath.Dir(root)
+		}
+	}
+
+	b, err := ioutil.ReadFile(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.parse(ctx, opts, b)
+}
+
+// rootDocumentNames are the usual names
+// of root documents of multi-file specifications.
+var rootDocumentNames = map[string]bool{
+	"openapi.yaml": true,
+	"openapi.yml":  true,
+	"openapi.json": true,
+	"swagger.yaml": true,
+	"swagger.yml":  true,
+	"swagger.json": true,
+}
+
+// rootResource selects the root document from the files of a specification.
+//
+// A file with a usual root document name is preferred (e.g. openapi.yaml),
+// the one closest to the top of the directory tree if there are several,
+// otherwise the only document with an openapi or swagger key is selected.
+func rootResource(paths []string) (string, error) {
+	var named []string
+	for _, p := range paths {
+		if rootDocumentNames[strings.ToLower(filepath.Base(p))] {
+			named = append(named, p)
+		}
+	}
+
+	if len(named) > 0 {
+		depth := func(p string) int {
+			return strings.Count(filepath.ToSlash(filepath.Clean(p)), "/")
+		}
+
+		sort.SliceStable(named, func(i, j int) bool {
+			return depth(named[i]) < depth(named[j])
+		})
+
+		if len(named) == 1 || depth(named[0]) < depth(named[1]) {
+			return named[0], nil
+		}
+
+		return "", fmt.Errorf("multiple root documents found: %v", strings.Join(named, ", "))
+	}
+
+	var roots []string
+	for _, p := range paths {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+
+		// JSON is also valid YAML, files that are
+		// neither are not part of the specification.
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(b, &doc); err != nil {
+			continue
+		}
+
+		if _, ok := doc["openapi"]; ok {
+			roots = append(roots, p)
+		} else if _, ok := doc["swagger"]; ok {
+			roots = append(roots, p)
+		}
+	}
+
+	switch len(roots) {
+	case 0:
+		return "", fmt.Errorf("no root document found, none of the files have an openapi or swagger key")
+	case 1:
+		return roots[0], nil
+	default:
+		return "", fmt.Errorf("multiple root documents found: %v", strings.Join(roots, ", "))
+	}
+}
+
+// resolveReferences resolves the references in the swagger
+// relative to the given URL within the limits in the options.
+//
+// Exceeding the limits is an error, other failures are not fatal,
+// and only reported.
+//
+// On timeout it returns without waiting for the loader, which might
+// still be modifying the swagger, so it must not be used afterwards.
+func resolveReferences(
+	loader *openapi3.SwaggerLoader,
+	swagger *openapi3.Swagger,
+	location *url.URL,
+	opts *OpenAPI3Options,
+) error {
+	client := &http.Client{
+		Timeout: time.Duration(opts.ResolveTimeout) * time.Second,
+	}
+
+	// Pending and further documents are not loaded after a timeout,
+	// the loader might still be busy with anything else.
+	resolveCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The documents are loaded recursively,
+	// so the depth is the number of documents being loaded.
+	depth := 0
+
+	// The loader might not keep the error as it is,
+	// so it is stored here as well.
+	var limitErr error
+
+	loader.LoadSwaggerFromURIFunc = func(l *openapi3.SwaggerLoader, docURL *url.URL) (*openapi3.Swagger, error) {
+		if err := resolveCtx.Err(); err != nil {
+			return nil, err
+		}
+
+		if opts.ResolveMaxDepth > 0 && depth >= opts.ResolveMaxDepth {
+			limitErr = fmt.Errorf("references are nested deeper than %v documents at %v", opts.ResolveMaxDepth, docURL)
+			return nil, limitErr
+		}
+
+		depth++
+		defer func() { depth-- }()
+
+		data, err := loadReferencedDocument(resolveCtx, client, docURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.LoadSwaggerFromDataWithPath(data, docURL)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- loader.ResolveRefsIn(swagger, location)
+	}()
+
+	var timeout <-chan time.Time
+	if opts.ResolveTimeout > 0 {
+		timer := time.NewTimer(time.Duration(opts.ResolveTimeout) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err := <-done:
+		if limitErr != nil {
+			return fmt.Errorf("failed to resolve references at %v: %w", location, limitErr)
+		}
+
+		if err != nil {
+			// It's not a fatal error, we can continue
+			fmt.Fprintf(os.Stderr, "failed to resolve references at %v: %v\n", location, err)
+		}
+		return nil
+	case <-timeout:
+		cancel()
+		return fmt.Errorf("failed to resolve references at %v: timed out after %v seconds", location, opts.ResolveTimeout)
+	}
+}
+
+// resolveLocalReferences serves the folder given in the options
+// over http for the duration of resolving the references.
+func resolveLocalReferences(loader *openapi3.SwaggerLoader, swagger *openapi3.Swagger, opts *OpenAPI3Options) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to serve folder %v: %w", opts.ResolveReferencesIn, err)
+	}
+	defer listener.Close()
+
+	// A dedicated mux is used, so that parsing
+	// can be done multiple times in the same process.
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(opts.ResolveReferencesIn)))
+
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		// returns ErrServerClosed on graceful close
+		if err := srv.Serve(listener); err != http.ErrServerClosed {
+			fmt.Println(err)
+		}
+	}()
+
+	defer srv.Close()
+
+	localURL, err := url.Parse("http://" + listener.Addr().String())
+	if err != nil {
+		return err
+	}
+
+	return resolveReferences(loader, swagger, localURL, opts)
+}
+
+// loadReferencedDocument loads the document at the given URL,
+// it is read from the file system if the URL has no host.
+func loadReferencedDocument(ctx context.Context, client *http.Client, docURL *url.URL) ([]byte, error) {
+	if docURL.Scheme == "" || docURL.Host == "" {
+		return ioutil.ReadFile(docURL.Path)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, docURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req.WithContext(ctx))
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to load %v: %v", docURL, res.Status)
+	}
 
-	return o.Parse(ctx, options, b)
+	return ioutil.ReadAll(res.Body)
 }
 
 // ParseSchemas parses the schema definitions
@@ -379,6 +678,14 @@ func (o *OpenAPI3) ParseSchemas(ctx context.Context, sp *spec.Spec, swagger *ope
 		return errs.ErrMissing("spec")
 	}
 
+	if opts.UseTitleAsName {
+		names, err := titleSchemaNames(swagger.Components.Schemas)
+		if err != nil {
+			return err
+		}
+		opts.schemaNames = names
+	}
+
 	// Go over all the schemas
 	for name, oapi3schema := range swagger.Components.Schemas {
 		if oapi3schema.Value == nil {
@@ -395,7 +702,12 @@ func (o *OpenAPI3) ParseSchemas(ctx context.Context, sp *spec.Spec, swagger *ope
 		// name is given. Except if it is explicitly
 		// told not to create them in the extension.
 		if schema.Name == "" {
-			schema.Name = name
+			schema.Name = opts.schemaName(name)
+
+			// The key is kept for e.g. type mappings.
+			if schema.Name != name {
+				schema.OriginalName = name
+			}
 
 			var ext OpenAPI3SchemaExtension
 			err := o.GetExtension(opts.ExtensionName, oapi3schema.Value.Extensions, &ext)
@@ -418,6 +730,49 @@ func (o *OpenAPI3) ParseSchemas(ctx context.Context, sp *spec.Spec, swagger *ope
 	return nil
 }
 
+// titleSchemaNames returns the titles of the component schemas
+// by their keys if the titles are valid Go identifiers.
+//
+// It is an error if a schema would have the same name as another one.
+func titleSchemaNames(schemas map[string]*openapi3.SchemaRef) (map[string]string, error) {
+	names := make(map[string]string)
+
+	for key, s := range schemas {
+		if s == nil || s.Value == nil {
+			continue
+		}
+
+		title := strings.TrimSpace(s.Value.Title)
+		if title == "" || title == key || !token.IsIdentifier(title) {
+			continue
+		}
+
+		names[key] = title
+	}
+
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]string, len(keys))
+
+	for _, key := range keys {
+		name := key
+		if title, ok := names[key]; ok {
+			name = title
+		}
+
+		if other, ok := seen[name]; ok {
+			return nil, fmt.Errorf("schemas %v and %v would both be named %v, change the title of one of them", other, key, name)
+		}
+		seen[name] = key
+	}
+
+	return names, nil
+}
+
 // ParseSchema parses the Open API 3 schema and returns a Schema
 func (o *OpenAPI3) ParseSchema(
 	ctx context.Context,
@@ -436,7 +791,7 @@ func (o *OpenAPI3) ParseSchema(
 	if oapi3Schema.Value == nil {
 		if oapi3Schema.Ref != "" {
 			rf := strings.Split(oapi3Schema.Ref, "/")
-			schema.Name = rf[len(rf)-1]
+			schema.Name = opts.schemaName(rf[len(rf)-1])
 			schema.OriginalName = rf[len(rf)-1]
 			return schema, nil
 		}
@@ -446,14 +801,21 @@ func (o *OpenAPI3) ParseSchema(
 	// If it had a ref, we already know its name
 	if oapi3Schema.Ref != "" {
 		rf := strings.Split(oapi3Schema.Ref, "/")
-		schema.Name = rf[len(rf)-1]
+		schema.Name = opts.schemaName(rf[len(rf)-1])
 		schema.OriginalName = rf[len(rf)-1]
 	}
 
 	schema.Description = oapi3Schema.Value.Description
+	schema.Format = oapi3Schema.Value.Format
+
+	schemaExtensions, err := o.GetExtensions(oapi3Schema.Value.Extensions, opts)
+	if err != nil {
+		return nil, err
+	}
+	schema.Extensions = schemaExtensions
 
 	var ext OpenAPI3SchemaExtension
-	err := o.GetExtension(opts.ExtensionName, oapi3Schema.Value.Extensions, &ext)
+	err = o.GetExtension(opts.ExtensionName, oapi3Schema.Value.Extensions, &ext)
 	if err != nil && err != ErrExtNotFound {
 		return nil, err
 	}
@@ -462,6 +824,11 @@ func (o *OpenAPI3) ParseSchema(
 		schema.Name = *ext.Type
 	}
 
+	schema.Sensitive = schema.Format == "password"
+	if ext.Sensitive != nil {
+		schema.Sensitive = *ext.Sensitive
+	}
+
 	if ext.Create != nil {
 
 		if *ext.Create {
@@ -507,6 +874,25 @@ func (o *OpenAPI3) ParseSchema(
 			}
 			children = append(children, s)
 		}
+
+		// The properties next to allOf are added
+		// as an inline struct member of the allOf.
+		if len(oapi3Schema.Value.Properties) > 0 {
+			own := &openapi3.Schema{
+				Type:                        "object",
+				Properties:                  oapi3Schema.Value.Properties,
+				Required:                    oapi3Schema.Value.Required,
+				AdditionalPropertiesAllowed: oapi3Schema.Value.AdditionalPropertiesAllowed,
+				AdditionalProperties:        oapi3Schema.Value.AdditionalProperties,
+			}
+
+			s, err := o.ParseSchema(ctx, &openapi3.SchemaRef{Value: own}, opts, append(visited, schema)...)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, s)
+		}
+
 		return schema.AllOf(children), nil
 	}
 
@@ -519,6 +905,7 @@ func (o *OpenAPI3) ParseSchema(
 			}
 			children = append(children, s)
 		}
+		schema.Discriminator = parseDiscriminator(oapi3Schema.Value.Discriminator, opts)
 		return schema.AnyOf(children), nil
 	}
 
@@ -531,13 +918,49 @@ func (o *OpenAPI3) ParseSchema(
 			}
 			children = append(children, s)
 		}
+		schema.Discriminator = parseDiscriminator(oapi3Schema.Value.Discriminator, opts)
 		return schema.OneOf(children), nil
 	}
 
 	if oapi3Schema.Value.Enum != nil {
 		schema.Enum = deepcopy.Copy(oapi3Schema.Value.Enum).([]interface{})
+
+		enumNames, err := parseEnumNames(oapi3Schema.Value)
+		if err != nil {
+			return nil, err
+		}
+		schema.EnumNames = enumNames
+	}
+
+	if oapi3Schema.Value.Default != nil {
+		schema.Default = deepcopy.Copy(oapi3Schema.Value.Default)
+	}
+
+	if oapi3Schema.Value.MultipleOf != nil && *oapi3Schema.Value.MultipleOf > 0 {
+		multipleOf := *oapi3Schema.Value.MultipleOf
+		schema.MultipleOf = &multipleOf
+	}
+
+	if oapi3Schema.Value.Min != nil {
+		minimum := *oapi3Schema.Value.Min
+		schema.Minimum = &minimum
+		schema.ExclusiveMinimum = oapi3Schema.Value.ExclusiveMin
+	}
+
+	if oapi3Schema.Value.Max != nil {
+		maximum := *oapi3Schema.Value.Max
+		schema.Maximum = &maximum
+		schema.ExclusiveMaximum = oapi3Schema.Value.ExclusiveMax
+	}
+
+	schema.MinLength = oapi3Schema.Value.MinLength
+	if oapi3Schema.Value.MaxLength != nil {
+		maxLength := *oapi3Schema.Value.MaxLength
+		schema.MaxLength = &maxLength
 	}
 
+	schema.Pattern = oapi3Schema.Value.Pattern
+
 	switch strings.TrimSpace(oapi3Schema.Value.Type) {
 	case "":
 		schema.Any()
@@ -568,12 +991,16 @@ func (o *OpenAPI3) ParseSchema(
 			// propname is the field's name in the Go type,
 			// but we also need to keep its original field name
 			s.FieldName = propname
-			propname = util.ToGoName(strcase.ToCamel(propname))
+			propname = common.Initialisms(ctx).ToGoName(strcase.ToCamel(propname))
 
 			props[propname] = s
 		}
 		schema.Struct(props)
 
+		if len(oapi3Schema.Value.Required) > 0 {
+			schema.RequiredProperties = append([]string(nil), oapi3Schema.Value.Required...)
+		}
+
 		// Check if it has additional props
 		if oapi3Schema.Value.AdditionalPropertiesAllowed != nil &&
 			*oapi3Schema.Value.AdditionalPropertiesAllowed {
@@ -594,6 +1021,18 @@ func (o *OpenAPI3) ParseSchema(
 			schema.AdditionalProps = additionalSchema
 		}
 
+		// Objects without properties, additionalProperties and constraints
+		// on the properties are free-form, an empty struct would drop
+		// all the data, so they are maps instead.
+		if len(oapi3Schema.Value.Properties) == 0 &&
+			oapi3Schema.Value.AdditionalPropertiesAllowed == nil &&
+			oapi3Schema.Value.AdditionalProperties == nil &&
+			len(oapi3Schema.Value.Required) == 0 &&
+			oapi3Schema.Value.MinProps == 0 &&
+			oapi3Schema.Value.MaxProps == nil {
+			schema.AdditionalProps = spec.NewSchema().SetVariant(spec.VariantAny)
+		}
+
 		// The name of the field can be set via an option
 		// to avoid conflicts
 		schema.AdditionalPropsName = opts.AdditionalPropertiesName
@@ -601,16 +1040,47 @@ func (o *OpenAPI3) ParseSchema(
 		// If we don't know any of the fields, we don't need a struct,
 		// rather just a map.
 		if schema.AdditionalProps != nil && len(schema.Children.Map) == 0 {
-			schema.Map(spec.NewSchema().Primitive("string"), schema.AdditionalProps)
+			key, err := o.ParsePropertyNames(ctx, oapi3Schema.Value, opts, append(visited, schema)...)
+			if err != nil {
+				return nil, err
+			}
+
+			if ext.KeyType != nil && *ext.KeyType != "" {
+				key = spec.NewSchema().Primitive(*ext.KeyType)
+			}
+
+			schema.Map(key, schema.AdditionalProps)
 		}
 
 	case "array":
-		item, err := o.ParseSchema(ctx, oapi3Schema.Value.Items, opts, append(visited, schema)...)
+		tuple, err := o.ParsePrefixItems(ctx, oapi3Schema.Value, opts, append(visited, schema)...)
 		if err != nil {
 			return nil, err
 		}
-		schema.Array(item)
+		schema.Tuple = tuple
+
+		// Tuples might not have a schema for the rest of the items.
+		if len(tuple) > 0 && oapi3Schema.Value.Items == nil {
+			schema.Array(spec.NewSchema().Any())
+		} else {
+			item, err := o.ParseSchema(ctx, oapi3Schema.Value.Items, opts, append(visited, schema)...)
+			if err != nil {
+				return nil, err
+			}
+			schema.Array(item)
+		}
+
+		schema.MinItems = oapi3Schema.Value.MinItems
+		if oapi3Schema.Value.MaxItems != nil {
+			maxItems := *oapi3Schema.Value.MaxItems
+			schema.MaxItems = &maxItems
+		}
 	case "string":
+		if goType := opts.StringFormatMap[oapi3Schema.Value.Format]; goType != "" {
+			schema.Primitive(goType)
+			break
+		}
+
 		switch oapi3Schema.Value.Format {
 		case "date", "date-time":
 			schema.Primitive("time.Time")
@@ -646,56 +1116,338 @@ func (o *OpenAPI3) ParseSchema(
 	return schema, nil
 }
 
-// ParsePaths parses the paths of the specification
-func (o *OpenAPI3) ParsePaths(ctx context.Context, sp *spec.Spec, swagger *openapi3.Swagger, opts *OpenAPI3Options) error {
-	if sp == nil {
-		return fmt.Errorf("spec cannot be nil")
+// ParsePrefixItems parses the prefixItems of an OpenAPI 3.1 tuple array.
+//
+// The loader does not know about prefixItems, so it is read from
+// the unknown properties of the schema, and references in it are not resolved.
+func (o *OpenAPI3) ParsePrefixItems(
+	ctx context.Context,
+	oapi3Schema *openapi3.Schema,
+	opts *OpenAPI3Options,
+	visited ...*spec.Schema,
+) ([]*spec.Schema, error) {
+	raw, ok := oapi3Schema.Extensions["prefixItems"].(jsonstd.RawMessage)
+	if !ok {
+		return nil, nil
 	}
 
-	// Go over all the paths
-	for url, swaggerPath := range swagger.Paths {
-		path, err := o.ParsePath(ctx, swaggerPath, opts)
-		if err != nil {
-			return err
-		}
+	var items []*openapi3.SchemaRef
 
-		path.PathString = url
+	err := json.Unmarshal(raw, &items)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefixItems: %w", err)
+	}
 
-		sp.Paths = append(sp.Paths, path)
+	tuple := make([]*spec.Schema, 0, len(items))
+
+	for _, item := range items {
+		s, err := o.ParseSchema(ctx, item, opts, visited...)
+		if err != nil {
+			return nil, err
+		}
+		tuple = append(tuple, s)
 	}
 
-	return nil
+	return tuple, nil
 }
 
-// ParsePath parses a single path item.
-func (o *OpenAPI3) ParsePath(ctx context.Context, swPath *openapi3.PathItem, opts *OpenAPI3Options) (*spec.Path, error) {
+// ParsePropertyNames returns the key schema of a map
+// based on the propertyNames of the schema.
+//
+// Only references (e.g. to string enums) are used as key types,
+// as other constraints such as patterns can only be expressed with strings.
+func (o *OpenAPI3) ParsePropertyNames(
+	ctx context.Context,
+	oapi3Schema *openapi3.Schema,
+	opts *OpenAPI3Options,
+	visited ...*spec.Schema,
+) (*spec.Schema, error) {
+	key := spec.NewSchema().Primitive("string")
 
-	path := &spec.Path{
-		Description: swPath.Description,
+	// The loader does not know about propertyNames either.
+	raw, ok := oapi3Schema.Extensions["propertyNames"].(jsonstd.RawMessage)
+	if !ok {
+		return key, nil
 	}
 
-	var ext OpenAPI3PathExtension
-	err := o.GetExtension(opts.ExtensionName, swPath.Extensions, &ext)
-	if err != nil && err != ErrExtNotFound {
-		return nil, err
+	var propertyNames openapi3.SchemaRef
+
+	err := json.Unmarshal(raw, &propertyNames)
+	if err != nil {
+		return nil, fmt.Errorf("invalid propertyNames: %w", err)
 	}
 
-	if ext.Name != nil && *ext.Name != "" {
-		path.Name = *ext.Name
+	if propertyNames.Ref == "" {
+		return key, nil
 	}
 
-	// Parse each operation individually
-	for method, op := range swPath.Operations() {
+	return o.ParseSchema(ctx, &propertyNames, opts, visited...)
+}
+
+// enumNameExtensions are the vendor extensions
+// that are used for naming enum values.
+var enumNameExtensions = []string{"x-enum-varnames", "x-enumNames"}
+
+// parseEnumNames returns the names of the enum values
+// of the schema from the vendor extensions, if any.
+func parseEnumNames(oapi3Schema *openapi3.Schema) ([]string, error) {
+	for _, extName := range enumNameExtensions {
+		raw, ok := oapi3Schema.Extensions[extName].(jsonstd.RawMessage)
+		if !ok {
+			continue
+		}
+
+		var names []string
+
+		err := json.Unmarshal(raw, &names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v: %w", extName, err)
+		}
+
+		if len(names) != len(oapi3Schema.Enum) {
+			return nil, fmt.Errorf(
+				"%v has %v names for %v enum values",
+				extName, len(names), len(oapi3Schema.Enum),
+			)
+		}
+
+		return names, nil
+	}
+
+	return nil, nil
+}
+
+// parseDiscriminator converts the discriminator of a schema,
+// the mapped references are replaced by the names of the schemas.
+func parseDiscriminator(discriminator *openapi3.Discriminator, opts *OpenAPI3Options) *spec.Discriminator {
+	if discriminator == nil || discriminator.PropertyName == "" {
+		return nil
+	}
+
+	d := &spec.Discriminator{
+		PropertyName: discriminator.PropertyName,
+	}
+
+	if len(discriminator.Mapping) > 0 {
+		d.Mapping = make(map[string]string, len(discriminator.Mapping))
+
+		for value, ref := range discriminator.Mapping {
+			rf := strings.Split(ref, "/")
+			d.Mapping[value] = opts.schemaName(rf[len(rf)-1])
+		}
+	}
+
+	return d
+}
+
+// ParseBasePath returns the path of the first server
+// if its URL is relative, otherwise an empty string.
+func (o *OpenAPI3) ParseBasePath(swagger *openapi3.Swagger) string {
+	if len(swagger.Servers) == 0 || swagger.Servers[0] == nil {
+		return ""
+	}
+
+	serverURL := strings.TrimSpace(swagger.Servers[0].URL)
+	if !strings.HasPrefix(serverURL, "/") {
+		return ""
+	}
+
+	return strings.TrimRight(serverURL, "/")
+}
+
+// ParsePaths parses the paths of the specification
+func (o *OpenAPI3) ParsePaths(ctx context.Context, sp *spec.Spec, swagger *openapi3.Swagger, opts *OpenAPI3Options) error {
+	if sp == nil {
+		return fmt.Errorf("spec cannot be nil")
+	}
+
+	// Go over all the paths
+	for url, swaggerPath := range swagger.Paths {
+		resolved, err := resolvePathItem(swagger, swaggerPath)
+		if err != nil {
+			return fmt.Errorf("path %v: %w", url, err)
+		}
+
+		path, err := o.ParsePath(ctx, resolved, opts)
+		if err != nil {
+			return err
+		}
+
+		path.PathString = url
+
+		// The operations of referenced path items have the same IDs
+		// as the original ones, so they are named after the path as well
+		// (e.g. GetPet for /pets/{id} and GetPetAnimalsID for /animals/{id}).
+		if resolved != swaggerPath {
+			for _, op := range path.Operations {
+				op.Name += operationName(ctx, url)
+			}
+		}
+
+		sp.Paths = append(sp.Paths, path)
+	}
+
+	dedupeOperationNames(sp)
+
+	return nil
+}
+
+// operationNameSeparatorRe matches the characters of
+// operation IDs that cannot be part of Go identifiers.
+var operationNameSeparatorRe = regexp.MustCompile(`[^\p{L}\p{N}_]+`)
+
+// operationName returns the Go name of an operation from its ID,
+// separators such as dots and slashes start new words
+// (e.g. pets.list and pets/list are both PetsList),
+// and the initialisms are upper-cased (e.g. getPetUrl is GetPetURL).
+func operationName(ctx context.Context, operationID string) string {
+	return common.Initialisms(ctx).ToGoName(strcase.ToCamel(operationNameSeparatorRe.ReplaceAllString(operationID, "_")))
+}
+
+// dedupeOperationNames makes the names of the operations unique
+// by numbering the operations with the same name in the order of their IDs,
+// methods and paths (e.g. PetsList and PetsList2),
+// which is possible after separators are removed.
+//
+// Operations without IDs are left unnamed for the transformer to name them.
+func dedupeOperationNames(sp *spec.Spec) {
+	byName := make(map[string][]*spec.Operation)
+	paths := make(map[*spec.Operation]string)
+	for _, p := range sp.Paths {
+		for _, op := range p.Operations {
+			if op.Name == "" {
+				continue
+			}
+			byName[op.Name] = append(byName[op.Name], op)
+			paths[op] = p.PathString
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name, ops := range byName {
+		if len(ops) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ops := byName[name]
+
+		sort.SliceStable(ops, func(i, j int) bool {
+			if ops[i].ID != ops[j].ID {
+				return ops[i].ID < ops[j].ID
+			}
+			if ops[i].Method != ops[j].Method {
+				return ops[i].Method < ops[j].Method
+			}
+			return paths[ops[i]] < paths[ops[j]]
+		})
+
+		n := 2
+		for _, op := range ops[1:] {
+			for len(byName[fmt.Sprintf("%v%v", name, n)]) > 0 {
+				n++
+			}
+
+			op.Name = fmt.Sprintf("%v%v", name, n)
+			byName[op.Name] = []*spec.Operation{op}
+		}
+	}
+}
+
+// resolvePathItem returns the path item a $ref path item refers to.
+//
+// The loader only resolves external path item references
+// if it is configured to do so, and it does not resolve local ones,
+// so we look up references to other paths in the document here.
+// Inline summaries and descriptions take precedence over the referenced ones.
+func resolvePathItem(swagger *openapi3.Swagger, swPath *openapi3.PathItem) (*openapi3.PathItem, error) {
+	visited := make(map[string]bool)
+
+	resolved := swPath
+
+	for resolved.Ref != "" && len(resolved.Operations()) == 0 {
+		ref := resolved.Ref
+
+		if visited[ref] {
+			return nil, fmt.Errorf("circular path item reference %v", ref)
+		}
+		visited[ref] = true
+
+		if !strings.HasPrefix(ref, "#/paths/") {
+			return nil, fmt.Errorf(
+				"failed to resolve path item reference %v, set resolveReferencesAt or resolveReferencesIn for external references",
+				ref,
+			)
+		}
+
+		pathURL := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, "#/paths/"))
+
+		target, ok := swagger.Paths[pathURL]
+		if !ok || target == nil {
+			return nil, fmt.Errorf("path item reference %v not found", ref)
+		}
+
+		resolved = target
+	}
+
+	if resolved == swPath {
+		return swPath, nil
+	}
+
+	item := *resolved
+
+	if swPath.Summary != "" {
+		item.Summary = swPath.Summary
+	}
+
+	if swPath.Description != "" {
+		item.Description = swPath.Description
+	}
+
+	return &item, nil
+}
+
+// ParsePath parses a single path item.
+func (o *OpenAPI3) ParsePath(ctx context.Context, swPath *openapi3.PathItem, opts *OpenAPI3Options) (*spec.Path, error) {
+
+	pathExtensions, err := o.GetExtensions(swPath.Extensions, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	path := &spec.Path{
+		Description: swPath.Description,
+		Extensions:  pathExtensions,
+	}
+
+	var ext OpenAPI3PathExtension
+	err = o.GetExtension(opts.ExtensionName, swPath.Extensions, &ext)
+	if err != nil && err != ErrExtNotFound {
+		return nil, err
+	}
+
+	if ext.Name != nil && *ext.Name != "" {
+		path.Name = *ext.Name
+	}
+
+	swOps := make(map[*spec.Operation]*openapi3.Operation)
+
+	// Parse each operation individually
+	for method, op := range swPath.Operations() {
 		specOp, err := o.ParseOperation(ctx, op, opts)
 		if err != nil {
 			return nil, err
 		}
 		specOp.Method = method
 		path.Operations = append(path.Operations, specOp)
+		swOps[specOp] = op
 	}
 
 	// We also need to add the parameters defined
-	// on the path to all the operations
+	// on the path to all the operations,
+	// unless the operation overrides them.
 	for _, p := range swPath.Parameters {
 		if p.Value == nil {
 			continue
@@ -706,20 +1458,65 @@ func (o *OpenAPI3) ParsePath(ctx context.Context, swPath *openapi3.PathItem, opt
 		}
 
 		for _, op := range path.Operations {
-			op.Parameters = append(op.Parameters, params...)
+			for _, param := range params {
+				if hasParameter(op, param.Name, param.Type) {
+					continue
+				}
+				op.Parameters = append(op.Parameters, param)
+			}
+		}
+	}
+
+	for _, op := range path.Operations {
+		renameBodyParameters(op)
+
+		// Pagination parameters can be defined on the path as well.
+		var opExt OpenAPI3OperationExtension
+		err = o.GetExtension(opts.ExtensionName, swOps[op].Extensions, &opExt)
+		if err != nil && err != ErrExtNotFound {
+			return nil, err
+		}
+
+		op.Pagination, err = o.ParsePagination(op, &opExt)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	return path, nil
 }
 
+// hasParameter checks whether the operation
+// already has a parameter with the given name and location.
+func hasParameter(op *spec.Operation, name string, paramType spec.ParameterType) bool {
+	for _, param := range op.Parameters {
+		if param.Name == name && param.Type == paramType {
+			return true
+		}
+	}
+	return false
+}
+
 // ParseOperation parses an Open API 3 operation
 func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, opts *OpenAPI3Options) (*spec.Operation, error) {
 
+	opExtensions, err := o.GetExtensions(op.Extensions, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	specOp := &spec.Operation{
-		Name:        strcase.ToCamel(op.OperationID),
+		Name:        operationName(ctx, op.OperationID),
 		ID:          op.OperationID,
 		Description: op.Description,
+		Extensions:  opExtensions,
+	}
+
+	if op.ExternalDocs != nil {
+		specOp.ExternalDocs = &spec.ExternalDocs{
+			URL:         op.ExternalDocs.URL,
+			Description: op.ExternalDocs.Description,
+		}
 	}
 
 	for _, p := range op.Parameters {
@@ -735,6 +1532,12 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 		specOp.Parameters = append(specOp.Parameters, params...)
 	}
 
+	var ext OpenAPI3OperationExtension
+	err = o.GetExtension(opts.ExtensionName, op.Extensions, &ext)
+	if err != nil && err != ErrExtNotFound {
+		return nil, err
+	}
+
 	// Request body is also a parameter, but we need to
 	// parse it differently.
 	if op.RequestBody != nil && op.RequestBody.Value != nil {
@@ -742,7 +1545,6 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 
 		for contentType, content := range reqBody.Content {
 			param := &spec.Parameter{
-				Name:        "body",
 				Description: reqBody.Description,
 				Required:    reqBody.Required,
 				Type:        spec.ParameterTypeBody,
@@ -757,6 +1559,18 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 				param.Schema = s
 			}
 
+			param.Encoding, err = o.ParseEncoding(ctx, content.Encoding, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			param.Examples = parseExamples(content)
+
+			param.Name, err = o.bodyParameterName(param, &ext, opts)
+			if err != nil {
+				return nil, fmt.Errorf("operation %v: %w", op.OperationID, err)
+			}
+
 			specOp.Parameters = append(specOp.Parameters, param)
 		}
 	}
@@ -778,11 +1592,23 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 			responseName = strings.TrimSpace(*ext.Name)
 		}
 
+		links, err := o.ParseLinks(ctx, res.Value.Links, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		headers, err := o.ParseResponseHeaders(ctx, res.Value.Headers, opts)
+		if err != nil {
+			return nil, err
+		}
+
 		if len(res.Value.Content) == 0 {
 			specOp.Responses = append(specOp.Responses, &spec.Response{
 				Name:        responseName,
 				Description: res.Value.Description,
 				Code:        code,
+				Links:       links,
+				Headers:     headers,
 			})
 
 			continue
@@ -794,6 +1620,9 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 				Description: res.Value.Description,
 				ContentType: contentType,
 				Code:        code,
+				Links:       links,
+				Headers:     headers,
+				Examples:    parseExamples(content),
 			}
 
 			if content.Schema != nil {
@@ -802,6 +1631,10 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 					return nil, err
 				}
 				specRes.Schema = s
+			} else if util.IsJSONMediaType(contentType) {
+				// Documented JSON content without a schema can still
+				// have a body, so it must not become an empty response.
+				specRes.Schema = spec.NewSchema().Any()
 			}
 
 			specOp.Responses = append(specOp.Responses, specRes)
@@ -814,9 +1647,337 @@ func (o *OpenAPI3) ParseOperation(ctx context.Context, op *openapi3.Operation, o
 	}
 	specOp.Callbacks = cbs
 
+	if ext.MaxBodyBytes != nil {
+		if *ext.MaxBodyBytes <= 0 {
+			return nil, fmt.Errorf("operation %v: maxBodyBytes must be positive", op.OperationID)
+		}
+		specOp.MaxBodyBytes = *ext.MaxBodyBytes
+	}
+
 	return specOp, nil
 }
 
+// bodyParameterName returns the name of a request body parameter.
+//
+// The name is either set in the extension, derived
+// from the schema name, or body by default.
+func (o *OpenAPI3) bodyParameterName(
+	param *spec.Parameter,
+	ext *OpenAPI3OperationExtension,
+	opts *OpenAPI3Options,
+) (string, error) {
+	switch {
+	case ext.BodyName != nil && strings.TrimSpace(*ext.BodyName) != "":
+		name := strings.TrimSpace(*ext.BodyName)
+		if !token.IsIdentifier(name) {
+			return "", fmt.Errorf("body name %v is not a valid Go identifier", name)
+		}
+		return name, nil
+	case opts.BodyNameFromSchema && param.Schema != nil && param.Schema.Name != "":
+		return strcase.ToLowerCamel(param.Schema.Name), nil
+	default:
+		return "body", nil
+	}
+}
+
+// parseExamples returns the examples of the content ordered by their names,
+// the single example of the content is named "default".
+func parseExamples(content *openapi3.MediaType) []*spec.Example {
+	if content == nil {
+		return nil
+	}
+
+	return parseExampleValues(content.Example, content.Examples)
+}
+
+// parseExampleValues is the same as parseExamples, but for the example
+// fields of anything that can have examples, such as parameters.
+func parseExampleValues(example interface{}, exampleRefs map[string]*openapi3.ExampleRef) []*spec.Example {
+	var examples []*spec.Example
+
+	if example != nil {
+		examples = append(examples, &spec.Example{
+			Name:  "default",
+			Value: example,
+		})
+	}
+
+	names := make([]string, 0, len(exampleRefs))
+	for name, ex := range exampleRefs {
+		if ex != nil && ex.Value != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ex := exampleRefs[name].Value
+
+		examples = append(examples, &spec.Example{
+			Name:          name,
+			Summary:       ex.Summary,
+			Description:   ex.Description,
+			Value:         ex.Value,
+			ExternalValue: ex.ExternalValue,
+		})
+	}
+
+	return examples
+}
+
+// reservedParameterNames are the names of the parameters
+// of generated methods that are not parameters of the operation.
+var reservedParameterNames = map[string]bool{
+	"c":        true,
+	"ctx":      true,
+	"callOpts": true,
+}
+
+// renameBodyParameters prefixes the name of the request body
+// parameters with request if another parameter has the same name,
+// the name is reserved by the generated methods, or it is a Go keyword
+// or predeclared identifier (e.g. type or error for schemas named after them).
+func renameBodyParameters(op *spec.Operation) {
+	taken := func(name string) bool {
+		if reservedParameterNames[name] || token.Lookup(name).IsKeyword() || gotypes.Universe.Lookup(name) != nil {
+			return true
+		}
+		for _, p := range op.Parameters {
+			if p.Type != spec.ParameterTypeBody && p.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, p := range op.Parameters {
+		if p.Type != spec.ParameterTypeBody || !taken(p.Name) {
+			continue
+		}
+
+		name := "request" + strcase.ToCamel(p.Name)
+		unique := name
+
+		for i := 2; taken(unique); i++ {
+			unique = name + strconv.Itoa(i)
+		}
+
+		p.Name = unique
+	}
+}
+
+// ParsePagination detects limit and offset or cursor-based pagination of an operation.
+//
+// Unless the extension says otherwise, operations with both parameters
+// and an array response are paginated, and so are operations with
+// the cursor parameter and a response with the cursor of the next page.
+func (o *OpenAPI3) ParsePagination(op *spec.Operation, ext *OpenAPI3OperationExtension) (*spec.Pagination, error) {
+	if ext.Paginate != nil && !*ext.Paginate {
+		return nil, nil
+	}
+
+	pagination := &spec.Pagination{
+		LimitParam:  "limit",
+		OffsetParam: "offset",
+	}
+
+	if ext.LimitParam != nil && *ext.LimitParam != "" {
+		pagination.LimitParam = *ext.LimitParam
+	}
+
+	if ext.OffsetParam != nil && *ext.OffsetParam != "" {
+		pagination.OffsetParam = *ext.OffsetParam
+	}
+
+	var hasLimit, hasOffset, hasArray bool
+
+	for _, p := range op.Parameters {
+		if p.Type != spec.ParameterTypeQuery {
+			continue
+		}
+
+		switch p.Name {
+		case pagination.LimitParam:
+			hasLimit = true
+		case pagination.OffsetParam:
+			hasOffset = true
+		}
+	}
+
+	for _, res := range op.Responses {
+		if strings.HasPrefix(res.Code, "2") && res.Schema != nil && res.Schema.Variant == spec.VariantArray {
+			hasArray = true
+		}
+	}
+
+	if hasLimit && hasOffset && hasArray {
+		return pagination, nil
+	}
+
+	cursor := parseCursorPagination(op, ext)
+	if cursor != nil {
+		return cursor, nil
+	}
+
+	if ext.Paginate != nil {
+		return nil, fmt.Errorf(
+			"operation %v cannot be paginated, it needs the %v and %v query parameters, and an array response, "+
+				"or a cursor query parameter and a response with the next cursor",
+			op.ID, pagination.LimitParam, pagination.OffsetParam,
+		)
+	}
+
+	return nil, nil
+}
+
+// parseCursorPagination detects cursor-based pagination of an operation,
+// the cursor of the next page is looked for in the properties of the
+// successful responses.
+//
+// The parameters of the path must already be added to the operation,
+// as the cursor parameter can be defined for the whole path.
+func parseCursorPagination(op *spec.Operation, ext *OpenAPI3OperationExtension) *spec.Pagination {
+	cursorParam := "cursor"
+	if ext.CursorParam != nil && *ext.CursorParam != "" {
+		cursorParam = *ext.CursorParam
+	}
+
+	nextCursorFields := []string{"next_cursor", "nextCursor"}
+	if ext.NextCursorField != nil && *ext.NextCursorField != "" {
+		nextCursorFields = []string{*ext.NextCursorField}
+	}
+
+	hasCursor := false
+	for _, p := range op.Parameters {
+		if p.Type == spec.ParameterTypeQuery && p.Name == cursorParam {
+			hasCursor = true
+			break
+		}
+	}
+
+	if !hasCursor {
+		return nil
+	}
+
+	for _, res := range op.Responses {
+		if !strings.HasPrefix(res.Code, "2") || res.Schema == nil || res.Schema.Variant != spec.VariantStruct {
+			continue
+		}
+
+		for _, field := range nextCursorFields {
+			for _, child := range res.Schema.Children.Map {
+				if child.FieldName == field {
+					return &spec.Pagination{
+						CursorParam:     cursorParam,
+						NextCursorField: field,
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseEncoding parses the encoding of the properties of a request body.
+func (o *OpenAPI3) ParseEncoding(ctx context.Context, encoding map[string]*openapi3.Encoding, opts *OpenAPI3Options) (map[string]*spec.Encoding, error) {
+	if len(encoding) == 0 {
+		return nil, nil
+	}
+
+	specEncoding := make(map[string]*spec.Encoding, len(encoding))
+
+	for name, enc := range encoding {
+		if enc == nil {
+			continue
+		}
+
+		// The headers and the serialization of the properties
+		// are not used by any of the generators, so they are not parsed.
+		specEncoding[name] = &spec.Encoding{
+			ContentType: enc.ContentType,
+		}
+	}
+
+	return specEncoding, nil
+}
+
+// ParseResponseHeaders parses the headers of a response in alphabetical order.
+// Headers without a schema are strings.
+func (o *OpenAPI3) ParseResponseHeaders(ctx context.Context, headers map[string]*openapi3.HeaderRef, opts *OpenAPI3Options) ([]*spec.Header, error) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	specHeaders := make([]*spec.Header, 0, len(headers))
+
+	for _, name := range names {
+		header := headers[name]
+		if header == nil || header.Value == nil {
+			continue
+		}
+
+		specHeader := &spec.Header{
+			Name:        name,
+			Description: header.Value.Description,
+			Required:    header.Value.Required,
+		}
+
+		if header.Value.Schema != nil {
+			s, err := o.ParseSchema(ctx, header.Value.Schema, opts)
+			if err != nil {
+				return nil, err
+			}
+			specHeader.Schema = s
+		} else {
+			specHeader.Schema = spec.NewSchema().Primitive("string")
+		}
+
+		specHeaders = append(specHeaders, specHeader)
+	}
+
+	return specHeaders, nil
+}
+
+// ParseLinks parses the links of a response.
+//
+// Only links that refer to the operation with operationId are supported,
+// links with operationRef result in an error.
+func (o *OpenAPI3) ParseLinks(ctx context.Context, links map[string]*openapi3.LinkRef, opts *OpenAPI3Options) ([]*spec.Link, error) {
+	specLinks := make([]*spec.Link, 0, len(links))
+
+	for name, linkRef := range links {
+		if linkRef == nil || linkRef.Value == nil {
+			continue
+		}
+
+		if linkRef.Value.OperationID == "" {
+			return nil, fmt.Errorf("link %v: operationId is required, operationRef is not supported", name)
+		}
+
+		link := &spec.Link{
+			Name:        name,
+			OperationID: linkRef.Value.OperationID,
+			Description: linkRef.Value.Description,
+			Parameters:  make(map[string]string, len(linkRef.Value.Parameters)),
+		}
+
+		for paramName, paramVal := range linkRef.Value.Parameters {
+			link.Parameters[paramName] = fmt.Sprint(paramVal)
+		}
+
+		specLinks = append(specLinks, link)
+	}
+
+	sort.Slice(specLinks, func(i, j int) bool {
+		return specLinks[i].Name < specLinks[j].Name
+	})
+
+	return specLinks, nil
+}
+
 // ParseCallbacks parses the callbacks of an operation.
 func (o *OpenAPI3) ParseCallbacks(ctx context.Context, cbs map[string]*openapi3.CallbackRef, opts *OpenAPI3Options) (map[string][]*spec.Path, error) {
 	specCbs := make(map[string][]*spec.Path)
@@ -849,7 +2010,7 @@ func (o *OpenAPI3) ParseCallbacks(ctx context.Context, cbs map[string]*openapi3.
 			cbPaths = append(cbPaths, specCb)
 		}
 
-		specCbs[util.ToGoName(strings.Title(strcase.ToCamel(cbEvent)))] = cbPaths
+		specCbs[common.Initialisms(ctx).ToGoName(strings.Title(strcase.ToCamel(cbEvent)))] = cbPaths
 	}
 
 	return specCbs, nil
@@ -900,6 +2061,7 @@ func (o *OpenAPI3) ParseParameter(ctx context.Context, p *openapi3.ParameterRef,
 			return nil, err
 		}
 		simpleParam.Schema = s
+		simpleParam.Examples = parseExampleValues(p.Value.Example, p.Value.Examples)
 		params = append(params, simpleParam)
 	}
 
@@ -924,6 +2086,13 @@ func (o *OpenAPI3) ParseParameter(ctx context.Context, p *openapi3.ParameterRef,
 				return nil, err
 			}
 			param.Schema = s
+
+			// The examples of the content take precedence.
+			param.Examples = parseExamples(content)
+			if len(param.Examples) == 0 {
+				param.Examples = parseExampleValues(p.Value.Example, p.Value.Examples)
+			}
+
 			params = append(params, param)
 		}
 	}
@@ -954,6 +2123,42 @@ func (o *OpenAPI3) GetExtension(name string, extensions map[string]interface{},
 	return ErrExtNotFound
 }
 
+// GetExtensions collects all the vendor extensions
+// except for the Repose extension.
+func (o *OpenAPI3) GetExtensions(extensions map[string]interface{}, opts *OpenAPI3Options) (map[string]interface{}, error) {
+	if len(extensions) == 0 {
+		return nil, nil
+	}
+
+	exts := make(map[string]interface{}, len(extensions))
+
+	for name, ext := range extensions {
+		if name == opts.ExtensionName || !strings.HasPrefix(name, "x-") {
+			continue
+		}
+
+		raw, isRawMessage := ext.(jsonstd.RawMessage)
+		if !isRawMessage {
+			exts[name] = ext
+			continue
+		}
+
+		var val interface{}
+		err := json.Unmarshal(raw, &val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extension %v: %v", name, err)
+		}
+
+		exts[name] = val
+	}
+
+	if len(exts) == 0 {
+		return nil, nil
+	}
+
+	return exts, nil
+}
+
 // StripExtension strips the extension from the swagger specification
 // and serialize it to the options.
 func (o *OpenAPI3) StripExtension(ctx context.Context, swagger *openapi3.Swagger, opts *OpenAPI3Options) error {
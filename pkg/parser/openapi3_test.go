@@ -0,0 +1,1112 @@
+package parser
+
+import (
+	"context"
+	stdjson "encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamasfe/repose/pkg/common"
+	"github.com/tamasfe/repose/pkg/spec"
+	"github.com/tamasfe/repose/pkg/transformer"
+	"github.com/tamasfe/repose/pkg/util"
+	"gopkg.in/go-playground/assert.v1"
+)
+
+func parseTestSpec(t *testing.T, data string) *spec.Spec {
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	sp, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sp
+}
+
+func findOperation(sp *spec.Spec, id string) *spec.Operation {
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+	return nil
+}
+
+func TestPathParameterOverride(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "parameters": [
+        {"name": "limit", "in": "query", "description": "path", "schema": {"type": "integer"}},
+        {"name": "offset", "in": "query", "schema": {"type": "integer"}}
+      ],
+      "get": {
+        "operationId": "listItems",
+        "parameters": [
+          {"name": "limit", "in": "query", "description": "operation", "schema": {"type": "string"}},
+          {"name": "offset", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "delete": {
+        "operationId": "deleteItems",
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`)
+
+	listItems := findOperation(sp, "listItems")
+	assert.NotEqual(t, listItems, nil)
+	assert.Equal(t, len(listItems.Parameters), 3)
+
+	limitCount := 0
+	for _, p := range listItems.Parameters {
+		if p.Name == "limit" {
+			limitCount++
+			assert.Equal(t, p.Description, "operation")
+			assert.Equal(t, p.Schema.PrimitiveType, "string")
+		}
+	}
+	assert.Equal(t, limitCount, 1)
+
+	deleteItems := findOperation(sp, "deleteItems")
+	assert.NotEqual(t, deleteItems, nil)
+	assert.Equal(t, len(deleteItems.Parameters), 2)
+
+	for _, p := range deleteItems.Parameters {
+		if p.Name == "limit" {
+			assert.Equal(t, p.Description, "path")
+		}
+	}
+}
+
+func TestPrefixItems(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.1.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Point": {
+        "type": "array",
+        "prefixItems": [{"type": "string"}, {"type": "integer"}]
+      },
+      "Names": {
+        "type": "array",
+        "items": {"type": "string"}
+      }
+    }
+  }
+}`)
+
+	schemas := make(map[string]*spec.Schema)
+	for _, s := range sp.Schemas {
+		schemas[s.Name] = s
+	}
+
+	point := schemas["Point"]
+	assert.Equal(t, len(point.Tuple), 2)
+	assert.Equal(t, point.Tuple[0].PrimitiveType, "string")
+	assert.Equal(t, point.Tuple[1].PrimitiveType, "int")
+	assert.Equal(t, point.Children.Schema.Variant, spec.VariantAny)
+
+	assert.Equal(t, len(schemas["Names"].Tuple), 0)
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}},
+            "application/xml": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}
+          }
+        },
+        "responses": {"204": {"description": "Created"}}
+      },
+      "put": {
+        "operationId": "updatePet",
+        "requestBody": {
+          "content": {
+            "application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}
+          }
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  }
+}`)
+
+	createPet := findOperation(sp, "createPet")
+	assert.Equal(t, len(createPet.Parameters), 2)
+
+	for _, p := range createPet.Parameters {
+		assert.Equal(t, p.Type, spec.ParameterTypeBody)
+		assert.Equal(t, p.Required, true)
+		assert.Equal(t, p.IsPtr(), false)
+	}
+
+	updatePet := findOperation(sp, "updatePet")
+	assert.Equal(t, len(updatePet.Parameters), 1)
+	assert.Equal(t, updatePet.Parameters[0].Required, false)
+	assert.Equal(t, updatePet.Parameters[0].IsPtr(), true)
+}
+
+func TestYAMLAnchors(t *testing.T) {
+	data := `
+openapi: 3.0.0
+info:
+  title: Test
+  version: 1.0.0
+x-definitions:
+  timestamps: &timestamps
+    created:
+      type: string
+      format: date-time
+    updated:
+      type: string
+      format: date-time
+  error: &error
+    description: Error
+    content:
+      application/json:
+        schema:
+          $ref: '#/components/schemas/Error'
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      x-repose:
+        paginate: false
+      responses:
+        '200':
+          description: Pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        default: *error
+  /owners:
+    get:
+      operationId: listOwners
+      responses:
+        '200':
+          description: Owners
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Owner'
+        default: *error
+components:
+  schemas:
+    Error:
+      type: object
+      properties:
+        message:
+          type: string
+    Pet:
+      type: object
+      x-repose:
+        create: true
+      properties:
+        <<: *timestamps
+        name:
+          type: string
+    Owner:
+      type: object
+      properties:
+        <<: *timestamps
+        address:
+          type: string
+`
+
+	state := &common.State{}
+	ctx := context.WithValue(context.Background(), common.ContextState, state)
+
+	sp, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas := make(map[string]*spec.Schema)
+	for _, s := range sp.Schemas {
+		schemas[s.Name] = s
+	}
+
+	for _, name := range []string{"Pet", "Owner"} {
+		props := schemas[name].Children.Map
+		assert.Equal(t, len(props), 3)
+		assert.Equal(t, props["created"].PrimitiveType, props["updated"].PrimitiveType)
+	}
+
+	for _, id := range []string{"listPets", "listOwners"} {
+		op := findOperation(sp, id)
+		assert.Equal(t, len(op.Responses), 2)
+	}
+
+	var stripped map[string]interface{}
+	err = stdjson.Unmarshal(state.SpecData(), &stripped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := stripped["paths"].(map[string]interface{})
+	for _, p := range []string{"/pets", "/owners"} {
+		get := paths[p].(map[string]interface{})["get"].(map[string]interface{})
+		_, hasExt := get["x-repose"]
+		assert.Equal(t, hasExt, false)
+
+		def := get["responses"].(map[string]interface{})["default"].(map[string]interface{})
+		assert.Equal(t, def["description"], "Error")
+	}
+
+	components := stripped["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, name := range []string{"Pet", "Owner"} {
+		schema := components[name].(map[string]interface{})
+		_, hasExt := schema["x-repose"]
+		assert.Equal(t, hasExt, false)
+		assert.Equal(t, len(schema["properties"].(map[string]interface{})), 3)
+	}
+}
+
+func TestPropertyNames(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Color": {"type": "string", "enum": ["red", "green"]},
+      "Stock": {
+        "type": "object",
+        "propertyNames": {"$ref": "#/components/schemas/Color"},
+        "additionalProperties": {"type": "integer"}
+      },
+      "Labels": {
+        "type": "object",
+        "propertyNames": {"pattern": "^[a-z]+$"},
+        "additionalProperties": {"type": "string"}
+      },
+      "Counts": {
+        "type": "object",
+        "x-repose": {"keyType": "Color"},
+        "additionalProperties": {"type": "integer"}
+      }
+    }
+  }
+}`)
+
+	schemas := make(map[string]*spec.Schema)
+	for _, s := range sp.Schemas {
+		schemas[s.Name] = s
+	}
+
+	assert.Equal(t, schemas["Stock"].Variant, spec.VariantMap)
+	assert.Equal(t, schemas["Stock"].Children.Array[0].Name, "Color")
+	assert.Equal(t, schemas["Labels"].Children.Array[0].PrimitiveType, "string")
+	assert.Equal(t, schemas["Counts"].Children.Array[0].PrimitiveType, "Color")
+}
+
+func TestBodyParameterName(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/posts/{body}": {
+      "parameters": [{"name": "body", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "put": {
+        "operationId": "updatePost",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Post"}}}
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    },
+    "/posts": {
+      "post": {
+        "operationId": "createPost",
+        "x-repose": {"bodyName": "newPost"},
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Post"}}}
+        },
+        "responses": {"204": {"description": "Created"}}
+      },
+      "patch": {
+        "operationId": "patchPost",
+        "x-repose": {"bodyName": "ctx"},
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Post"}}}
+        },
+        "responses": {"204": {"description": "Patched"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Post": {"type": "object", "properties": {"title": {"type": "string"}}}
+    }
+  }
+}`)
+
+	names := func(op *spec.Operation) map[spec.ParameterType]string {
+		n := make(map[spec.ParameterType]string)
+		for _, p := range op.Parameters {
+			n[p.Type] = p.Name
+		}
+		return n
+	}
+
+	updatePost := names(findOperation(sp, "updatePost"))
+	assert.Equal(t, updatePost[spec.ParameterTypePath], "body")
+	assert.Equal(t, updatePost[spec.ParameterTypeBody], "requestBody")
+
+	createPost := names(findOperation(sp, "createPost"))
+	assert.Equal(t, createPost[spec.ParameterTypeBody], "newPost")
+
+	// The context parameter of the generated methods is named ctx.
+	patchPost := names(findOperation(sp, "patchPost"))
+	assert.Equal(t, patchPost[spec.ParameterTypeBody], "requestCtx")
+
+	for _, name := range []string{"new-post", "func", "1post"} {
+		ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+		_, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/posts": {
+      "post": {
+        "operationId": "createPost",
+        "x-repose": {"bodyName": "`+name+`"},
+        "requestBody": {"content": {"application/json": {"schema": {"type": "string"}}}},
+        "responses": {"204": {"description": "Created"}}
+      }
+    }
+  }
+}`))
+		assert.NotEqual(t, err, nil)
+	}
+}
+
+func TestBodyParameterNameFromSchema(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	sp, err := (&OpenAPI3{}).Parse(ctx, map[string]interface{}{
+		"bodyNameFromSchema": true,
+	}, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/types": {
+      "post": {
+        "operationId": "createType",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Type"}}}
+        },
+        "responses": {"204": {"description": "Created"}}
+      },
+      "put": {
+        "operationId": "reportError",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}
+        },
+        "responses": {"204": {"description": "Reported"}}
+      },
+      "patch": {
+        "operationId": "updatePost",
+        "requestBody": {
+          "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NewPost"}}}
+        },
+        "responses": {"204": {"description": "Updated"}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "Type": {"type": "object", "properties": {"name": {"type": "string"}}},
+      "Error": {"type": "object", "properties": {"message": {"type": "string"}}},
+      "NewPost": {"type": "object", "properties": {"title": {"type": "string"}}}
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bodyName := func(id string) string {
+		for _, p := range findOperation(sp, id).Parameters {
+			if p.Type == spec.ParameterTypeBody {
+				return p.Name
+			}
+		}
+		return ""
+	}
+
+	// Keywords and predeclared identifiers are renamed.
+	assert.Equal(t, bodyName("createType"), "requestType")
+	assert.Equal(t, bodyName("reportError"), "requestError")
+	assert.Equal(t, bodyName("updatePost"), "newPost")
+}
+
+func TestBasePath(t *testing.T) {
+	for _, tc := range []struct {
+		servers  string
+		basePath string
+	}{
+		{`[{"url": "/api/v1/"}]`, "/api/v1"},
+		{`[{"url": "https://example.com/api/v1"}]`, ""},
+		{`[]`, ""},
+	} {
+		sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "servers": `+tc.servers+`,
+  "paths": {}
+}`)
+
+		assert.Equal(t, sp.BasePath, tc.basePath)
+	}
+}
+
+func TestSchemalessJSONResponse(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/status": {
+      "get": {
+        "operationId": "getStatus",
+        "responses": {
+          "200": {
+            "description": "Status",
+            "content": {"application/json": {"example": {"ok": true}}}
+          },
+          "204": {"description": "No status"}
+        }
+      }
+    }
+  }
+}`)
+
+	op := findOperation(sp, "getStatus")
+	for _, res := range op.Responses {
+		switch res.Code {
+		case "200":
+			assert.Equal(t, res.Schema != nil, true)
+			assert.Equal(t, res.Schema.Variant, spec.VariantAny)
+		case "204":
+			assert.Equal(t, res.Schema == nil, true)
+		}
+	}
+}
+
+func TestPathItemRef(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "operationId": "getPet",
+        "responses": {"204": {"description": "Found"}}
+      }
+    },
+    "/animals/{id}": {
+      "$ref": "#/paths/~1pets~1{id}",
+      "description": "Animals are pets too"
+    }
+  }
+}`)
+
+	assert.Equal(t, len(sp.Paths), 2)
+
+	for _, p := range sp.Paths {
+		assert.Equal(t, len(p.Operations), 1)
+		assert.Equal(t, p.Operations[0].ID, "getPet")
+		assert.Equal(t, len(p.Operations[0].Parameters), 1)
+		assert.Equal(t, p.Operations[0].Parameters[0].Name, "id")
+
+		if p.PathString == "/animals/{id}" {
+			assert.Equal(t, p.Description, "Animals are pets too")
+			assert.Equal(t, p.Operations[0].Name, "GetPetAnimalsID")
+		} else {
+			assert.Equal(t, p.Operations[0].Name, "GetPet")
+		}
+	}
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	_, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {"$ref": "#/paths/~1animals"}
+  }
+}`))
+	assert.NotEqual(t, err, nil)
+}
+
+func TestCursorPagination(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "cursor", "in": "query", "schema": {"type": "string"}}],
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "items": {"type": "array", "items": {"type": "string"}},
+                    "next_cursor": {"type": "string"}
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`)
+
+	pagination := sp.Paths[0].Operations[0].Pagination
+	assert.NotEqual(t, pagination, nil)
+	assert.Equal(t, pagination.CursorParam, "cursor")
+	assert.Equal(t, pagination.NextCursorField, "next_cursor")
+
+	// The cursor parameter can be defined for the path.
+	sp = parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "parameters": [{"name": "cursor", "in": "query", "schema": {"type": "string"}}],
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "object",
+                  "properties": {
+                    "items": {"type": "array", "items": {"type": "string"}},
+                    "nextCursor": {"type": "string"}
+                  }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`)
+
+	pagination = sp.Paths[0].Operations[0].Pagination
+	assert.NotEqual(t, pagination, nil)
+	assert.Equal(t, pagination.CursorParam, "cursor")
+	assert.Equal(t, pagination.NextCursorField, "nextCursor")
+}
+
+func TestPathLevelPagination(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "parameters": [
+        {"name": "limit", "in": "query", "schema": {"type": "integer"}},
+        {"name": "offset", "in": "query", "schema": {"type": "integer"}}
+      ],
+      "get": {
+        "operationId": "listPets",
+        "responses": {
+          "200": {
+            "description": "Pets",
+            "content": {"application/json": {"schema": {"type": "array", "items": {"type": "string"}}}}
+          }
+        }
+      }
+    }
+  }
+}`)
+
+	pagination := sp.Paths[0].Operations[0].Pagination
+	assert.NotEqual(t, pagination, nil)
+	assert.Equal(t, pagination.LimitParam, "limit")
+	assert.Equal(t, pagination.OffsetParam, "offset")
+}
+
+func TestUseTitleAsName(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "pet-v2": {"title": "Pet", "type": "object", "properties": {"name": {"type": "string"}}},
+      "kind": {"title": "type", "type": "string"},
+      "owner": {
+        "title": "Pet owner",
+        "type": "object",
+        "properties": {"pet": {"$ref": "#/components/schemas/pet-v2"}}
+      }
+    }
+  }
+}`
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	sp, err := (&OpenAPI3{}).Parse(ctx, map[string]interface{}{"useTitleAsName": true}, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]*spec.Schema)
+	for _, s := range sp.Schemas {
+		names[s.Name] = s
+	}
+
+	assert.NotEqual(t, names["Pet"], nil)
+	assert.Equal(t, names["Pet"].OriginalName, "pet-v2")
+	// The title is not a valid identifier.
+	assert.NotEqual(t, names["owner"], nil)
+	assert.Equal(t, names["owner"].Children.Map["Pet"].Name, "Pet")
+	// Keywords are not valid identifiers either.
+	assert.NotEqual(t, names["kind"], nil)
+	assert.Equal(t, names["type"], nil)
+
+	_, err = (&OpenAPI3{}).Parse(ctx, map[string]interface{}{"useTitleAsName": true}, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {"type": "object"},
+      "Animal": {"title": "Pet", "type": "object"}
+    }
+  }
+}`))
+	assert.NotEqual(t, err, nil)
+}
+
+func TestResolveReferencesInRepeated(t *testing.T) {
+	data := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {"$ref": "pet.json#/Pet"}
+    }
+  }
+}`
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	// Parsing used to panic the second time
+	// because of a registration on the default mux.
+	for i := 0; i < 2; i++ {
+		dir, err := ioutil.TempDir("", "repose-refs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		err = ioutil.WriteFile(
+			filepath.Join(dir, "pet.json"),
+			[]byte(`{"Pet": {"type": "object", "properties": {"name": {"type": "string"}}}}`),
+			0644,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sp, err := (&OpenAPI3{}).Parse(ctx, map[string]interface{}{"resolveReferencesIn": dir}, []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var pet *spec.Schema
+		for _, s := range sp.Schemas {
+			if s.Name == "Pet" {
+				pet = s
+			}
+		}
+
+		if pet == nil {
+			t.Fatalf("Pet was not resolved in iteration %v", i)
+		}
+
+		assert.Equal(t, pet.Variant, spec.VariantStruct)
+		assert.NotEqual(t, pet.Children.Map["Name"], nil)
+	}
+}
+
+func TestResolveReferencesTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	_, err := (&OpenAPI3{}).Parse(ctx, map[string]interface{}{
+		"resolveReferencesAt": srv.URL + "/",
+		"resolveTimeout":      1,
+	}, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {"$ref": "pet.json#/Pet"}
+    }
+  }
+}`))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), "timed out"), true)
+}
+
+func TestResolveReferencesMaxDepth(t *testing.T) {
+	// Every document refers to the next one.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		if _, err := fmt.Sscanf(path.Base(r.URL.Path), "%d.json", &n); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprintf(w, `{"Pet": {"$ref": "%v.json#/Pet"}}`, n+1)
+	}))
+	defer srv.Close()
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	_, err := (&OpenAPI3{}).Parse(ctx, map[string]interface{}{
+		"resolveReferencesAt": srv.URL + "/",
+		"resolveMaxDepth":     3,
+	}, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {},
+  "components": {
+    "schemas": {
+      "Pet": {"$ref": "0.json#/Pet"}
+    }
+  }
+}`))
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, strings.Contains(err.Error(), "nested deeper than 3 documents"), true)
+}
+
+func TestRootResource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repose-root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"api.yaml":            "openapi: 3.0.0\ninfo:\n  title: Test\n  version: 1.0.0\npaths: {}\n",
+		"schemas/pet.yaml":    "Pet:\n  type: object\n",
+		"schemas/openapi.txt": "not a document",
+	}
+
+	paths := make([]string, 0, len(files))
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	root, err := rootResource(paths)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, root, filepath.Join(dir, "api.yaml"))
+
+	// Usual names are preferred, the shallowest one is the root.
+	root, err = rootResource([]string{
+		filepath.Join(dir, "nested", "openapi.yaml"),
+		filepath.Join(dir, "openapi.yaml"),
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, root, filepath.Join(dir, "openapi.yaml"))
+
+	_, err = rootResource([]string{
+		filepath.Join(dir, "a", "openapi.yaml"),
+		filepath.Join(dir, "b", "swagger.json"),
+	})
+	assert.NotEqual(t, err, nil)
+}
+
+func TestSeparatedOperationIDs(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "pets.list", "responses": {"204": {"description": "Pets"}}},
+      "post": {"operationId": "pets/create", "responses": {"204": {"description": "Created"}}}
+    },
+    "/v2/pets": {
+      "get": {"operationId": "pets/list", "responses": {"204": {"description": "Pets"}}}
+    },
+    "/owners": {
+      "get": {"operationId": "owners.v1/list-all", "responses": {"204": {"description": "Owners"}}}
+    }
+  }
+}`)
+
+	assert.Equal(t, findOperation(sp, "pets.list").Name, "PetsList")
+	assert.Equal(t, findOperation(sp, "pets/create").Name, "PetsCreate")
+	assert.Equal(t, findOperation(sp, "owners.v1/list-all").Name, "OwnersV1ListAll")
+
+	// The names are deduplicated in the order of the IDs.
+	assert.Equal(t, findOperation(sp, "pets/list").Name, "PetsList2")
+}
+
+func TestOperationNameInitialisms(t *testing.T) {
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+	ctx = context.WithValue(ctx, common.ContextInitialisms, util.NewInitialisms([]string{"sku"}))
+
+	sp, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}/url": {
+      "get": {"operationId": "getPetUrl", "responses": {"204": {"description": "URL"}}}
+    },
+    "/items/{id}/sku": {
+      "get": {"operationId": "getItemSku", "responses": {"204": {"description": "SKU"}}},
+      "put": {"responses": {"204": {"description": "SKU"}}}
+    }
+  }
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, findOperation(sp, "getPetUrl").Name, "GetPetURL")
+	assert.Equal(t, findOperation(sp, "getItemSku").Name, "GetItemSKU")
+
+	// Generated names are the same as the parsed ones.
+	err = (&transformer.Default{}).Transform(ctx, nil, sp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range sp.Paths {
+		for _, op := range p.Operations {
+			if op.Method == "PUT" {
+				assert.MatchRegex(t, op.Name, `^Put.*SKU`)
+			}
+		}
+	}
+}
+
+func TestDuplicateOperationIDs(t *testing.T) {
+	// The paths are parsed in a random order,
+	// the names must not depend on it.
+	for i := 0; i < 10; i++ {
+		sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets/{id}": {
+      "get": {"operationId": "getPet", "responses": {"204": {"description": "Pet"}}}
+    },
+    "/animals/{id}": {
+      "get": {"operationId": "getPet", "responses": {"204": {"description": "Pet"}}}
+    },
+    "/dogs/{id}": {
+      "get": {"operationId": "getPet", "responses": {"204": {"description": "Pet"}}}
+    }
+  }
+}`)
+
+		names := make(map[string]string)
+		for _, p := range sp.Paths {
+			names[p.PathString] = p.Operations[0].Name
+		}
+
+		assert.Equal(t, names["/animals/{id}"], "GetPet")
+		assert.Equal(t, names["/dogs/{id}"], "GetPet2")
+		assert.Equal(t, names["/pets/{id}"], "GetPet3")
+	}
+}
+
+func TestOperationsWithoutIDs(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {"responses": {"204": {"description": "Pets"}}},
+      "post": {"responses": {"204": {"description": "Pet"}}}
+    },
+    "/animals": {
+      "get": {"responses": {"204": {"description": "Animals"}}}
+    }
+  }
+}`)
+
+	// The operations are named by the transformer,
+	// they must not be numbered as duplicates.
+	for _, p := range sp.Paths {
+		for _, op := range p.Operations {
+			assert.Equal(t, op.Name, "")
+		}
+	}
+}
+
+func TestParameterExamples(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "findPets",
+        "parameters": [
+          {"name": "limit", "in": "query", "schema": {"type": "integer"}, "example": 10},
+          {
+            "name": "tags",
+            "in": "query",
+            "schema": {"type": "array", "items": {"type": "string"}},
+            "examples": {
+              "dogs": {"summary": "Dogs only", "value": ["dog"]},
+              "cats": {"value": ["cat"]}
+            }
+          }
+        ],
+        "responses": {"204": {"description": "Found"}}
+      }
+    }
+  }
+}`)
+
+	findPets := findOperation(sp, "findPets")
+	assert.Equal(t, len(findPets.Parameters), 2)
+
+	for _, p := range findPets.Parameters {
+		switch p.Name {
+		case "limit":
+			assert.Equal(t, len(p.Examples), 1)
+			assert.Equal(t, p.Examples[0].Name, "default")
+			assert.Equal(t, p.Examples[0].Value, float64(10))
+		case "tags":
+			assert.Equal(t, len(p.Examples), 2)
+			assert.Equal(t, p.Examples[0].Name, "cats")
+			assert.Equal(t, p.Examples[1].Name, "dogs")
+			assert.Equal(t, p.Examples[1].Summary, "Dogs only")
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	sp := parseTestSpec(t, `{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "responses": {
+          "201": {
+            "description": "Pet",
+            "links": {
+              "GetPet": {
+                "operationId": "getPet",
+                "description": "The created pet.",
+                "parameters": {"id": "$response.body#/id"}
+              }
+            }
+          }
+        }
+      }
+    },
+    "/pets/{id}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+        "responses": {"204": {"description": "Pet"}}
+      }
+    }
+  }
+}`)
+
+	links := findOperation(sp, "addPet").Responses[0].Links
+	assert.Equal(t, len(links), 1)
+	assert.Equal(t, links[0].Name, "GetPet")
+	assert.Equal(t, links[0].OperationID, "getPet")
+	assert.Equal(t, links[0].Description, "The created pet.")
+	assert.Equal(t, links[0].Parameters["id"], "$response.body#/id")
+
+	ctx := context.WithValue(context.Background(), common.ContextState, &common.State{})
+
+	_, err := (&OpenAPI3{}).Parse(ctx, nil, []byte(`{
+  "openapi": "3.0.0",
+  "info": {"title": "Test", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "addPet",
+        "responses": {
+          "201": {
+            "description": "Pet",
+            "links": {
+              "GetPet": {"operationRef": "#/paths/~1pets~1{id}/get"}
+            }
+          }
+        }
+      }
+    }
+  }
+}`))
+	assert.NotEqual(t, err, nil)
+	assert.MatchRegex(t, err.Error(), "operationRef is not supported")
+}
@@ -56,9 +56,64 @@ type Schema struct {
 	// Used for enum types
 	Enum []interface{}
 
+	// EnumNames are the names of the enum values
+	// from the x-enum-varnames or x-enumNames extensions, if any,
+	// in the same order as the values.
+	EnumNames []string
+
+	// Default value of the schema from the specification, if any.
+	Default interface{}
+
+	// Format of the schema from the specification, if any.
+	Format string
+
+	// Sensitive indicates that the value
+	// must not be printed, e.g. in logs.
+	Sensitive bool
+
+	// MultipleOf is the number that numeric
+	// values must be a multiple of, if any.
+	MultipleOf *float64
+
+	// MinItems and MaxItems are the length
+	// constraints of array schemas, if any.
+	MinItems uint64
+	MaxItems *uint64
+
+	// Minimum and Maximum are the bounds of numeric
+	// schemas, if any, ExclusiveMinimum and ExclusiveMaximum
+	// indicate that the bounds themselves are not valid values.
+	Minimum          *float64
+	Maximum          *float64
+	ExclusiveMinimum bool
+	ExclusiveMaximum bool
+
+	// MinLength and MaxLength are the length
+	// constraints of string schemas, if any.
+	MinLength uint64
+	MaxLength *uint64
+
+	// Pattern is the regular expression string
+	// values must match, if any.
+	Pattern string
+
+	// RequiredProperties are the original names of
+	// the required properties of struct schemas.
+	RequiredProperties []string
+
+	// Tuple contains the schemas of the items
+	// by position for tuple arrays (prefixItems), if any.
+	Tuple []*Schema
+
+	// Discriminator of oneOf and anyOf schemas, if any.
+	Discriminator *Discriminator
+
 	// Children are needed in cases like when the
 	// parent object is a struct, or a compound object.
 	Children *SchemaObject
+
+	// Vendor extensions of the schema, if any.
+	Extensions map[string]interface{}
 }
 
 // SchemaVariant defines the variant of the schema.
@@ -308,6 +363,19 @@ func (s *Schema) HasChildren() bool {
 	return s.Children.IsArray() || s.Children.IsMap() || s.Children.IsSchema()
 }
 
+// Discriminator describes the property that
+// decides the schema of a oneOf or anyOf value.
+type Discriminator struct {
+	// PropertyName is the name of the property
+	// that contains the discriminator value.
+	PropertyName string
+
+	// Mapping contains the schema names by discriminator
+	// values, the names of the schemas themselves are used
+	// for values that are not in it.
+	Mapping map[string]string
+}
+
 // SchemaPath is a path of nested schemas.
 // It is used while walking a schema.
 type SchemaPath []*Schema
@@ -398,6 +466,10 @@ func (s *Schema) walk(path []*Schema, walker SchemaWalker) {
 		s.AdditionalProps.walk(newPath, walker)
 	}
 
+	for _, t := range s.Tuple {
+		t.walk(newPath, walker)
+	}
+
 	if s.Children == nil {
 		return
 	}
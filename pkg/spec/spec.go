@@ -12,6 +12,10 @@ type Spec struct {
 	Paths []*Path `json:"paths"`
 	// Schemas used in the specification
 	Schemas []*Schema `json:"schemas"`
+
+	// BasePath is the path of the server if its URL
+	// is relative (e.g. /api/v1), the paths are relative to it.
+	BasePath string `json:"basePath"`
 }
 
 // Path is a HTTP REST-like path.
@@ -32,6 +36,9 @@ type Path struct {
 
 	// Operations of the path
 	Operations []*Operation `json:"operations"`
+
+	// Vendor extensions of the path, if any.
+	Extensions map[string]interface{} `json:"extensions"`
 }
 
 // Operation is a HTTP operation.
@@ -59,6 +66,46 @@ type Operation struct {
 
 	// Callbacks of the operation
 	Callbacks map[string][]*Path `json:"callbacks"`
+
+	// External documentation of the operation, if any.
+	ExternalDocs *ExternalDocs `json:"externalDocs"`
+
+	// Vendor extensions of the operation, if any.
+	Extensions map[string]interface{} `json:"extensions"`
+
+	// Pagination of the operation, if it is paginated.
+	Pagination *Pagination `json:"pagination"`
+
+	// MaxBodyBytes is the maximum size of the
+	// request body, or 0 if it is not limited.
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+}
+
+// Pagination describes the query parameters of a paginated operation,
+// either limit and offset, or a cursor for cursor-based pagination.
+type Pagination struct {
+	// Name of the parameter for the maximum number of items.
+	LimitParam string `json:"limitParam"`
+
+	// Name of the parameter for the index of the first item.
+	OffsetParam string `json:"offsetParam"`
+
+	// Name of the parameter for the cursor of the page,
+	// only set for cursor-based pagination.
+	CursorParam string `json:"cursorParam"`
+
+	// Name of the property of the response
+	// that contains the cursor of the next page.
+	NextCursorField string `json:"nextCursorField"`
+}
+
+// ExternalDocs is a reference to external documentation.
+type ExternalDocs struct {
+	// URL of the documentation.
+	URL string `json:"url"`
+
+	// Description of the documentation, if any.
+	Description string `json:"description"`
 }
 
 // ParameterType describes where the parameter is expected.
@@ -122,13 +169,39 @@ type Parameter struct {
 
 	Serialization ParameterSerialization `json:"serialization"`
 
+	// Encoding of the properties of multipart and
+	// form bodies by property name, if any.
+	Encoding map[string]*Encoding `json:"encoding"`
+
+	// Examples of the parameter or the body, if any.
+	Examples []*Example `json:"examples"`
+
 	// Marks the parameter as required.
 	Required bool `json:"required"`
 }
 
+// Encoding describes how a property of a
+// multipart or form body is serialized.
+type Encoding struct {
+	// The content type of the property,
+	// for multipart bodies it is the content type of the part.
+	ContentType string `json:"contentType"`
+}
+
+// IsPtr checks whether the parameter should be passed by reference.
+//
+// Request bodies are pointers only if they are optional,
+// so that a missing body can be told apart from an empty one.
 func (p *Parameter) IsPtr() bool {
-	return p.Schema != nil && (!p.Required || p.Schema.ShouldBePtr()) &&
-		!p.Schema.CanBeNil()
+	if p.Schema == nil || p.Schema.CanBeNil() {
+		return false
+	}
+
+	if p.Type == ParameterTypeBody {
+		return !p.Required
+	}
+
+	return !p.Required || p.Schema.ShouldBePtr()
 }
 
 // Response is one of the expected responses
@@ -149,6 +222,67 @@ type Response struct {
 
 	// The schema of the response, if any.
 	Schema *Schema `json:"schema"`
+
+	// Links to other operations that can
+	// follow the response, if any.
+	Links []*Link `json:"links"`
+
+	// Headers of the response, if any.
+	Headers []*Header `json:"headers"`
+
+	// Examples of the response body, if any.
+	Examples []*Example `json:"examples"`
+}
+
+// Example is an example value of a request or response body.
+type Example struct {
+	// Name of the example, the example given
+	// without a name is called "default".
+	Name string `json:"name"`
+
+	// Summary of the example if any.
+	Summary string `json:"summary"`
+
+	// Description of the example if any.
+	Description string `json:"description"`
+
+	// The value of the example, it is nil if the value is external.
+	Value interface{} `json:"value"`
+
+	// URL of the value if it is not in the specification.
+	ExternalValue string `json:"externalValue"`
+}
+
+// Header is a HTTP header of a response.
+type Header struct {
+	// Name of the header.
+	Name string `json:"name"`
+
+	// Description of the header if any.
+	Description string `json:"description"`
+
+	// Marks the header as required.
+	Required bool `json:"required"`
+
+	// The schema of the header value.
+	Schema *Schema `json:"schema"`
+}
+
+// Link describes a relationship between a response
+// and another operation.
+type Link struct {
+	// Name of the link.
+	Name string `json:"name"`
+
+	// The ID of the linked operation.
+	OperationID string `json:"operationId"`
+
+	// Description of the link if any.
+	Description string `json:"description"`
+
+	// Parameters of the linked operation mapped to
+	// values or runtime expressions (e.g. "$response.body#/id").
+	Parameters map[string]string `json:"parameters"`
 }
 
 func (r *Response) IsPtr() bool {
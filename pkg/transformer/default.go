@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/Masterminds/sprig"
 	"github.com/mitchellh/mapstructure"
@@ -26,13 +27,20 @@ import (
 // TagTemplateValues contains values for tag templates.
 type TagTemplateValues struct {
 	Description string `description:"Description of the schema"`
-	FieldName   string `description:"Name of the field"`
+	FieldName   string `description:"Name of the field in the specification, as it appears on the wire"`
+	GoName      string `description:"Name of the field in the generated Go struct"`
 	Type        string `description:"Type of the field"`
 }
 
 // DefaultOptions alters the behaviour of the code generator.
 type DefaultOptions struct {
-	Tags map[string][]string `yaml:"tags,omitempty" description:"Add additional tags to struct fields. Supports Go templating with sprig functions"`
+	Tags              map[string][]string `yaml:"tags,omitempty" description:"Add additional tags to struct fields. Supports Go templating with sprig functions"`
+	BodyContentTypes  []string            `yaml:"bodyContentTypes,omitempty" description:"Priority list of request body content types, if an operation accepts multiple, only the body with the first matching content type is kept"`
+	ContentTypeBodies bool                `yaml:"contentTypeBodies" description:"Keep a separate optional body for each content type of operations that accept different schemas for different content types (e.g. bodyJSON and bodyXML), only the body of the content type of the request is set, bodyContentTypes is used for the other operations"`
+	ExcludePaths      []string            `yaml:"excludePaths,omitempty" description:"Glob patterns of paths to leave out of the generated code, * matches within a path segment, ** matches across segments (e.g. /admin/**)"`
+	ExcludeOperations []string            `yaml:"excludeOperations,omitempty" description:"Operation IDs to leave out of the generated code"`
+	TypeMappings      map[string]string   `yaml:"typeMappings,omitempty" description:"Existing Go types to use instead of generating types for schemas, keyed by the schema name, the types are given with their full package path (e.g. Money: github.com/user/money.Amount)"`
+	ValidateTags      bool                `yaml:"validateTags" description:"Add validate tags for go-playground/validator to struct fields based on the constraints of the schemas (required, minimum, maximum, lengths and enums), patterns are not supported by the validator and are left out"`
 }
 
 // MarshalYAML implements YAML Marshaler.
@@ -114,6 +122,9 @@ func (d *Default) DefaultOptions() interface{} {
 		Tags: map[string][]string{
 			"json": []string{"{{ .FieldName }}", "omitempty"},
 		},
+		BodyContentTypes:  []string{"application/json"},
+		ContentTypeBodies: false,
+		ValidateTags:      false,
 	}
 }
 
@@ -126,6 +137,21 @@ func (d *Default) Transform(ctx context.Context, rawOpts interface{}, sp *spec.S
 		return fmt.Errorf("invalid options: %w", err)
 	}
 
+	err = d.Exclude(ctx, sp, opts)
+	if err != nil {
+		return err
+	}
+
+	err = d.ApplyTypeMappings(ctx, sp, opts)
+	if err != nil {
+		return err
+	}
+
+	err = d.SelectBodyContentTypes(ctx, sp, opts)
+	if err != nil {
+		return err
+	}
+
 	err = d.AddTags(ctx, sp, opts)
 	if err != nil {
 		return err
@@ -174,6 +200,290 @@ func (d *Default) Transform(ctx context.Context, rawOpts interface{}, sp *spec.S
 	return nil
 }
 
+// Exclude removes the paths and operations
+// from the specification that are excluded in the options.
+// Paths that have no operations left are removed as well.
+func (d *Default) Exclude(ctx context.Context, sp *spec.Spec, opts *DefaultOptions) error {
+	if len(opts.ExcludePaths) == 0 && len(opts.ExcludeOperations) == 0 {
+		return nil
+	}
+
+	pathPatterns := make([]*regexp.Regexp, 0, len(opts.ExcludePaths))
+
+	for _, pattern := range opts.ExcludePaths {
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid path pattern %v: %w", pattern, err)
+		}
+		pathPatterns = append(pathPatterns, re)
+	}
+
+	excludedOps := make(map[string]bool, len(opts.ExcludeOperations))
+	for _, id := range opts.ExcludeOperations {
+		excludedOps[id] = true
+	}
+
+	paths := make([]*spec.Path, 0, len(sp.Paths))
+
+PathLoop:
+	for _, p := range sp.Paths {
+		for _, re := range pathPatterns {
+			if re.MatchString(p.PathString) {
+				continue PathLoop
+			}
+		}
+
+		ops := make([]*spec.Operation, 0, len(p.Operations))
+
+		for _, o := range p.Operations {
+			if !excludedOps[o.ID] {
+				ops = append(ops, o)
+			}
+		}
+
+		if len(ops) == 0 && len(p.Operations) != 0 {
+			continue
+		}
+
+		p.Operations = ops
+		paths = append(paths, p)
+	}
+
+	sp.Paths = paths
+
+	return nil
+}
+
+// globToRegexp converts a path glob pattern to a regular expression,
+// where * and ? do not match slashes, but ** does.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	expr := &strings.Builder{}
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// ApplyTypeMappings replaces the schemas listed in the type mappings
+// with the given existing types, the same way the type extension
+// of the parser does with create set to false.
+func (d *Default) ApplyTypeMappings(ctx context.Context, sp *spec.Spec, opts *DefaultOptions) error {
+	if len(opts.TypeMappings) == 0 {
+		return nil
+	}
+
+	walkFunc := func(path spec.SchemaPath) error {
+		last := path.Last()
+
+		if last == nil {
+			return nil
+		}
+
+		name := last.OriginalName
+		if name == "" {
+			name = last.Name
+		}
+
+		goType, ok := opts.TypeMappings[name]
+		if !ok || goType == "" {
+			return nil
+		}
+
+		last.Name = goType
+		last.Create = false
+		last.SetVariant(spec.VariantPrimitive)
+		last.PrimitiveType = ""
+		last.Children = nil
+		last.AdditionalProps = nil
+		last.AdditionalPropsName = ""
+		last.Tuple = nil
+		last.Enum = nil
+		last.EnumNames = nil
+
+		return nil
+	}
+
+	for _, s := range sp.Schemas {
+		err := s.Walk(walkFunc, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			for _, param := range o.Parameters {
+				err := param.Schema.Walk(walkFunc, false)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, res := range o.Responses {
+				err := res.Schema.Walk(walkFunc, false)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, cb := range o.Callbacks {
+				for _, cbPath := range cb {
+					for _, cbOp := range cbPath.Operations {
+						for _, param := range cbOp.Parameters {
+							err := param.Schema.Walk(walkFunc, false)
+							if err != nil {
+								return err
+							}
+						}
+
+						for _, res := range cbOp.Responses {
+							err := res.Schema.Walk(walkFunc, false)
+							if err != nil {
+								return err
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// SelectBodyContentTypes keeps a single body parameter for operations
+// that accept multiple request content types, based on the priority list in the options.
+// If none of the content types are in the list, the first one in alphabetical order is kept.
+func (d *Default) SelectBodyContentTypes(ctx context.Context, sp *spec.Spec, opts *DefaultOptions) error {
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			var bodies []*spec.Parameter
+
+			for _, param := range o.Parameters {
+				if param.Type == spec.ParameterTypeBody {
+					bodies = append(bodies, param)
+				}
+			}
+
+			if len(bodies) < 2 {
+				continue
+			}
+
+			sort.Slice(bodies, func(i, j int) bool {
+				return bodies[i].ContentType < bodies[j].ContentType
+			})
+
+			if opts.ContentTypeBodies && hasDifferentSchemas(bodies) {
+				nameContentTypeBodies(ctx, bodies)
+				continue
+			}
+
+			selected := bodies[0]
+
+		priority:
+			for _, contentType := range opts.BodyContentTypes {
+				for _, body := range bodies {
+					if contentTypeMatches(body.ContentType, contentType) {
+						selected = body
+						break priority
+					}
+				}
+			}
+
+			params := make([]*spec.Parameter, 0, len(o.Parameters)-len(bodies)+1)
+
+			for _, param := range o.Parameters {
+				if param.Type == spec.ParameterTypeBody && param != selected {
+					continue
+				}
+				params = append(params, param)
+			}
+
+			o.Parameters = params
+		}
+	}
+
+	return nil
+}
+
+// hasDifferentSchemas checks whether the bodies have different schemas,
+// inline schemas are always considered different.
+func hasDifferentSchemas(bodies []*spec.Parameter) bool {
+	for _, body := range bodies {
+		if body.Schema == nil || body.Schema.Name == "" ||
+			bodies[0].Schema == nil || body.Schema.Name != bodies[0].Schema.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nameContentTypeBodies gives the bodies of an operation unique names
+// based on their content types, and makes them optional, as only
+// one of them is sent in a request.
+func nameContentTypeBodies(ctx context.Context, bodies []*spec.Parameter) {
+	names := make(map[string]bool, len(bodies))
+
+	for _, body := range bodies {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(body.ContentType, ";")[0]))
+
+		// Suffixes like +json are separate words.
+		mediaType = strings.Map(func(r rune) rune {
+			if r == '/' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '-'
+		}, mediaType)
+
+		parts := strings.SplitN(mediaType, "/", 2)
+
+		suffix := common.Initialisms(ctx).ToGoName(strcase.ToCamel(parts[len(parts)-1]))
+		if names[suffix] {
+			suffix = common.Initialisms(ctx).ToGoName(strcase.ToCamel(strings.Join(parts, "-")))
+		}
+		names[suffix] = true
+
+		body.Name += suffix
+		body.Required = false
+	}
+}
+
+// contentTypeMatches reports whether the content type matches the pattern,
+// parameters such as charset are ignored, and "*" can be used as a wildcard
+// for the type or the subtype.
+func contentTypeMatches(contentType, pattern string) bool {
+	mediaType := func(s string) (string, string) {
+		s = strings.ToLower(strings.TrimSpace(strings.Split(s, ";")[0]))
+		parts := strings.SplitN(s, "/", 2)
+		if len(parts) != 2 {
+			return parts[0], ""
+		}
+		return parts[0], parts[1]
+	}
+
+	tp, subTp := mediaType(contentType)
+	patternTp, patternSubTp := mediaType(pattern)
+
+	return (patternTp == "*" || patternTp == tp) &&
+		(patternSubTp == "*" || patternSubTp == subTp)
+}
+
 // ExtractSchemas extracts the nested schemas that need to be created.
 // This only extracts schemas with a custom type, and leaves
 // allOfs and such alone.
@@ -284,9 +594,17 @@ func (d *Default) SimplifyInlineSchemas(ctx context.Context, sp *spec.Spec, opts
 			last.Map(spec.NewSchema().Primitive("string"), last.AdditionalProps)
 		}
 
+		// Tuples need custom JSON marshaling as well,
+		// anonymous ones are left as regular arrays.
+		if len(last.Tuple) > 0 && last.Name == "" {
+			last.Tuple = nil
+		}
+
 		if last.Name == "" {
-			// These can be anything, don't try to generate helper code with no names
-			if last.Variant == spec.VariantAnyOf || last.Variant == spec.VariantOneOf {
+			// These can be anything, don't try to generate helper code with no names,
+			// unless the concrete types can be decoded.
+			if (last.Variant == spec.VariantAnyOf || last.Variant == spec.VariantOneOf) &&
+				!isPolymorphicArrayItem(path) && !isDiscriminatedUnion(last) {
 				last.SetVariant(spec.VariantAny)
 			}
 		}
@@ -323,6 +641,47 @@ func (d *Default) SimplifyInlineSchemas(ctx context.Context, sp *spec.Spec, opts
 	return nil
 }
 
+// isPolymorphicArrayItem returns whether the last schema in the path
+// is the union item schema of a named array with only named variants.
+func isPolymorphicArrayItem(path spec.SchemaPath) bool {
+	if len(path) < 2 {
+		return false
+	}
+
+	parent := path[len(path)-2]
+	last := path.Last()
+
+	if parent.Name == "" || parent.Variant != spec.VariantArray ||
+		parent.Children == nil || parent.Children.Schema != last {
+		return false
+	}
+
+	return hasNamedVariants(last)
+}
+
+// isDiscriminatedUnion returns whether the schema is a union
+// with a discriminator and only named variants.
+func isDiscriminatedUnion(schema *spec.Schema) bool {
+	return schema.Discriminator != nil && hasNamedVariants(schema)
+}
+
+// hasNamedVariants returns whether the union has
+// children, and all of them are named.
+func hasNamedVariants(schema *spec.Schema) bool {
+	children := schema.Children.GetArray()
+	if len(children) == 0 {
+		return false
+	}
+
+	for _, c := range children {
+		if c == nil || c.Name == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ExtractAllOfs creates all the types for AllOfs.
 // AllOfs are a special type, all of their content
 // are basically embedded structs.
@@ -484,7 +843,7 @@ func (d *Default) GeneratePathNames(ctx context.Context, sp *spec.Spec, opts *De
 						continue
 					}
 
-					cbPath.Name = util.ToGoName(strings.Title(cbName + strcase.ToCamel(strings.Join(pathParts, "/"))))
+					cbPath.Name = common.Initialisms(ctx).ToGoName(strings.Title(cbName + strcase.ToCamel(strings.Join(pathParts, "/"))))
 				}
 			}
 		}
@@ -511,7 +870,7 @@ func (d *Default) GeneratePathNames(ctx context.Context, sp *spec.Spec, opts *De
 			continue
 		}
 
-		p.Name = util.ToGoName(strings.Title(strcase.ToCamel(strings.Join(pathParts, "/"))))
+		p.Name = common.Initialisms(ctx).ToGoName(strings.Title(strcase.ToCamel(strings.Join(pathParts, "/"))))
 	}
 
 	return nil
@@ -531,7 +890,7 @@ func (d *Default) GenerateOperationNames(ctx context.Context, sp *spec.Spec, opt
 
 			// The operation name is simply the method + the path name
 			// This relies on the path name already set.
-			o.Name = strcase.ToCamel(strings.ToLower(o.Method) + strings.Title(p.Name))
+			o.Name = common.Initialisms(ctx).ToGoName(strcase.ToCamel(strings.ToLower(o.Method) + strings.Title(p.Name)))
 		}
 	}
 
@@ -548,7 +907,7 @@ func (d *Default) GenerateResponseNames(ctx context.Context, sp *spec.Spec, opts
 
 			for _, r := range o.Responses {
 				if r.Name != "" {
-					r.Name = util.ToGoName(strcase.ToCamel(r.Name))
+					r.Name = common.Initialisms(ctx).ToGoName(strcase.ToCamel(r.Name))
 					continue
 				}
 
@@ -570,7 +929,7 @@ func (d *Default) AddTags(ctx context.Context, sp *spec.Spec, opts *DefaultOptio
 	// in which case setting tags in place is not enough, and has no effect.
 	addTagsFunc := func(tags map[string][]string, refNames *[]string) spec.SchemaWalker {
 		return func(path spec.SchemaPath) error {
-			if len(tags) == 0 {
+			if len(tags) == 0 && !opts.ValidateTags {
 				return errors.New("should stop")
 			}
 			sm := path.Last()
@@ -595,6 +954,21 @@ func (d *Default) AddTags(ctx context.Context, sp *spec.Spec, opts *DefaultOptio
 				actualTgs[k] = newTags
 			}
 
+			// The Go name of the field is the key
+			// of the schema in the parent struct.
+			var goName string
+			if len(path) > 1 {
+				parent := path[len(path)-2]
+				if parent.Children != nil {
+					for k, child := range parent.Children.Map {
+						if child == sm {
+							goName = k
+							break
+						}
+					}
+				}
+			}
+
 			for _, tag := range actualTgs {
 				name := sm.FieldName
 
@@ -611,6 +985,7 @@ func (d *Default) AddTags(ctx context.Context, sp *spec.Spec, opts *DefaultOptio
 					tagBuf := &bytes.Buffer{}
 					err = templ.Execute(tagBuf, &TagTemplateValues{
 						FieldName:   name,
+						GoName:      goName,
 						Type:        sm.Name,
 						Description: sm.Description,
 					})
@@ -622,6 +997,17 @@ func (d *Default) AddTags(ctx context.Context, sp *spec.Spec, opts *DefaultOptio
 				}
 			}
 
+			// Only struct fields can be validated by tags,
+			// and explicitly given tags are not overridden.
+			if opts.ValidateTags && len(path) > 1 && path[len(path)-2].Variant == spec.VariantStruct {
+				if _, exists := actualTgs["validate"]; !exists {
+					required := isRequiredProperty(path[len(path)-2], sm.FieldName)
+					if validate := validateTag(sm, required); len(validate) > 0 {
+						actualTgs["validate"] = validate
+					}
+				}
+			}
+
 			sm.Tags = actualTgs
 
 			if sm.Name != "" {
@@ -665,6 +1051,106 @@ func (d *Default) AddTags(ctx context.Context, sp *spec.Spec, opts *DefaultOptio
 	return nil
 }
 
+// isRequiredProperty checks whether the
+// property is required by the struct schema.
+func isRequiredProperty(parent *spec.Schema, name string) bool {
+	for _, required := range parent.RequiredProperties {
+		if required == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateTag returns the parts of the validate tag
+// for the constraints of the given struct field.
+func validateTag(sm *spec.Schema, required bool) []string {
+	var constraints []string
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	switch sm.Variant {
+	case spec.VariantPrimitive:
+		switch {
+		case sm.PrimitiveType == "string":
+			if sm.MinLength > 0 {
+				constraints = append(constraints, "min="+strconv.FormatUint(sm.MinLength, 10))
+			}
+			if sm.MaxLength != nil {
+				constraints = append(constraints, "max="+strconv.FormatUint(*sm.MaxLength, 10))
+			}
+		case strings.HasPrefix(sm.PrimitiveType, "int"),
+			strings.HasPrefix(sm.PrimitiveType, "uint"),
+			strings.HasPrefix(sm.PrimitiveType, "float"):
+			if sm.Minimum != nil {
+				if sm.ExclusiveMinimum {
+					constraints = append(constraints, "gt="+formatFloat(*sm.Minimum))
+				} else {
+					constraints = append(constraints, "min="+formatFloat(*sm.Minimum))
+				}
+			}
+			if sm.Maximum != nil {
+				if sm.ExclusiveMaximum {
+					constraints = append(constraints, "lt="+formatFloat(*sm.Maximum))
+				} else {
+					constraints = append(constraints, "max="+formatFloat(*sm.Maximum))
+				}
+			}
+		default:
+			// Mapped types such as time.Time
+			// cannot be validated this way.
+			return nil
+		}
+
+		if len(sm.Enum) > 0 {
+			values := make([]string, 0, len(sm.Enum))
+			for _, v := range sm.Enum {
+				value := fmt.Sprint(v)
+
+				// The values are separated by spaces in the tag,
+				// so such values cannot be listed.
+				if value == "" || strings.ContainsAny(value, " ,|\"") {
+					values = nil
+					break
+				}
+
+				values = append(values, value)
+			}
+
+			if len(values) > 0 {
+				constraints = append(constraints, "oneof="+strings.Join(values, " "))
+			}
+		}
+	case spec.VariantArray:
+		if sm.MinItems > 0 {
+			constraints = append(constraints, "min="+strconv.FormatUint(sm.MinItems, 10))
+		}
+		if sm.MaxItems != nil {
+			constraints = append(constraints, "max="+strconv.FormatUint(*sm.MaxItems, 10))
+		}
+	}
+
+	if required && !sm.Nullable {
+		// Only fields that can be nil are checked with "required",
+		// as it rejects the zero values of other types as well,
+		// the constraints of those are always checked instead.
+		if sm.ShouldBePtr() || sm.CanBeNil() {
+			return append([]string{"required"}, constraints...)
+		}
+
+		return constraints
+	}
+
+	if len(constraints) > 0 {
+		return append([]string{"omitempty"}, constraints...)
+	}
+
+	return nil
+}
+
 func (d *Default) addTagsToOperation(
 	ctx context.Context,
 	sp *spec.Spec,
@@ -790,6 +1276,30 @@ func (d *Default) AddPathComments(ctx context.Context, sp *spec.Spec, opts *Defa
 							strings.TrimSuffix(strings.TrimRight(res.Description, "\n"), ".")+"."),
 						)
 					}
+
+					for _, link := range res.Links {
+						o.Comments = append(o.Comments,
+							fmt.Sprintf("    Link \"%v\" to operation \"%v\".", link.Name, d.linkedOperationName(sp, link)),
+						)
+
+						paramNames := make([]string, 0, len(link.Parameters))
+						for paramName := range link.Parameters {
+							paramNames = append(paramNames, paramName)
+						}
+						sort.Strings(paramNames)
+
+						for _, paramName := range paramNames {
+							o.Comments = append(o.Comments,
+								fmt.Sprintf("        \"%v\" = %v", paramName, link.Parameters[paramName]),
+							)
+						}
+
+						if link.Description != "" && options.DescriptionComments {
+							o.Comments = append(o.Comments, fmt.Sprintf("        Description: %v\n",
+								strings.TrimSuffix(strings.TrimRight(link.Description, "\n"), ".")+"."),
+							)
+						}
+					}
 				}
 			}
 
@@ -799,6 +1309,20 @@ func (d *Default) AddPathComments(ctx context.Context, sp *spec.Spec, opts *Defa
 	return nil
 }
 
+// linkedOperationName returns the generated name of the operation
+// the link points to, or the original ID if it is not found.
+func (d *Default) linkedOperationName(sp *spec.Spec, link *spec.Link) string {
+	for _, p := range sp.Paths {
+		for _, o := range p.Operations {
+			if o.ID != "" && o.ID == link.OperationID {
+				return o.Name
+			}
+		}
+	}
+
+	return link.OperationID
+}
+
 // OrderResources orders all the spec resources in an alphabetical order.
 func (d *Default) OrderResources(ctx context.Context, sp *spec.Spec, opts *DefaultOptions) error {
 	sort.Slice(sp.Paths, func(i, j int) bool {
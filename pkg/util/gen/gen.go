@@ -39,7 +39,59 @@ func Raw(str string) *jen.Statement {
 	return jen.Op(str)
 }
 
-func PrimitiveFromString(s *spec.Schema, ptr bool, varName, strName jen.Code) (jen.Code, error) {
+// AssertImplements creates a compile-time assertion
+// that the implementation satisfies the interface, e.g.:
+//
+//	var _ api.Server = &ServerImpl{}
+//
+// It should be used by every generator that generates
+// implementations of generated interfaces.
+func AssertImplements(iface jen.Code, impl jen.Code) jen.Code {
+	return jen.Var().Id("_").Add(iface).Op("=").Add(impl)
+}
+
+// NormalizeImportPath validates and normalizes a package import path
+// given in the options, so that it can be used with Qual.
+//
+// The path must be a full import path (e.g. "github.com/user/module/types")
+// with a domain-like first element, relative, module-relative and file system
+// paths are rejected, as jen would silently generate invalid imports from them.
+// An empty path is returned as is.
+func NormalizeImportPath(path string) (string, error) {
+	path = strings.TrimSuffix(strings.TrimSpace(path), "/")
+
+	if path == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(path, "/") || strings.Contains(path, "\\") {
+		return "", fmt.Errorf("%q is a file system path, a full import path is required", path)
+	}
+
+	for _, element := range strings.Split(path, "/") {
+		switch element {
+		case "":
+			return "", fmt.Errorf("%q contains an empty path element", path)
+		case ".", "..":
+			return "", fmt.Errorf("%q is a relative path, a full import path is required", path)
+		}
+	}
+
+	if !strings.Contains(strings.Split(path, "/")[0], ".") {
+		return "", fmt.Errorf("%q is not a full import path, it must start with a domain-like element (e.g. github.com/user/module/types)", path)
+	}
+
+	return path, nil
+}
+
+// PrimitiveFromString returns the code that parses a primitive
+// value of the schema from a string.
+//
+// Custom primitive types are parsed with the functions of parsers keyed by
+// the type, the functions must have the signature func(string) (T, error),
+// both the type and the function are given with their full package path,
+// e.g. "github.com/google/uuid.UUID" and "github.com/google/uuid.Parse".
+func PrimitiveFromString(s *spec.Schema, ptr bool, varName, strName jen.Code, parsers map[string]string) (jen.Code, error) {
 
 	var assignRight jen.Code
 	if ptr {
@@ -129,7 +181,50 @@ func PrimitiveFromString(s *spec.Schema, ptr bool, varName, strName jen.Code) (j
 				"assignRight": assignRight,
 			},
 		)
+	case "time.Time":
+		// Dates are full-date values of RFC 3339 without the time.
+		layout := jen.Qual("time", "RFC3339")
+		if s.Format == "date" {
+			layout = jen.Lit("2006-01-02")
+		}
+
+		return Template(`
+		if _parsedVal, err := {{ .Parse }}({{ .Layout }}, {{ .strName }}); err == nil {
+			_v := _parsedVal
+			{{ .varName }} = {{ .assignRight }}
+		}`[1:],
+			Values{
+				"Parse":       jen.Qual("time", "Parse"),
+				"Layout":      layout,
+				"strName":     strName,
+				"varName":     varName,
+				"assignRight": assignRight,
+			},
+		)
 	default:
-		return nil, fmt.Errorf("not a primitive type")
+		parseFunc, ok := parsers[s.PrimitiveType]
+		if !ok {
+			return nil, fmt.Errorf("not a primitive type")
+		}
+
+		parse := jen.Id(parseFunc)
+
+		if strings.Contains(parseFunc, ".") {
+			lastIdx := strings.LastIndex(parseFunc, ".")
+			parse = jen.Qual(parseFunc[:lastIdx], parseFunc[lastIdx+1:])
+		}
+
+		return Template(`
+		if _parsedVal, err := {{ .Parse }}({{ .strName }}); err == nil {
+			_v := _parsedVal
+			{{ .varName }} = {{ .assignRight }}
+		}`[1:],
+			Values{
+				"Parse":       parse,
+				"strName":     strName,
+				"varName":     varName,
+				"assignRight": assignRight,
+			},
+		)
 	}
 }
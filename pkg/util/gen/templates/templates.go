@@ -188,27 +188,45 @@ func HTTPRequestDefaults() *HTTPRequestValues {
 	}
 }
 
+// HTTPRespondEncoder encodes a value into
+// a http.ResponseWriter with the given encoder.
 var HTTPRespondEncoder = `
 enc := {{ .NewEncoder }}({{ .WriterName }})
-{{ .WriterName }}.Header().Add("Content-Type", "application/json; charset=UTF-8")
+{{ .WriterName }}.Header().Set("Content-Type", {{ .ContentType }})
 {{ .WriterName }}.WriteHeader({{ .StatusCode }})
 {{ .ErrName }} := enc.Encode({{ .Value }})
 {{ .HandleErr }}`[1:]
 
 type HTTPRespondJSONValues struct {
-	NewEncoder jen.Code
-	WriterName jen.Code
-	StatusCode jen.Code
-	ErrName    jen.Code
-	HandleErr  jen.Code
+	NewEncoder  jen.Code
+	WriterName  jen.Code
+	ContentType jen.Code
+	StatusCode  jen.Code
+	ErrName     jen.Code
+	Value       jen.Code
+	HandleErr   jen.Code
+}
+
+func (h *HTTPRespondJSONValues) Values() gen.Values {
+	return gen.Values{
+		"NewEncoder":  h.NewEncoder,
+		"WriterName":  h.WriterName,
+		"ContentType": h.ContentType,
+		"StatusCode":  h.StatusCode,
+		"ErrName":     h.ErrName,
+		"Value":       h.Value,
+		"HandleErr":   h.HandleErr,
+	}
 }
 
 func HTTPRespondJSONDefaults() *HTTPRespondJSONValues {
 	return &HTTPRespondJSONValues{
-		NewEncoder: jen.Qual("encoding/json", "NewEncoder"),
-		WriterName: jen.Id("w"),
-		StatusCode: jen.Lit(200),
-		ErrName:    jen.Err(),
+		NewEncoder:  jen.Qual("encoding/json", "NewEncoder"),
+		WriterName:  jen.Id("w"),
+		ContentType: jen.Lit("application/json; charset=UTF-8"),
+		StatusCode:  jen.Lit(200),
+		ErrName:     jen.Err(),
+		Value:       jen.Id("val"),
 		HandleErr: jen.If(jen.Err().Op("!=").Nil()).Block(
 			jen.Return(jen.Err()),
 		),
@@ -9,6 +9,33 @@ import (
 // Taken function "lintName" from Go lint.
 // Also added casing transform.
 func ToGoName(name string) (should string) {
+	return Initialisms(nil).ToGoName(name)
+}
+
+// Initialisms are initialisms that are upper-cased
+// in names in addition to the common ones.
+type Initialisms map[string]bool
+
+// NewInitialisms returns the given initialisms
+// in a case-insensitive set.
+func NewInitialisms(initialisms []string) Initialisms {
+	set := make(Initialisms, len(initialisms))
+
+	for _, initialism := range initialisms {
+		initialism = strings.TrimSpace(initialism)
+		if initialism == "" {
+			continue
+		}
+
+		set[strings.ToUpper(initialism)] = true
+	}
+
+	return set
+}
+
+// ToGoName is the same as the ToGoName function,
+// but the initialisms are upper-cased as well.
+func (initialisms Initialisms) ToGoName(name string) (should string) {
 	// Fast path for simple cases: "_" and all lowercase.
 	if name == "_" {
 		return name
@@ -58,12 +85,12 @@ func ToGoName(name string) (should string) {
 
 		// [w,i) is a word.
 		word := string(runes[w:i])
-		if u := strings.ToUpper(word); commonInitialisms[u] {
+		if u := strings.ToUpper(word); commonInitialisms[u] || initialisms[u] {
 			// Keep consistent case, which is lowercase only at the start.
 			if w == 0 && unicode.IsLower(runes[w]) {
 				u = strings.ToLower(u)
 			}
-			// All the common initialisms are ASCII,
+			// All the initialisms are expected to be ASCII,
 			// so we can replace the bytes exactly.
 			copy(runes[w:], []rune(u))
 		} else if w > 0 && strings.ToLower(word) == word {
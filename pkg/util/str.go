@@ -21,3 +21,11 @@ func MustParseInt(number string) int {
 	}
 	return i
 }
+
+// IsJSONMediaType checks whether the content type is JSON,
+// including structured syntax suffixes like application/problem+json.
+func IsJSONMediaType(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
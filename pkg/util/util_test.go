@@ -19,3 +19,32 @@ func TestConvertParams(t *testing.T) {
 
 	assert.Equal(t, bracesStr, origStr)
 }
+
+func TestToGoNameInitialisms(t *testing.T) {
+	assert.Equal(t, ToGoName("Id"), "ID")
+	assert.Equal(t, ToGoName("PetId"), "PetID")
+	assert.Equal(t, ToGoName("Url"), "URL")
+	assert.Equal(t, ToGoName("HttpUrl"), "HTTPURL")
+	assert.Equal(t, ToGoName("Api"), "API")
+
+	assert.Equal(t, ToGoName("ProductSku"), "ProductSku")
+
+	initialisms := NewInitialisms([]string{"api", " sku", ""})
+
+	assert.Equal(t, initialisms.ToGoName("PetApi"), "PetAPI")
+	assert.Equal(t, initialisms.ToGoName("apiKey"), "apiKey")
+	assert.Equal(t, initialisms.ToGoName("ProductSku"), "ProductSKU")
+	assert.Equal(t, initialisms.ToGoName("Id"), "ID")
+
+	// The initialisms do not affect other names.
+	assert.Equal(t, ToGoName("ProductSku"), "ProductSku")
+	assert.Equal(t, Initialisms(nil).ToGoName("ProductSku"), "ProductSku")
+}
+
+func TestIsJSONMediaType(t *testing.T) {
+	assert.Equal(t, IsJSONMediaType("application/json"), true)
+	assert.Equal(t, IsJSONMediaType("application/json; charset=utf-8"), true)
+	assert.Equal(t, IsJSONMediaType("application/problem+json"), true)
+	assert.Equal(t, IsJSONMediaType("application/xml"), false)
+	assert.Equal(t, IsJSONMediaType("text/plain"), false)
+}